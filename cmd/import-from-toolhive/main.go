@@ -2,28 +2,33 @@
 package main
 
 import (
-	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
-	"gopkg.in/yaml.v3"
+
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+	"github.com/stacklok/toolhive-registry/pkg/types"
 )
 
 var (
-	sourceURL  string
-	sourceFile string
-	outputDir  string
-	verbose    bool
-	dryRun     bool
+	sourceURL     string
+	sourceFile    string
+	outputDir     string
+	verbose       bool
+	dryRun        bool
+	strategy      string
+	mergeStrategy registry.MergeStrategy
 )
 
 var rootCmd = &cobra.Command{
@@ -31,6 +36,15 @@ var rootCmd = &cobra.Command{
 	Short: "Import ToolHive registry.json into modular YAML format",
 	Long: `Import the existing ToolHive registry.json and convert it to the modular YAML format.
 Each registry entry will be converted to its own directory with a spec.yaml file.
+Both container servers and remote servers are imported; the OAuth configuration,
+headers and custom metadata of remote servers are written in the spec.yaml form.
+
+Re-importing merges into existing entries instead of overwriting them. Each import
+records the imported spec in <entry>/.import-base.yaml, and the next import only
+updates the fields that changed upstream since then, keeping local edits, comments
+and key order. Fields changed both locally and upstream are conflicts, resolved
+with --strategy: ours keeps the local value, theirs takes the upstream value and
+fail (the default) leaves the entry untouched.
 
 This tool is specifically for importing from ToolHive's format. For migrating to
 upstream MCP Registry format, use the 'migrate' command (future).`,
@@ -45,6 +59,8 @@ func init() {
 	rootCmd.Flags().StringVarP(&outputDir, "output", "o", "registry", "Output directory for YAML files")
 	rootCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
 	rootCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be created without actually creating files")
+	rootCmd.Flags().StringVar(&strategy, "strategy", string(registry.MergeStrategyFail),
+		"How to resolve fields changed both locally and upstream (ours, theirs, fail)")
 }
 
 func main() {
@@ -55,25 +71,33 @@ func main() {
 }
 
 func runImport(_ *cobra.Command, _ []string) error {
+	var err error
+	mergeStrategy, err = registry.ParseMergeStrategy(strategy)
+	if err != nil {
+		return err
+	}
+
 	registryData, err := loadRegistryData()
 	if err != nil {
 		return err
 	}
 
-	registry, err := parseRegistry(registryData)
+	thvRegistry, err := parseRegistry(registryData)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Found %d registry entries to import\n", len(registry.Servers))
+	totalCount := len(thvRegistry.Servers) + len(thvRegistry.RemoteServers)
+	fmt.Printf("Found %d registry entries to import (%d container, %d remote)\n",
+		totalCount, len(thvRegistry.Servers), len(thvRegistry.RemoteServers))
 
 	if dryRun {
 		fmt.Println("\nDry run mode - no files will be created")
 		fmt.Println("\nWould create the following structure:")
 	}
 
-	successCount := processRegistryEntries(registry)
-	printImportSummary(successCount, len(registry.Servers))
+	successCount := processRegistryEntries(thvRegistry)
+	printImportSummary(successCount, totalCount)
 
 	return nil
 }
@@ -125,14 +149,32 @@ func parseRegistry(registryData []byte) (*toolhiveRegistry.Registry, error) {
 	return &registry, nil
 }
 
-func processRegistryEntries(registry *toolhiveRegistry.Registry) int {
-	names := getSortedServerNames(registry)
+func processRegistryEntries(thvRegistry *toolhiveRegistry.Registry) int {
+	names := getSortedServerNames(thvRegistry)
 
 	successCount := 0
+	imported := make(map[string]string)
 	for _, name := range names {
-		server := registry.Servers[name]
-		if err := importEntry(name, server, outputDir, dryRun); err != nil {
-			log.Printf("Warning: Failed to import %s: %v", name, err)
+		entry := registryEntry(thvRegistry, name)
+		if entry == nil {
+			log.Printf("Warning: %s is both a container and a remote server; skipping it", name)
+			continue
+		}
+
+		// Different names can sanitize to the same directory
+		dirName := sanitizeName(name)
+		if other, exists := imported[dirName]; exists {
+			log.Printf("Warning: Failed to import %s: directory %s is already used by %s", name, dirName, other)
+			continue
+		}
+		imported[dirName] = name
+
+		if err := importEntry(name, entry, outputDir, dryRun); err != nil {
+			if errors.Is(err, registry.ErrMergeConflicts) {
+				log.Printf("Warning: Skipped %s: it has conflicts (use --strategy ours or theirs to resolve them)", name)
+			} else {
+				log.Printf("Warning: Failed to import %s: %v", name, err)
+			}
 			continue
 		}
 		successCount++
@@ -140,22 +182,35 @@ func processRegistryEntries(registry *toolhiveRegistry.Registry) int {
 	return successCount
 }
 
-func getSortedServerNames(registry *toolhiveRegistry.Registry) []string {
-	var names []string
-	for name := range registry.Servers {
+func getSortedServerNames(thvRegistry *toolhiveRegistry.Registry) []string {
+	names := make([]string, 0, len(thvRegistry.Servers)+len(thvRegistry.RemoteServers))
+	for name := range thvRegistry.Servers {
 		names = append(names, name)
 	}
-	// Sort names alphabetically
-	for i := 0; i < len(names); i++ {
-		for j := i + 1; j < len(names); j++ {
-			if names[i] > names[j] {
-				names[i], names[j] = names[j], names[i]
-			}
+	for name := range thvRegistry.RemoteServers {
+		if _, exists := thvRegistry.Servers[name]; !exists {
+			names = append(names, name)
 		}
 	}
+	sort.Strings(names)
 	return names
 }
 
+// registryEntry wraps the container or remote server with the given name in a registry entry,
+// returning nil if the name is used by both
+func registryEntry(thvRegistry *toolhiveRegistry.Registry, name string) *types.RegistryEntry {
+	server, isImage := thvRegistry.Servers[name]
+	remote, isRemote := thvRegistry.RemoteServers[name]
+	switch {
+	case isImage && isRemote:
+		return nil
+	case isRemote:
+		return &types.RegistryEntry{RemoteServerMetadata: remote}
+	default:
+		return &types.RegistryEntry{ImageMetadata: server}
+	}
+}
+
 func printImportSummary(successCount, totalCount int) {
 	if !dryRun {
 		fmt.Printf("\n✓ Successfully imported %d/%d entries to %s\n", successCount, totalCount, outputDir)
@@ -168,63 +223,54 @@ func printImportSummary(successCount, totalCount int) {
 	}
 }
 
-func importEntry(name string, server *toolhiveRegistry.ImageMetadata, outputDir string, dryRun bool) error {
+func importEntry(name string, entry *types.RegistryEntry, outputDir string, dryRun bool) error {
 	// Sanitize the name for use as a directory
 	dirName := sanitizeName(name)
 	entryDir := filepath.Join(outputDir, dirName)
 	specPath := filepath.Join(entryDir, "spec.yaml")
 
-	if verbose || dryRun {
-		fmt.Printf("  %s -> %s\n", name, specPath)
-	}
-
-	if dryRun {
-		return nil
-	}
-
-	// Create the directory
-	if err := os.MkdirAll(entryDir, 0750); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
-	}
-
 	// Ensure the name is set in the metadata
-	if server.Name == "" {
-		server.Name = name
+	if entry.GetName() == "" {
+		entry.SetName(name)
 	}
 
-	// Create YAML content with proper formatting (2-space indentation)
-	var buf bytes.Buffer
-	encoder := yaml.NewEncoder(&buf)
-	encoder.SetIndent(2)
-	if err := encoder.Encode(server); err != nil {
-		return fmt.Errorf("failed to marshal YAML: %w", err)
-	}
-	err := encoder.Close()
+	// Create YAML content in the spec.yaml form, so that remote servers get the
+	// simplified oauth: block and top-level custom metadata fields
+	yamlData, err := registry.MarshalSpec(entry)
 	if err != nil {
-		return fmt.Errorf("failed to close YAML encoder: %w", err)
+		return fmt.Errorf("failed to marshal YAML: %w", err)
 	}
-	yamlData := buf.Bytes()
 
 	// Add a header comment with metadata
 	header := fmt.Sprintf(`# %s MCP Server Registry Entry
 # Auto-imported from ToolHive registry.json
-# 
+#
 # Original source: https://github.com/stacklok/toolhive
 # Import timestamp: %s
 # ---
 `, name, time.Now().UTC().Format(time.RFC3339))
 
-	finalContent := header + string(yamlData)
+	// Merge into an existing spec.yaml so that local edits survive the re-import
+	spec, err := registry.MergeImport(entryDir, header, yamlData, mergeStrategy)
+	if spec != nil {
+		printSpecImport(name, specPath, spec)
+	}
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		return nil
+	}
 
-	// Write the spec.yaml file
-	if err := os.WriteFile(specPath, []byte(finalContent), 0600); err != nil {
-		return fmt.Errorf("failed to write spec.yaml: %w", err)
+	if err := spec.Write(); err != nil {
+		return err
 	}
 
 	// Optionally create a README for complex entries
-	if shouldCreateReadme(server) {
+	if spec.Changed && shouldCreateReadme(entry.GetServerMetadata()) {
 		readmePath := filepath.Join(entryDir, "README.md")
-		readmeContent := generateReadme(name, server)
+		readmeContent := generateReadme(name, entry)
 		if err := os.WriteFile(readmePath, []byte(readmeContent), 0600); err != nil {
 			// Non-fatal error
 			if verbose {
@@ -236,6 +282,29 @@ func importEntry(name string, server *toolhiveRegistry.ImageMetadata, outputDir
 	return nil
 }
 
+// printSpecImport describes how an imported spec.yaml is created or merged. Conflicts are
+// always shown; everything else only in verbose or dry run mode.
+func printSpecImport(name, specPath string, spec *registry.SpecImport) {
+	if verbose || dryRun {
+		switch {
+		case spec.Created:
+			fmt.Printf("  %s -> %s\n", name, specPath)
+		case len(spec.Updated) == 0 && len(spec.Conflicts) == 0:
+			fmt.Printf("  %s is up to date\n", name)
+		default:
+			fmt.Printf("  %s -> %s (merged)\n", name, specPath)
+		}
+		for _, field := range spec.Updated {
+			fmt.Printf("    ~ %s\n", field)
+		}
+	} else if len(spec.Conflicts) > 0 {
+		fmt.Printf("  %s -> %s\n", name, specPath)
+	}
+	for _, conflict := range spec.Conflicts {
+		fmt.Printf("    ! conflict: %s\n", conflict)
+	}
+}
+
 func sanitizeName(name string) string {
 	// Replace problematic characters with hyphens
 	replacer := strings.NewReplacer(
@@ -269,20 +338,21 @@ func sanitizeName(name string) string {
 	return finalName
 }
 
-func shouldCreateReadme(server *toolhiveRegistry.ImageMetadata) bool {
+func shouldCreateReadme(server toolhiveRegistry.ServerMetadata) bool {
 	// Create README for entries with substantial documentation needs
-	return len(server.Tools) > 10 || len(server.EnvVars) > 5 || len(server.Tags) > 10
+	return len(server.GetTools()) > 10 || len(server.GetEnvVars()) > 5 || len(server.GetTags()) > 10
 }
 
-func generateReadme(name string, server *toolhiveRegistry.ImageMetadata) string {
+func generateReadme(name string, entry *types.RegistryEntry) string {
 	var readme strings.Builder
+	server := entry.GetServerMetadata()
 
-	addReadmeHeader(&readme, name, server.Description)
-	addBasicInformation(&readme, server)
-	addToolsSection(&readme, server.Tools)
-	addEnvironmentVariablesSection(&readme, server.EnvVars)
-	addTagsSection(&readme, server.Tags)
-	addMetadataSection(&readme, server.Metadata)
+	addReadmeHeader(&readme, name, server.GetDescription())
+	addBasicInformation(&readme, entry)
+	addToolsSection(&readme, server.GetTools())
+	addEnvironmentVariablesSection(&readme, server.GetEnvVars())
+	addTagsSection(&readme, server.GetTags())
+	addMetadataSection(&readme, server.GetMetadata())
 
 	return readme.String()
 }
@@ -294,23 +364,26 @@ func addReadmeHeader(readme *strings.Builder, name, description string) {
 	}
 }
 
-func addBasicInformation(readme *strings.Builder, server *toolhiveRegistry.ImageMetadata) {
+func addBasicInformation(readme *strings.Builder, entry *types.RegistryEntry) {
 	readme.WriteString("## Basic Information\n\n")
 
-	if server.Image != "" {
-		fmt.Fprintf(readme, "- **Image:** `%s`\n", server.Image)
+	if entry.IsImage() {
+		fmt.Fprintf(readme, "- **Image:** `%s`\n", entry.Image)
+	}
+	if entry.IsRemote() {
+		fmt.Fprintf(readme, "- **URL:** %s\n", entry.URL)
 	}
-	if server.RepositoryURL != "" {
-		fmt.Fprintf(readme, "- **Repository:** [%s](%s)\n", server.RepositoryURL, server.RepositoryURL)
+	if repositoryURL := entry.GetServerMetadata().GetRepositoryURL(); repositoryURL != "" {
+		fmt.Fprintf(readme, "- **Repository:** [%s](%s)\n", repositoryURL, repositoryURL)
 	}
-	if server.Tier != "" {
-		fmt.Fprintf(readme, "- **Tier:** %s\n", server.Tier)
+	if entry.GetTier() != "" {
+		fmt.Fprintf(readme, "- **Tier:** %s\n", entry.GetTier())
 	}
-	if server.Status != "" {
-		fmt.Fprintf(readme, "- **Status:** %s\n", server.Status)
+	if entry.GetStatus() != "" {
+		fmt.Fprintf(readme, "- **Status:** %s\n", entry.GetStatus())
 	}
-	if server.Transport != "" {
-		fmt.Fprintf(readme, "- **Transport:** %s\n", server.Transport)
+	if entry.GetTransport() != "" {
+		fmt.Fprintf(readme, "- **Transport:** %s\n", entry.GetTransport())
 	}
 }
 
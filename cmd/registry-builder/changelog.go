@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+)
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog <previous>",
+	Short: "Generate a markdown changelog against a previous release",
+	Long: `Compare the registry entries in the --registry directory against a previous
+release and write a markdown changelog grouped by new servers, updated servers,
+deprecations, removals and security-relevant changes (permissions, provenance,
+secrets, OAuth).
+
+The previous release can be a built registry.json, a built official-registry.json,
+a registry directory or a git revision:
+
+  registry-builder changelog build/previous/registry.json --title v2025.10.01
+  registry-builder changelog v2025.09.01 --file CHANGELOG.md`,
+	Args: cobra.ExactArgs(1),
+	RunE: runChangelog,
+}
+
+var (
+	changelogTitle string
+	changelogFile  string
+)
+
+func init() {
+	changelogCmd.Flags().StringVar(&changelogTitle, "title", "", "Release title for the changelog heading (default \"Unreleased\")")
+	changelogCmd.Flags().StringVarP(&changelogFile, "file", "f", "", "Write the changelog to a file instead of stdout")
+
+	rootCmd.AddCommand(changelogCmd)
+}
+
+func runChangelog(_ *cobra.Command, args []string) error {
+	previous, err := loadEntriesSource(args[0])
+	if err != nil {
+		return err
+	}
+
+	current, err := registry.LoadEntriesFrom(registryPath)
+	if err != nil {
+		return err
+	}
+
+	changelog := registry.NewChangelog(changelogTitle, previous, current)
+
+	if changelogFile == "" {
+		return changelog.WriteMarkdown(os.Stdout)
+	}
+
+	var buf bytes.Buffer
+	if err := changelog.WriteMarkdown(&buf); err != nil {
+		return err
+	}
+	if err := os.WriteFile(changelogFile, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write changelog: %w", err)
+	}
+	if verbose {
+		log.Printf("Wrote changelog to %s (%s)", changelogFile, changelog.Diff.Summary())
+	}
+	return nil
+}
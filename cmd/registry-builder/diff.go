@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <old> <new>",
+	Short: "Show semantic changes between two registries",
+	Long: `Compare two registries and report what changed for consumers: servers added,
+removed or deprecated, image tag bumps, tools added or removed, env vars that
+became required, permission widening (new allow_host/allow_port), OAuth changes
+and more.
+
+Each argument can be a built registry.json, a built official-registry.json,
+a registry directory, or a git revision of the --registry directory:
+
+  registry-builder diff build/registry.json /tmp/registry.json
+  registry-builder diff main HEAD --output markdown
+
+Security-relevant changes are marked with "!" in text output.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runDiff,
+}
+
+var diffOutput string
+
+func init() {
+	diffCmd.Flags().StringVar(&diffOutput, "output", registry.ReportFormatText,
+		fmt.Sprintf("Diff format (%s, %s, %s)", registry.ReportFormatText, registry.ReportFormatMarkdown, registry.ReportFormatJSON))
+
+	rootCmd.AddCommand(diffCmd)
+}
+
+func runDiff(_ *cobra.Command, args []string) error {
+	oldEntries, err := loadEntriesSource(args[0])
+	if err != nil {
+		return err
+	}
+
+	newEntries, err := loadEntriesSource(args[1])
+	if err != nil {
+		return err
+	}
+
+	diff := registry.DiffRegistries(oldEntries, newEntries)
+	return diff.WriteDiff(os.Stdout, diffOutput)
+}
+
+// loadEntriesSource loads entries from a file or directory path, falling back to
+// treating the argument as a git revision of the registry directory
+func loadEntriesSource(source string) (map[string]*types.RegistryEntry, error) {
+	if _, err := os.Stat(source); err == nil {
+		if verbose {
+			log.Printf("Loading registry entries from %s", source)
+		}
+		return registry.LoadEntriesFrom(source)
+	}
+
+	if verbose {
+		log.Printf("Loading registry entries from %s at revision %s", registryPath, source)
+	}
+	entries, skipped, err := registry.LoadEntriesAtRevision(registryPath, source)
+	if err != nil {
+		return nil, fmt.Errorf("%s is neither a file, a directory nor a git revision: %w", source, err)
+	}
+	if len(skipped) > 0 {
+		log.Printf("Warning: Skipping %d entries that are invalid at revision %s: %s",
+			len(skipped), source, strings.Join(skipped, ", "))
+	}
+	return entries, nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+)
+
+var fmtCmd = &cobra.Command{
+	Use:   "fmt [spec.yaml ...]",
+	Short: "Rewrite spec.yaml files in the canonical format",
+	Long: `Rewrite every spec.yaml and version overlay in the registry, or only the given
+files, in the canonical format:
+
+  - keys in a fixed order (name, description, tier, status, transport, tools, ...)
+  - tools, tags and permission lists sorted
+  - empty permission fields removed
+  - block style, 2-space indentation and quotes only where YAML needs them
+
+Comments are kept. With --check no files are changed; the files that are not
+formatted are listed and the command exits non-zero.`,
+	RunE: runFmt,
+}
+
+var fmtCheck bool
+
+func init() {
+	fmtCmd.Flags().BoolVar(&fmtCheck, "check", false,
+		"List files that are not formatted and exit non-zero instead of rewriting them")
+
+	rootCmd.AddCommand(fmtCmd)
+}
+
+func runFmt(cmd *cobra.Command, args []string) error {
+	files := args
+	if len(files) == 0 {
+		var err error
+		files, err = registry.SpecFiles(registryPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	var changed, failed int
+	for _, file := range files {
+		unformatted, err := registry.FormatFile(file, fmtCheck)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			failed++
+			continue
+		}
+		if !unformatted {
+			if verbose {
+				log.Printf("%s is formatted", file)
+			}
+			continue
+		}
+		changed++
+		fmt.Println(file)
+	}
+
+	cmd.SilenceUsage = failed > 0 || (fmtCheck && changed > 0)
+	switch {
+	case failed > 0:
+		return fmt.Errorf("failed to format %d file(s)", failed)
+	case fmtCheck && changed > 0:
+		return fmt.Errorf("%d of %d file(s) are not formatted; run 'registry-builder fmt' to fix them", changed, len(files))
+	case verbose:
+		log.Printf("Formatted %d of %d file(s)", changed, len(files))
+	}
+	return nil
+}
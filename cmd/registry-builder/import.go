@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <official-registry.json|URL>",
+	Short: "Import servers in the official MCP Registry format into spec.yaml directories",
+	Long: `Import servers published in the official MCP Registry format into the --registry
+directory, one <name>/spec.yaml per server.
+
+The source can be an official-registry.json, a GET /v0/servers response or a JSON
+array of servers, read from a file or fetched from a URL:
+
+  registry-builder import build/official-registry.json
+  registry-builder import https://registry.example.com/v0/servers?limit=100
+
+OCI packages become image entries and remotes become url entries. Environment
+variables, headers and the ToolHive publisher extensions (tools, tags, permissions,
+provenance, OAuth) are mapped back to their spec.yaml fields, so importing the
+output of 'registry-builder build' reproduces the same official registry. When a
+server is listed in several versions, the latest becomes spec.yaml and the others
+become versions/<version>.yaml overlays.
+
+Entries that already exist are merged rather than overwritten. Each import records
+the imported spec in <name>/.import-base.yaml; the next import compares against it,
+updating only the fields that changed upstream and keeping local edits, comments
+and key order. Fields changed both locally and upstream are conflicts, resolved
+with --strategy:
+
+  ours    keep the local value
+  theirs  take the upstream value
+  fail    report the conflicts and leave the entry untouched (default)`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImport,
+}
+
+var (
+	importStrategy string
+	importDryRun   bool
+)
+
+func init() {
+	importCmd.Flags().StringVar(&importStrategy, "strategy", string(registry.MergeStrategyFail),
+		"How to resolve fields changed both locally and upstream (ours, theirs, fail)")
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Show what would be imported without writing files")
+
+	rootCmd.AddCommand(importCmd)
+}
+
+func runImport(_ *cobra.Command, args []string) error {
+	strategy, err := registry.ParseMergeStrategy(importStrategy)
+	if err != nil {
+		return err
+	}
+
+	source := args[0]
+	data, err := readImportSource(source)
+	if err != nil {
+		return err
+	}
+
+	servers, err := registry.ParseServerList(data)
+	if err != nil {
+		return err
+	}
+
+	entries, errs := registry.ImportServers(servers)
+	for _, err := range errs {
+		log.Printf("Warning: %v", err)
+	}
+	fmt.Printf("Found %d servers (%d entries) to import\n", len(servers), len(entries))
+
+	header := fmt.Sprintf("# Imported from the official MCP registry format\n# Source: %s\n# ---\n", source)
+	imported, skipped := 0, 0
+	for _, entry := range entries {
+		if importEntry(entry, header, strategy) {
+			imported++
+		} else {
+			skipped++
+		}
+	}
+
+	if importDryRun {
+		fmt.Printf("\n✓ Would import %d entries (%d skipped)\n", imported, skipped)
+		return nil
+	}
+	fmt.Printf("\n✓ Imported %d entries to %s (%d skipped)\n", imported, registryPath, skipped)
+	return nil
+}
+
+// importEntry validates an imported entry and merges it into the registry, reporting whether it was imported
+func importEntry(entry *registry.ImportedEntry, header string, strategy registry.MergeStrategy) bool {
+	if diags := diagnoseImportedEntry(entry); diags.HasErrors() {
+		for _, diag := range diags {
+			fmt.Println(diag.String())
+		}
+		fmt.Printf("  ✗ %s is not a valid entry; skipping\n", entry.Name)
+		return false
+	}
+
+	spec, err := entry.Merge(registryPath, header, strategy)
+	if spec != nil {
+		printSpecImport(entry.Name, filepath.Join(spec.EntryDir, "spec.yaml"), spec)
+	}
+	if err != nil {
+		if errors.Is(err, registry.ErrMergeConflicts) {
+			fmt.Printf("  ✗ %s has conflicts; skipping (use --strategy ours or theirs to resolve them)\n", entry.Name)
+		} else {
+			log.Printf("Warning: Failed to import %s: %v", entry.Name, err)
+		}
+		return false
+	}
+	if len(entry.Versions) > 0 {
+		fmt.Printf("    +%d versions\n", len(entry.Versions))
+	}
+
+	if importDryRun {
+		return true
+	}
+	if err := entry.Write(spec); err != nil {
+		log.Printf("Warning: Failed to import %s: %v", entry.Name, err)
+		return false
+	}
+	return true
+}
+
+// printSpecImport describes how an imported spec.yaml is created or merged
+func printSpecImport(name, specPath string, spec *registry.SpecImport) {
+	switch {
+	case spec.Created:
+		fmt.Printf("  %s -> %s\n", name, specPath)
+	case len(spec.Updated) == 0 && len(spec.Conflicts) == 0:
+		fmt.Printf("  %s is up to date\n", name)
+	default:
+		fmt.Printf("  %s -> %s (merged)\n", name, specPath)
+	}
+	for _, field := range spec.Updated {
+		fmt.Printf("    ~ %s\n", field)
+	}
+	for _, conflict := range spec.Conflicts {
+		fmt.Printf("    ! conflict: %s\n", conflict)
+	}
+}
+
+// diagnoseImportedEntry validates the latest version and every other version of an imported entry
+func diagnoseImportedEntry(entry *registry.ImportedEntry) registry.Diagnostics {
+	validator := registry.NewSchemaValidator()
+	diags := validator.DiagnoseEntry(entry.Entry, entry.Name)
+	for version, versionEntry := range entry.Versions {
+		for _, diag := range validator.DiagnoseEntry(versionEntry, entry.Name) {
+			diag.Message = fmt.Sprintf("version %s: %s", version, diag.Message)
+			diags = append(diags, diag)
+		}
+	}
+	return diags
+}
+
+// readImportSource reads the import source from a file or an http(s) URL
+func readImportSource(source string) ([]byte, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		data, err := os.ReadFile(source) // #nosec G304 - path comes from command line argument
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", source, err)
+		}
+		return data, nil
+	}
+
+	if verbose {
+		log.Printf("Fetching servers from %s", source)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", source, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: HTTP %d", source, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return data, nil
+}
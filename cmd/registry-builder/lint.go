@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Lint registry entries against style and security rules",
+	Long: `Lint all registry entries with the configurable rule engine.
+
+Rules can be enabled, disabled and re-graded (error, warning, info) in a
+.registry-lint.yaml file at the repository root:
+
+  rules:
+    image-tag-latest:
+      severity: error
+    description-length:
+      max: 120
+    approved-tags:
+      enabled: true
+      tags: [database, api, productivity]
+
+Schema validation problems are reported alongside lint findings. The command
+exits non-zero if any error-severity diagnostics are found.`,
+	RunE: runLint,
+}
+
+var (
+	lintConfigPath string
+	lintOutput     string
+	lintListRules  bool
+)
+
+func init() {
+	lintCmd.Flags().StringVarP(&lintConfigPath, "config", "c", registry.DefaultLintConfigFile, "Path to the lint configuration file")
+	lintCmd.Flags().StringVar(&lintOutput, "output", registry.ReportFormatText,
+		fmt.Sprintf("Report format (%s, %s, %s)", registry.ReportFormatText, registry.ReportFormatJSON, registry.ReportFormatSARIF))
+	lintCmd.Flags().BoolVar(&lintListRules, "list-rules", false, "List available rules and their configured state")
+
+	rootCmd.AddCommand(lintCmd)
+}
+
+func runLint(cmd *cobra.Command, _ []string) error {
+	config, err := registry.LoadLintConfig(lintConfigPath)
+	if err != nil {
+		return err
+	}
+
+	linter, err := registry.NewLinter(config)
+	if err != nil {
+		return err
+	}
+
+	if lintListRules {
+		for _, rule := range linter.Rules() {
+			state := "disabled"
+			if linter.IsEnabled(rule) {
+				state = string(linter.SeverityOf(rule))
+			}
+			fmt.Printf("%-24s %-8s %s\n", rule.Name, state, rule.Description)
+		}
+		return nil
+	}
+
+	if verbose {
+		log.Printf("Linting registry entries in %s", registryPath)
+	}
+
+	// Load all entries; entries that fail validation are reported but not linted
+	loader := registry.NewLoader(registryPath)
+	if err := loader.LoadAll(); err != nil {
+		var validationErr *registry.ValidationError
+		if !errors.As(err, &validationErr) {
+			return fmt.Errorf("failed to load registry entries: %w", err)
+		}
+	}
+
+	diags := append(loader.Diagnostics(), linter.Lint(loader)...)
+
+	// Entries with validation errors are not loaded, so count them separately
+	checked := len(loader.GetEntries())
+	for _, name := range loader.Diagnostics().Entries() {
+		if _, loaded := loader.GetEntries()[name]; !loaded {
+			checked++
+		}
+	}
+
+	report := registry.NewValidationReport(checked, diags)
+	report.AddRuleDescriptions(linter.RuleDescriptions())
+
+	out := os.Stdout
+	if lintOutput == registry.ReportFormatText && !report.Valid {
+		out = os.Stderr
+	}
+	if err := report.WriteReport(out, lintOutput, version); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	if !report.Valid {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("lint found %d error(s)", report.Errors)
+	}
+
+	return nil
+}
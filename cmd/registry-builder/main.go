@@ -2,11 +2,17 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -44,22 +50,34 @@ and generating output in the specified format.
 Supported formats:
   - toolhive: ToolHive JSON format (default)
   - mcp-registry: Upstream MCP Registry format (future)
-  - all: Build all supported formats`,
+  - all: Build all supported formats
+
+With --watch, the command keeps running after the first build. When a spec.yaml
+changes, only that entry is reloaded and revalidated, and the outputs are
+rewritten atomically if it is valid.`,
 	RunE: runBuild,
 }
 
 var validateCmd = &cobra.Command{
 	Use:   "validate",
 	Short: "Validate registry entries",
-	Long:  `Validate all registry entries without building the output files.`,
-	RunE:  runValidate,
+	Long: `Validate all registry entries without building the output files.
+
+Every spec.yaml is checked and all problems are reported together. Use --output
+json or --output sarif to get machine-readable diagnostics with file paths and
+line numbers pointing into the source spec.yaml files.`,
+	RunE: runValidate,
 }
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all registry entries",
-	Long:  `List all registry entries found in the registry directory.`,
-	RunE:  runList,
+	Long: `List all registry entries found in the registry directory.
+
+With --read-only only the entries whose tools are all read-only are listed. Side
+effects come from the tool_definitions of an entry; tools without a definition,
+annotations or a side_effect set by hand count as unknown, not read-only.`,
+	RunE: runList,
 }
 
 var versionCmd = &cobra.Command{
@@ -73,10 +91,14 @@ var versionCmd = &cobra.Command{
 }
 
 var (
-	registryPath string
-	outputDir    string
-	outputFormat string
-	verbose      bool
+	registryPath   string
+	outputDir      string
+	outputFormat   string
+	validateOutput string
+	reproducible   bool
+	watch          bool
+	verbose        bool
+	listReadOnly   bool
 )
 
 func init() {
@@ -88,6 +110,16 @@ func init() {
 	buildCmd.Flags().StringVarP(&outputDir, "output-dir", "o", "build", "Output directory for built registry files")
 	buildCmd.Flags().StringVarP(&outputFormat, "format", "f", "toolhive",
 		fmt.Sprintf("Output format (%s, %s, %s)", RegistryToolHiveFormat, RegistryOfficialMCPRegistry, RegistryAllFormats))
+	buildCmd.Flags().BoolVar(&reproducible, "reproducible", false,
+		"Derive timestamps from "+registry.SourceDateEpochEnv+" or git history and server IDs from name and version")
+	buildCmd.Flags().BoolVarP(&watch, "watch", "w", false, "Rebuild the outputs whenever a spec.yaml changes")
+
+	// Validate command flags
+	validateCmd.Flags().StringVar(&validateOutput, "output", registry.ReportFormatText,
+		fmt.Sprintf("Report format (%s, %s, %s)", registry.ReportFormatText, registry.ReportFormatJSON, registry.ReportFormatSARIF))
+
+	// List command flags
+	listCmd.Flags().BoolVar(&listReadOnly, "read-only", false, "Only list entries whose tools are all read-only")
 
 	// Add commands
 	rootCmd.AddCommand(buildCmd)
@@ -112,8 +144,8 @@ func runBuild(_ *cobra.Command, _ []string) error {
 	loader := registry.NewLoader(registryPath)
 
 	// Load all entries
-	if err := loader.LoadAll(); err != nil {
-		return fmt.Errorf("failed to load registry entries: %w", err)
+	if err := loadRegistry(loader, watch); err != nil {
+		return err
 	}
 
 	entries := loader.GetEntries()
@@ -132,16 +164,17 @@ func runBuild(_ *cobra.Command, _ []string) error {
 		}
 	}
 
+	clock, err := newBuildClock(loader)
+	if err != nil {
+		return err
+	}
+
 	// Determine which formats to build
 	formats := determineFormats(outputFormat)
 
 	// Build each format
-	var builtFormats []string
-	for _, format := range formats {
-		if err := buildFormat(loader, format, outputDir); err != nil {
-			return fmt.Errorf("failed to build %s format: %w", format, err)
-		}
-		builtFormats = append(builtFormats, format)
+	if err := buildFormats(loader, clock, formats); err != nil {
+		return err
 	}
 
 	fmt.Printf("✓ Successfully built registry with %d entries\n", len(entries))
@@ -149,9 +182,44 @@ func runBuild(_ *cobra.Command, _ []string) error {
 		fmt.Printf("  - %d container-based servers\n", imageCount)
 		fmt.Printf("  - %d remote servers\n", remoteCount)
 	}
-	fmt.Printf("  Formats: %s\n", strings.Join(builtFormats, ", "))
+	fmt.Printf("  Formats: %s\n", strings.Join(formats, ", "))
 	fmt.Printf("  Output directory: %s\n", outputDir)
 
+	if !watch {
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	return watchRegistry(ctx, registryPath, loader, func() error {
+		if err := buildFormats(loader, clock, formats); err != nil {
+			return err
+		}
+		fmt.Printf("  Rebuilt %s in %s (%d entries)\n", strings.Join(formats, ", "), outputDir, len(loader.GetEntries()))
+		return nil
+	})
+}
+
+// newBuildClock creates the clock for a build. Timestamps and IDs are deterministic in
+// reproducible mode (or when SOURCE_DATE_EPOCH is set), which fails without a build time.
+func newBuildClock(loader *registry.Loader) (*registry.BuildClock, error) {
+	clock, err := registry.NewBuildClock(loader, reproducible)
+	if err != nil {
+		return nil, err
+	}
+	if verbose && clock.Reproducible() {
+		log.Printf("Reproducible build: timestamp %s", clock.BuildTime().Format(time.RFC3339))
+	}
+	return clock, nil
+}
+
+func buildFormats(loader *registry.Loader, clock *registry.BuildClock, formats []string) error {
+	for _, format := range formats {
+		if err := buildFormat(loader, clock, format, outputDir); err != nil {
+			return fmt.Errorf("failed to build %s format: %w", format, err)
+		}
+	}
 	return nil
 }
 
@@ -169,20 +237,21 @@ func determineFormats(format string) []string {
 	}
 }
 
-func buildFormat(loader *registry.Loader, format string, outputDir string) error {
+func buildFormat(loader *registry.Loader, clock *registry.BuildClock, format string, outputDir string) error {
 	switch format {
 	case RegistryToolHiveFormat:
-		return buildToolhiveFormat(loader, outputDir)
+		return buildToolhiveFormat(loader, clock, outputDir)
 	case RegistryOfficialMCPRegistry:
-		return buildOfficialMCPRegistryFormat(loader, outputDir)
+		return buildOfficialMCPRegistryFormat(loader, clock, outputDir)
 	default:
 		return fmt.Errorf("unknown format: %s", format)
 	}
 }
 
-func buildOfficialMCPRegistryFormat(loader *registry.Loader, outputDir string) error {
+func buildOfficialMCPRegistryFormat(loader *registry.Loader, clock *registry.BuildClock, outputDir string) error {
 	// Create official MCP Registry builder
 	r := registry.NewOfficialRegistry(loader)
+	r.SetClock(clock)
 
 	// Ensure output directory exists
 	if err := os.MkdirAll(outputDir, 0750); err != nil {
@@ -203,9 +272,10 @@ func buildOfficialMCPRegistryFormat(loader *registry.Loader, outputDir string) e
 	return nil
 }
 
-func buildToolhiveFormat(loader *registry.Loader, outputDir string) error {
+func buildToolhiveFormat(loader *registry.Loader, clock *registry.BuildClock, outputDir string) error {
 	// Create builder
 	builder := registry.NewBuilder(loader)
+	builder.SetClock(clock)
 
 	// Validate against schema
 	if err := builder.ValidateAgainstSchema(); err != nil {
@@ -230,28 +300,74 @@ func buildToolhiveFormat(loader *registry.Loader, outputDir string) error {
 	return nil
 }
 
-func runValidate(_ *cobra.Command, _ []string) error {
+func runValidate(cmd *cobra.Command, _ []string) error {
 	if verbose {
 		log.Printf("Validating registry entries in %s", registryPath)
 	}
 
+	switch validateOutput {
+	case registry.ReportFormatText, registry.ReportFormatJSON, registry.ReportFormatSARIF:
+	default:
+		return fmt.Errorf("unknown output format: %s (expected %s, %s or %s)", validateOutput,
+			registry.ReportFormatText, registry.ReportFormatJSON, registry.ReportFormatSARIF)
+	}
+
 	// Create loader
 	loader := registry.NewLoader(registryPath)
 
-	// Load all entries
+	// Load all entries, collecting every problem instead of stopping at the first
 	if err := loader.LoadAll(); err != nil {
-		return fmt.Errorf("failed to load registry entries: %w", err)
+		var validationErr *registry.ValidationError
+		if !errors.As(err, &validationErr) {
+			return fmt.Errorf("failed to load registry entries: %w", err)
+		}
 	}
 
 	entries := loader.GetEntries()
+	diags := loader.Diagnostics()
 
-	// Create builder for validation
-	builder := registry.NewBuilder(loader)
+	// Entries with errors are not loaded, so count them separately
+	entryCount := len(entries) + len(diags.Entries())
 
-	// Validate against schema
-	if err := builder.ValidateAgainstSchema(); err != nil {
-		return fmt.Errorf("validation failed: %w", err)
+	// Validate the loaded entries together, as they are published, against the schema
+	schemaDiags := registry.NewBuilder(loader).DiagnoseSchema()
+	loader.Locate(schemaDiags)
+	report := registry.NewValidationReport(entryCount, slices.Concat(diags, schemaDiags))
+
+	if err := writeValidationReport(report); err != nil {
+		return err
+	}
+	if !report.Valid {
+		// The report already explains the failure, so don't follow it with usage text
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d registry entries failed validation", len(report.Diagnostics.Entries()))
+	}
+
+	if validateOutput == registry.ReportFormatText {
+		printValidationSummary(loader)
+	}
+	return nil
+}
+
+// writeValidationReport writes the report in the requested format. Text reports are only
+// written when validation fails, to stderr, as the summary covers valid registries.
+func writeValidationReport(report *registry.ValidationReport) error {
+	var err error
+	switch {
+	case validateOutput != registry.ReportFormatText:
+		err = report.WriteReport(os.Stdout, validateOutput, version)
+	case !report.Valid:
+		err = report.WriteText(os.Stderr)
 	}
+	if err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// printValidationSummary prints the number of valid entries of each server type
+func printValidationSummary(loader *registry.Loader) {
+	entries := loader.GetEntries()
 
 	// Count image and remote servers
 	imageCount := 0
@@ -280,8 +396,6 @@ func runValidate(_ *cobra.Command, _ []string) error {
 			fmt.Printf("  - %s [%s]: %s\n", entry.GetName(), serverType, entry.GetDescription())
 		}
 	}
-
-	return nil
 }
 
 func runList(_ *cobra.Command, _ []string) error {
@@ -294,8 +408,14 @@ func runList(_ *cobra.Command, _ []string) error {
 	}
 
 	entries := loader.GetSortedEntries()
-
-	fmt.Printf("Found %d registry entries:\n\n", len(entries))
+	if listReadOnly {
+		entries = slices.DeleteFunc(entries, func(entry *types.RegistryEntry) bool {
+			return !entry.IsReadOnly()
+		})
+		fmt.Printf("Found %d read-only registry entries:\n\n", len(entries))
+	} else {
+		fmt.Printf("Found %d registry entries:\n\n", len(entries))
+	}
 
 	// Separate image and remote servers for display
 	var imageServers, remoteServers []*types.RegistryEntry
@@ -381,9 +501,20 @@ func displayVerboseEntryInfo(entry *types.RegistryEntry) {
 
 func displayToolsInfo(entry *types.RegistryEntry) {
 	tools := entry.GetTools()
-	if len(tools) > 0 {
-		fmt.Printf("  Tools:       %d available\n", len(tools))
+	if len(tools) == 0 {
+		return
+	}
+	counts := make(map[string]int)
+	for _, effect := range entry.ToolSideEffects() {
+		counts[effect]++
+	}
+	var summary []string
+	for _, effect := range types.SideEffects {
+		if counts[effect] > 0 {
+			summary = append(summary, fmt.Sprintf("%d %s", counts[effect], effect))
+		}
 	}
+	fmt.Printf("  Tools:       %d available (%s)\n", len(tools), strings.Join(summary, ", "))
 }
 
 func displayRepositoryInfo(entry *types.RegistryEntry) {
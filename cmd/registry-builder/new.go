@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+	"github.com/stacklok/toolhive-registry/pkg/toolhive"
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+var newCmd = &cobra.Command{
+	Use:   "new <name> --image <image> | --url <url>",
+	Short: "Create a spec.yaml skeleton for a new registry entry",
+	Long: `Create <registry>/<name>/spec.yaml for a new server, either a container image
+(--image) or a remote server (--url).
+
+The skeleton gets the default tier and status, a permissions block that allows
+HTTPS connections, a target_port for images that use an HTTP transport and a
+placeholder env var. Fields that aren't given on the command line get commented
+placeholders to fill in.
+
+With --list-tools the image is started through thv and the tools it reports are
+written to the tools list, with their definitions in tool_definitions. The generated spec is validated before it is written.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runNew,
+}
+
+var (
+	newOptions   registry.ScaffoldOptions
+	newListTools bool
+	newThvPath   string
+	newDryRun    bool
+)
+
+func init() {
+	newCmd.Flags().StringVar(&newOptions.Image, "image", "", "Container image of the server")
+	newCmd.Flags().StringVar(&newOptions.URL, "url", "", "Endpoint of a remote server")
+	newCmd.Flags().StringVar(&newOptions.Transport, "transport", "",
+		"Transport (stdio, sse, streamable-http; defaults to stdio for images and streamable-http for remote servers)")
+	newCmd.Flags().StringVar(&newOptions.Description, "description", "", "One-line description of the server")
+	newCmd.Flags().StringVar(&newOptions.RepositoryURL, "repository-url", "", "Source code repository of the server")
+	newCmd.Flags().IntVar(&newOptions.TargetPort, "target-port", registry.ScaffoldTargetPort,
+		"Container port of an image that uses an HTTP transport")
+	newCmd.Flags().StringSliceVar(&newOptions.Tools, "tools", nil, "Tools the server provides")
+	newCmd.Flags().BoolVar(&newListTools, "list-tools", false, "Start the image with thv and fill in the tools it reports")
+	newCmd.Flags().StringVar(&newThvPath, "thv-path", "", "Path to thv binary (defaults to searching PATH)")
+	newCmd.Flags().BoolVar(&newDryRun, "dry-run", false, "Print the spec.yaml instead of writing it")
+	newCmd.MarkFlagsMutuallyExclusive("image", "url")
+	newCmd.MarkFlagsMutuallyExclusive("tools", "list-tools")
+
+	rootCmd.AddCommand(newCmd)
+}
+
+func runNew(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	name := args[0]
+	specPath := filepath.Join(registryPath, name, "spec.yaml")
+	if _, err := os.Stat(specPath); err == nil {
+		return fmt.Errorf("%s already exists", specPath)
+	}
+
+	entry, err := registry.NewEntry(name, newOptions)
+	if err != nil {
+		return err
+	}
+	if newListTools {
+		definitions, err := discoverTools(name, entry)
+		if err != nil {
+			return err
+		}
+		entry.ImageMetadata.Tools = make([]string, 0, len(definitions))
+		for _, definition := range definitions {
+			entry.ImageMetadata.Tools = append(entry.ImageMetadata.Tools, definition.Name)
+		}
+		entry.ToolDefinitions = definitions
+	}
+
+	data, err := registry.ScaffoldSpec(name, entry)
+	if err != nil {
+		return err
+	}
+	if newDryRun {
+		fmt.Print(string(data))
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(specPath), 0750); err != nil {
+		return fmt.Errorf("failed to create entry directory: %w", err)
+	}
+	if err := os.WriteFile(specPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", specPath, err)
+	}
+	fmt.Printf("Created %s\n", specPath)
+	return nil
+}
+
+// discoverTools starts the image of a new entry with thv and returns the tools it reports,
+// sorted by name
+func discoverTools(name string, entry *types.RegistryEntry) ([]types.ToolDefinition, error) {
+	if !entry.IsImage() {
+		return nil, errors.New("--list-tools needs an image; remote servers cannot be run locally")
+	}
+
+	client, err := toolhive.NewClient(newThvPath, verbose)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ToolHive client: %w", err)
+	}
+
+	// Let the server be cleaned up on Ctrl-C instead of leaving it behind
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("Starting %s to list its tools...", entry.Image)
+	tempName, err := client.RunServer(ctx, entry, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run server: %w", err)
+	}
+	defer client.Cleanup(tempName)
+
+	tools, err := client.ListToolDefinitions(tempName)
+	if err != nil {
+		if logs, logErr := client.Logs(tempName); logErr == nil && logs != "" {
+			log.Printf("Logs from temporary server %s:\n%s", tempName, logs)
+		}
+		return nil, fmt.Errorf("failed to list tools: %w", err)
+	}
+	if len(tools) == 0 {
+		return nil, fmt.Errorf("%s reported no tools", entry.Image)
+	}
+
+	log.Printf("Found %d tools", len(tools))
+	return tools, nil
+}
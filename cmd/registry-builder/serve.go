@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	upstream "github.com/modelcontextprotocol/registry/pkg/api/v0"
+	"github.com/spf13/cobra"
+
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+	"github.com/stacklok/toolhive-registry/pkg/server"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve [registry.json|official-registry.json]",
+	Short: "Serve the registry over the MCP Registry v0 API",
+	Long: `Serve the registry over the read-only MCP Registry v0 HTTP API.
+
+By default the entries in the --registry directory are served. A built
+registry.json or official-registry.json can be served instead by passing it
+as an argument.
+
+Endpoints:
+  GET /v0/servers        list servers (cursor, limit, search, version, updated_since)
+  GET /v0/servers/{id}   get a server by ID, full name or short name
+  GET /v0/health         health check
+
+Responses carry an ETag, honour If-None-Match and are gzip-compressed when the
+client accepts it. With --watch, changed spec.yaml files are reloaded and served
+without restarting.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runServe,
+}
+
+var (
+	serveAddr  string
+	serveWatch bool
+)
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "localhost:8080", "Address to listen on")
+	serveCmd.Flags().BoolVarP(&serveWatch, "watch", "w", false, "Reload entries whenever a spec.yaml changes")
+
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(_ *cobra.Command, args []string) error {
+	source := registryPath
+	if len(args) > 0 {
+		source = args[0]
+	}
+
+	info, err := os.Stat(source)
+	if err != nil {
+		return fmt.Errorf("failed to access %s: %w", source, err)
+	}
+	if serveWatch && !info.IsDir() {
+		return fmt.Errorf("--watch requires a registry directory, not %s", source)
+	}
+
+	var (
+		servers []upstream.ServerJSON
+		loader  *registry.Loader
+		clock   *registry.BuildClock
+	)
+	if info.IsDir() {
+		// Keep the loader and clock around so watch mode can rebuild with stable IDs
+		loader = registry.NewLoader(source)
+		if err := loadRegistry(loader, serveWatch); err != nil {
+			return err
+		}
+		if clock, err = registry.NewBuildClock(loader, true); err != nil {
+			return err
+		}
+		servers, err = registry.BuildOfficialServers(loader, clock)
+	} else {
+		servers, err = registry.LoadServersFrom(source)
+	}
+	if err != nil {
+		return err
+	}
+
+	srv := server.New(servers)
+	httpServer := &http.Server{
+		Addr:              serveAddr,
+		Handler:           srv,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 2)
+	go func() {
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	fmt.Printf("Serving %d servers from %s on http://%s/v0/servers\n", len(servers), source, serveAddr)
+
+	if serveWatch {
+		go func() {
+			if err := watchServers(ctx, source, loader, clock, srv); err != nil {
+				errCh <- err
+			}
+		}()
+	}
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("server failed: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	if verbose {
+		log.Printf("Shutting down server")
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return httpServer.Shutdown(shutdownCtx)
+}
+
+// watchServers rebuilds the servers of srv whenever a spec.yaml in the registry directory
+// changes, until ctx is done
+func watchServers(
+	ctx context.Context, source string, loader *registry.Loader, clock *registry.BuildClock, srv *server.Server,
+) error {
+	return watchRegistry(ctx, source, loader, func() error {
+		servers, err := registry.BuildOfficialServers(loader, clock)
+		if err != nil {
+			return err
+		}
+		srv.Update(servers)
+		fmt.Printf("  Serving %d servers\n", len(servers))
+		return nil
+	})
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+)
+
+// watchRegistry reloads changed entries of a loaded registry until the context is cancelled.
+// After each valid change rebuild is called; invalid changes keep the previous outputs.
+func watchRegistry(ctx context.Context, path string, loader *registry.Loader, rebuild func() error) error {
+	watcher, err := registry.NewWatcher(loader)
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	fmt.Printf("👀 Watching %s for changes (Ctrl+C to stop)\n", path)
+
+	return watcher.Run(ctx, func(event registry.WatchEvent) {
+		if !reportWatchEvent(event) {
+			return
+		}
+		if err := rebuild(); err != nil {
+			fmt.Printf("✗ Rebuild failed: %v\n", err)
+		}
+	})
+}
+
+// reportWatchEvent prints the validation result for the changed entry only and
+// returns true if the outputs should be rebuilt
+func reportWatchEvent(event registry.WatchEvent) bool {
+	if event.Removed {
+		fmt.Printf("- %s removed\n", event.Entry)
+		return true
+	}
+
+	for _, diag := range event.Diagnostics {
+		fmt.Println(diag.String())
+	}
+
+	if event.Diagnostics.HasErrors() {
+		fmt.Printf("✗ %s is invalid; keeping the previous version\n", event.Entry)
+		return false
+	}
+
+	fmt.Printf("✓ %s reloaded\n", event.Entry)
+	return true
+}
+
+// loadRegistry loads all entries of a loader. In watch mode, invalid entries are
+// reported and skipped instead of failing, so they can be fixed while watching.
+func loadRegistry(loader *registry.Loader, watching bool) error {
+	err := loader.LoadAll()
+	if err == nil {
+		return nil
+	}
+
+	var validationErr *registry.ValidationError
+	if !watching || !errors.As(err, &validationErr) {
+		return fmt.Errorf("failed to load registry entries: %w", err)
+	}
+
+	for _, diag := range loader.Diagnostics() {
+		fmt.Println(diag.String())
+	}
+	fmt.Printf("✗ Skipping %d invalid entries until they are fixed\n", len(loader.Diagnostics().Entries()))
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/stacklok/toolhive/pkg/registry"
 	"gopkg.in/yaml.v3"
 
+	"github.com/stacklok/toolhive-registry/pkg/imageref"
 	"github.com/stacklok/toolhive-registry/pkg/types"
 )
 
@@ -467,19 +468,17 @@ func getGitHubRepoInfo(owner, repo, _ string, currentPulls int) (stars int, pull
 // getContainerPullCount fetches the pull count for a container image
 func getContainerPullCount(image string) (int, error) {
 	// Parse the image reference
-	parts := strings.Split(image, ":")
-	if len(parts) < 1 {
-		return 0, fmt.Errorf("invalid image format: %s", image)
+	ref, err := imageref.Parse(image)
+	if err != nil {
+		return 0, err
 	}
 
-	imageName := parts[0]
-
 	// Determine registry and fetch accordingly
-	if strings.HasPrefix(imageName, "ghcr.io/") {
-		return getGHCRPullCount(imageName)
-	} else if strings.Contains(imageName, "/") && !strings.Contains(imageName, ".") {
-		// Likely Docker Hub (no dots in the hostname part)
-		return getDockerHubPullCount(imageName)
+	switch ref.Domain {
+	case "ghcr.io":
+		return getGHCRPullCount(ref.Name())
+	case imageref.DockerHubDomain:
+		return getDockerHubPullCount(ref.Path)
 	}
 
 	// Unknown registry, return 0
@@ -616,11 +615,9 @@ func fetchGHCRVersions(client *http.Client, baseURL, imageName string) (int, err
 	return 0, nil
 }
 
-// getDockerHubPullCount fetches pull count for Docker Hub images
+// getDockerHubPullCount fetches pull count for Docker Hub images, given the
+// repository path including the namespace (e.g. library/nginx)
 func getDockerHubPullCount(imageName string) (int, error) {
-	// Remove docker.io prefix if present
-	imageName = strings.TrimPrefix(imageName, "docker.io/")
-
 	// Docker Hub API endpoint
 	url := fmt.Sprintf("https://hub.docker.com/v2/repositories/%s/", imageName)
 
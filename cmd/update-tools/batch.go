@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/stacklok/toolhive-registry/pkg/toolhive"
+)
+
+// updateStatus is the outcome of updating a single spec file
+type updateStatus string
+
+const (
+	statusUpdated   updateStatus = "updated"
+	statusUnchanged updateStatus = "unchanged"
+	statusFailed    updateStatus = "failed"
+	statusSkipped   updateStatus = "skipped"
+)
+
+// updateResult records how the update of a single spec file went
+type updateResult struct {
+	name   string
+	path   string
+	status updateStatus
+	// detail is a short explanation for the report, such as the tool counts or why it was skipped
+	detail string
+	err    error
+}
+
+func (r *updateResult) fail(err error) *updateResult {
+	// Command output is appended to errors on later lines; the report only shows the first
+	r.status, r.err, r.detail = statusFailed, err, strings.SplitN(err.Error(), "\n", 2)[0]
+	return r
+}
+
+func (r *updateResult) skip(reason string) *updateResult {
+	r.status, r.detail = statusSkipped, reason
+	return r
+}
+
+// updateSpecs updates the spec files with a pool of parallel workers and returns the
+// results in the order of specPaths
+func updateSpecs(ctx context.Context, specPaths []string, newClient func() (*toolhive.Client, error)) []*updateResult {
+	results := make([]*updateResult, len(specPaths))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(parallel, len(specPaths)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				update := &specUpdate{
+					path:      specPaths[i],
+					name:      filepath.Base(filepath.Dir(specPaths[i])),
+					newClient: newClient,
+				}
+				if len(specPaths) > 1 {
+					update.prefix = fmt.Sprintf("[%s] ", update.name)
+				}
+				results[i] = update.run(ctx)
+			}
+		}()
+	}
+
+	for i := range specPaths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// printReport prints the consolidated outcome of an update run, grouped by status
+func printReport(w io.Writer, results []*updateResult) {
+	byStatus := make(map[updateStatus][]*updateResult)
+	for _, result := range results {
+		byStatus[result.status] = append(byStatus[result.status], result)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Tool update report: %d updated, %d unchanged, %d failed, %d skipped\n",
+		len(byStatus[statusUpdated]), len(byStatus[statusUnchanged]),
+		len(byStatus[statusFailed]), len(byStatus[statusSkipped]))
+
+	for _, status := range []updateStatus{statusUpdated, statusFailed, statusSkipped, statusUnchanged} {
+		group := byStatus[status]
+		if len(group) == 0 || (status == statusUnchanged && !verbose) {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s (%d):\n", status, len(group))
+		for _, result := range group {
+			if result.detail == "" {
+				fmt.Fprintf(w, "  %s\n", result.name)
+				continue
+			}
+			fmt.Fprintf(w, "  %s: %s\n", result.name, result.detail)
+		}
+	}
+	if dryRun {
+		fmt.Fprintln(w, "\n[DRY RUN] No files were changed")
+	}
+}
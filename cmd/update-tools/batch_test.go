@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/toolhive"
+)
+
+// fakeThvScript stands in for thv: every command succeeds and servers report a read and a
+// write tool, with no resources or prompts
+const fakeThvScript = `#!/bin/sh
+if [ "$1" = mcp ] && [ "$3" = tools ]; then
+  echo '{"tools":[{"name":"read","annotations":{"readOnlyHint":true}},{"name":"write"}]}'
+elif [ "$1" = mcp ]; then
+  echo '{}'
+fi
+exit 0
+`
+
+// newFakeClient returns a client factory for a thv that runs the given script
+func newFakeClient(t *testing.T, script string) func() (*toolhive.Client, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "thv")
+	require.NoError(t, os.WriteFile(path, []byte(script), 0700)) // #nosec G306 - the script must be executable
+	return func() (*toolhive.Client, error) {
+		return toolhive.NewClient(path, false)
+	}
+}
+
+// writeSpecs writes spec.yaml files keyed by entry name and returns their paths in key order
+func writeSpecs(t *testing.T, names []string, specs map[string]string) []string {
+	t.Helper()
+	dir := t.TempDir()
+	var paths []string
+	for _, name := range names {
+		path := filepath.Join(dir, name, "spec.yaml")
+		if spec, ok := specs[name]; ok {
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+			require.NoError(t, os.WriteFile(path, []byte(spec), 0600))
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func TestUpdateSpecs(t *testing.T) {
+	t.Parallel()
+
+	names := []string{"alpha", "bravo", "charlie", "delta"}
+	paths := writeSpecs(t, names, map[string]string{
+		"alpha": `image: test/alpha:1.0.0
+description: Alpha
+transport: stdio
+tools:
+  - old
+`,
+		// bravo has no spec file and fails to load
+		"charlie": `url: https://example.com/mcp
+description: Charlie
+transport: sse
+tools:
+  - remote
+headers:
+  - name: X-Api-Key
+    required: true
+`,
+		"delta": `image: test/delta:1.0.0
+description: Delta
+transport: stdio
+tools:
+  - read
+  - write
+tool_definitions:
+  - name: read
+    annotations:
+      readOnlyHint: true
+    side_effect: read-only
+  - name: write
+    side_effect: unknown
+`,
+	})
+
+	results := updateSpecs(context.Background(), paths, newFakeClient(t, fakeThvScript))
+
+	// Results keep the order of the spec files, whichever worker finished first
+	require.Len(t, results, len(names))
+	var gotNames []string
+	var gotStatuses []updateStatus
+	for _, result := range results {
+		gotNames = append(gotNames, result.name)
+		gotStatuses = append(gotStatuses, result.status)
+	}
+	assert.Equal(t, names, gotNames)
+	assert.Equal(t, []updateStatus{statusUpdated, statusFailed, statusSkipped, statusUnchanged}, gotStatuses)
+	assert.Equal(t, "+2 -1 tools, tool definitions", results[0].detail)
+	assert.Contains(t, results[1].detail, "failed to load spec")
+	assert.Equal(t, "no credentials for required header X-Api-Key", results[2].detail)
+
+	spec, err := loadSpec(paths[0])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"read", "write"}, spec.GetTools())
+}
+
+func TestUpdateSpecs_Interrupted(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	paths := writeSpecs(t, []string{"alpha", "bravo"}, nil)
+	results := updateSpecs(ctx, paths, newFakeClient(t, fakeThvScript))
+	require.Len(t, results, 2)
+	for _, result := range results {
+		assert.Equal(t, statusSkipped, result.status)
+		assert.Equal(t, "interrupted", result.detail)
+	}
+}
+
+func TestPrintReport(t *testing.T) {
+	t.Parallel()
+
+	results := []*updateResult{
+		{name: "delta", status: statusUnchanged},
+		{name: "alpha", status: statusUpdated, detail: "+1 -0 tools"},
+		(&updateResult{name: "bravo"}).fail(errors.New("failed to load spec\nOutput: details")),
+		(&updateResult{name: "charlie"}).skip("interrupted"),
+		{name: "echo", status: statusUpdated, detail: "tool definitions"},
+	}
+
+	var out bytes.Buffer
+	printReport(&out, results)
+
+	// Groups come in a fixed order, keep the order of the results and hide unchanged servers
+	assert.Equal(t, `
+Tool update report: 2 updated, 1 unchanged, 1 failed, 1 skipped
+
+updated (2):
+  alpha: +1 -0 tools
+  echo: tool definitions
+
+failed (1):
+  bravo: failed to load spec
+
+skipped (1):
+  charlie: interrupted
+`, out.String())
+}
+
+func TestUpdateSpecs_TextOutput(t *testing.T) {
+	t.Parallel()
+
+	// Versions of thv that only print text still give the tool names
+	script := `#!/bin/sh
+if [ "$1" = mcp ] && [ "$3" = tools ]; then
+  printf 'TOOLS:\nNAME    DESCRIPTION\nread    Reads a file\nwrite   Writes a file\n'
+fi
+exit 0
+`
+	paths := writeSpecs(t, []string{"alpha"}, map[string]string{"alpha": `image: test/alpha:1.0.0
+description: Alpha
+transport: stdio
+tools:
+  - read
+tool_definitions:
+  - name: read
+    description: Reads a file
+`})
+
+	results := updateSpecs(context.Background(), paths, newFakeClient(t, script))
+	require.Len(t, results, 1)
+	assert.Equal(t, statusUpdated, results[0].status)
+	assert.Equal(t, "+1 -0 tools", results[0].detail)
+
+	// The definitions can't be read from text, so they are kept as they are
+	spec, err := loadSpec(paths[0])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"read", "write"}, spec.GetTools())
+	require.Len(t, spec.ToolDefinitions, 1)
+	assert.Equal(t, "Reads a file", spec.ToolDefinitions[0].Description)
+}
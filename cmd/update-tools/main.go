@@ -2,38 +2,96 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"slices"
 	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/spf13/cobra"
 	"github.com/stacklok/toolhive/pkg/logger"
 	"gopkg.in/yaml.v3"
 
+	"github.com/stacklok/toolhive-registry/pkg/mcpclient"
 	"github.com/stacklok/toolhive-registry/pkg/toolhive"
 	"github.com/stacklok/toolhive-registry/pkg/types"
 )
 
 var (
-	specPath    string
-	dryRun      bool
-	thvPath     string
-	addWarnings bool
-	verbose     bool
+	dryRun          bool
+	thvPath         string
+	addWarnings     bool
+	verbose         bool
+	updateAll       bool
+	registryPath    string
+	parallel        int
+	readyTimeout    time.Duration
+	readyInterval   time.Duration
+	toolDefinitions bool
+	capabilities    bool
+	credentialsFile string
+	secretsFile     string
+	passEnv         bool
+
+	// credentials are the headers and tokens of remote servers, keyed by entry name
+	credentials map[string]remoteCredentials
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "update-tools [spec-file]",
+	Use:   "update-tools [spec-file ...]",
 	Short: "Update tool lists in MCP server spec files using thv mcp list",
 	Long: `update-tools fetches the current list of tools from an MCP server using
 'thv mcp list --server <name>' and updates the tools section in the spec.yaml file.
 
 If no tools are detected but the spec had tools before, it keeps the old list
-and adds a warning comment.`,
-	Args: cobra.ExactArgs(1),
+and adds a warning comment.
+
+The descriptions, argument schemas and annotations of the tools are written to
+the tool_definitions section, unless --tool-definitions=false is given. The
+resources and prompt templates the server reports are written to the resources
+and prompts sections, unless --resources-and-prompts=false is given; a server
+that fails to list them or reports none keeps the lists it had. Resource
+templates are written to the resource_templates section for remote servers
+only, as thv can't list them for images.
+
+Several spec files can be given at once, or --all to update every entry in the
+registry. Up to --parallel servers are started at the same time, each under a
+unique temporary name that is stopped and removed once its tools are listed.
+
+Required and secret env vars of images are set to a placeholder, unless a real
+value is given in the --secrets-file or, with --pass-env, in the environment.
+The secrets file is either dotenv, whose values go to every server declaring the
+env var, or YAML keyed by entry name:
+
+  github:
+    GITHUB_PERSONAL_ACCESS_TOKEN: ghp_...
+
+Injected values reach thv in a private env file rather than on its command
+line, and are masked in the thv output and server logs update-tools prints.
+
+Remote servers are connected to directly at their url, over their sse or
+streamable-http transport. The headers they need and the access tokens of OAuth
+servers are read from the --credentials file, keyed by entry name:
+
+  stripe:
+    headers:
+      Authorization: Bearer sk_test_...
+  notion:
+    token: ntn_...
+
+Remote servers whose required headers or OAuth token are missing are skipped.
+
+A report of the updated, unchanged, failed and skipped servers is printed at
+the end.`,
 	RunE: runUpdate,
 }
 
@@ -44,6 +102,23 @@ func init() {
 	rootCmd.Flags().StringVar(&thvPath, "thv-path", "", "Path to thv binary (defaults to searching PATH)")
 	rootCmd.Flags().BoolVar(&addWarnings, "add-warnings", true, "Add warning comments when tools can't be fetched")
 	rootCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+	rootCmd.Flags().BoolVar(&updateAll, "all", false, "Update every spec file in the registry directory")
+	rootCmd.Flags().StringVarP(&registryPath, "registry", "r", "registry", "Path to the registry directory used with --all")
+	rootCmd.Flags().IntVarP(&parallel, "parallel", "j", 4, "Number of servers to update at the same time")
+	rootCmd.Flags().DurationVar(&readyTimeout, "ready-timeout", toolhive.DefaultReadyTimeout,
+		"How long to wait for a started or remote server to answer before giving up")
+	rootCmd.Flags().DurationVar(&readyInterval, "ready-interval", toolhive.DefaultReadyInterval,
+		"Delay before checking a started server again; doubles after every failed check")
+	rootCmd.Flags().BoolVar(&toolDefinitions, "tool-definitions", true,
+		"Also write the descriptions, argument schemas and annotations of the tools to tool_definitions")
+	rootCmd.Flags().BoolVar(&capabilities, "resources-and-prompts", true,
+		"Also write the resources and prompts the server reports")
+	rootCmd.Flags().StringVar(&credentialsFile, "credentials", "",
+		"YAML file with the headers and OAuth tokens of remote servers, keyed by entry name")
+	rootCmd.Flags().StringVar(&secretsFile, "secrets-file", "",
+		"Env var values to inject into started servers: a dotenv file, or a .yaml file keyed by entry name")
+	rootCmd.Flags().BoolVar(&passEnv, "pass-env", false,
+		"Inject the env vars a spec declares from the environment when the secrets file doesn't set them")
 }
 
 func main() {
@@ -53,85 +128,243 @@ func main() {
 	}
 }
 
-func runUpdate(_ *cobra.Command, args []string) error {
-	specPath = args[0]
+func runUpdate(cmd *cobra.Command, args []string) error {
+	specPaths, err := specArgs(args)
+	if err != nil {
+		return err
+	}
+	if parallel < 1 {
+		return fmt.Errorf("--parallel must be at least 1")
+	}
+	if readyTimeout <= 0 || readyInterval <= 0 {
+		return fmt.Errorf("--ready-timeout and --ready-interval must be positive")
+	}
+	cmd.SilenceUsage = true
+	if credentials, err = loadCredentials(credentialsFile); err != nil {
+		return err
+	}
+	secrets, err := toolhive.NewSecrets(secretsFile, passEnv)
+	if err != nil {
+		return err
+	}
+
+	// Let running servers be cleaned up on Ctrl-C instead of leaving them behind
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// Verify spec file exists
-	if _, err := os.Stat(specPath); os.IsNotExist(err) {
-		return fmt.Errorf("spec file not found: %s", specPath)
+	newClient := sync.OnceValues(func() (*toolhive.Client, error) {
+		client, err := toolhive.NewClient(thvPath, verbose)
+		if err != nil {
+			return nil, err
+		}
+		client.SetReadiness(readyTimeout, readyInterval)
+		client.SetSecrets(secrets)
+		return client, nil
+	})
+	results := updateSpecs(ctx, specPaths, newClient)
+	printReport(os.Stdout, results)
+
+	var failed []*updateResult
+	for _, result := range results {
+		if result.status == statusFailed {
+			failed = append(failed, result)
+		}
+	}
+	switch {
+	case len(failed) == 1 && len(results) == 1:
+		return failed[0].err
+	case len(failed) > 0:
+		return fmt.Errorf("%d of %d server(s) failed to update", len(failed), len(results))
 	}
+	return nil
+}
 
-	// Extract server name from path
-	serverDir := filepath.Dir(specPath)
-	serverName := filepath.Base(serverDir)
+// specArgs returns the spec files to update, from the arguments or the registry with --all
+func specArgs(args []string) ([]string, error) {
+	switch {
+	case updateAll && len(args) > 0:
+		return nil, errors.New("use either --all or spec file arguments, not both")
+	case updateAll:
+		return findSpecFiles(registryPath)
+	case len(args) == 0:
+		return nil, errors.New("no spec files given; pass spec files or --all")
+	}
 
-	logger.Infof("Processing server: %s", serverName)
+	var specPaths []string
+	for _, arg := range args {
+		if _, err := os.Stat(arg); os.IsNotExist(err) {
+			return nil, fmt.Errorf("spec file not found: %s", arg)
+		}
+		if !slices.Contains(specPaths, arg) {
+			specPaths = append(specPaths, arg)
+		}
+	}
+	return specPaths, nil
+}
+
+// findSpecFiles returns the spec file of every entry in the registry directory
+func findSpecFiles(dir string) ([]string, error) {
+	var specPaths []string
+	for _, pattern := range []string{"*/spec.yaml", "*/spec.yml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list spec files: %w", err)
+		}
+		for _, match := range matches {
+			if filepath.Base(filepath.Dir(match))[0] != '.' {
+				specPaths = append(specPaths, match)
+			}
+		}
+	}
+	if len(specPaths) == 0 {
+		return nil, fmt.Errorf("no spec files found in %s", dir)
+	}
+	sort.Strings(specPaths)
+	return specPaths, nil
+}
+
+// specUpdate updates the tool list of a single spec file
+type specUpdate struct {
+	path      string
+	name      string
+	newClient func() (*toolhive.Client, error)
+	// prefix tags log lines with the server name when several servers are updated at once
+	prefix string
+}
+
+func (u *specUpdate) infof(format string, args ...any) {
+	logger.Infof(u.prefix+format, args...)
+}
+
+func (u *specUpdate) warnf(format string, args ...any) {
+	logger.Warnf(u.prefix+format, args...)
+}
+
+// run updates the spec file and reports the outcome
+func (u *specUpdate) run(ctx context.Context) *updateResult {
+	result := &updateResult{name: u.name, path: u.path}
+	if ctx.Err() != nil {
+		return result.skip("interrupted")
+	}
+
+	u.infof("Processing server: %s", u.name)
 	if verbose {
-		logger.Infof("Spec file: %s", specPath)
+		u.infof("Spec file: %s", u.path)
 	}
 
 	// Load current spec and get tools
-	currentTools, err := getCurrentTools()
+	spec, err := loadSpec(u.path)
 	if err != nil {
-		return err
+		return result.fail(fmt.Errorf("failed to load spec: %w", err))
+	}
+	var remote mcpclient.Options
+	if spec.IsRemote() {
+		var reason string
+		if remote, reason = remoteOptions(spec, credentials[u.name]); reason != "" {
+			u.infof("Skipping remote server: %s", reason)
+			return result.skip(reason)
+		}
 	}
+	currentTools := spec.GetTools()
+	u.infof("Current tools count: %d", len(currentTools))
 
-	// Fetch new tools from thv
-	newTools, err := fetchToolsFromMCP(serverName)
+	// Fetch new tools from thv, or from the server itself if it is remote
+	var found *discovery
+	if spec.IsRemote() {
+		found, err = u.fetchFromRemote(ctx, spec, remote)
+	} else {
+		found, err = u.fetchFromMCP(ctx, spec)
+	}
+	if err != nil {
+		return result.fail(u.handleFetchError(err, currentTools))
+	}
+
+	changes, err := u.update(spec, found)
 	if err != nil {
-		return handleFetchError(err, currentTools)
+		return result.fail(err)
+	}
+	if len(changes) == 0 {
+		result.status = statusUnchanged
+		return result
+	}
+	result.status = statusUpdated
+	result.detail = strings.Join(changes, ", ")
+	return result
+}
+
+// update writes what the server reported to the spec file and returns the changes for the report
+func (u *specUpdate) update(spec *types.RegistryEntry, found *discovery) ([]string, error) {
+	currentTools := spec.GetTools()
+	newTools := make([]string, 0, len(found.tools))
+	for _, definition := range found.tools {
+		newTools = append(newTools, definition.Name)
 	}
 
-	logger.Infof("New tools count: %d", len(newTools))
+	u.infof("New tools count: %d", len(newTools))
 
 	// Handle empty tools case
-	if err := handleEmptyTools(newTools, currentTools); err != nil {
-		return err
+	if err := u.handleEmptyTools(newTools, currentTools); err != nil {
+		return nil, err
 	}
 
 	// Compare and update tools
-	return compareAndUpdateTools(currentTools, newTools)
-}
-
-func getCurrentTools() ([]string, error) {
-	currentSpec, err := loadSpec(specPath)
+	added, removed, err := u.compareAndUpdateTools(currentTools, newTools)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load spec: %w", err)
+		return nil, err
+	}
+	var changes []string
+	if len(added) > 0 || len(removed) > 0 {
+		changes = append(changes, fmt.Sprintf("+%d -%d tools", len(added), len(removed)))
+	}
+
+	if toolDefinitions && !found.namesOnly {
+		updated, err := u.updateToolDefinitions(spec.ToolDefinitions, found.tools)
+		if err != nil {
+			return nil, err
+		}
+		if updated {
+			changes = append(changes, "tool definitions")
+		}
 	}
 
-	currentTools := currentSpec.GetTools()
-	logger.Infof("Current tools count: %d", len(currentTools))
-	return currentTools, nil
+	if capabilities {
+		updated, err := u.updateCapabilities(spec, found)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, updated...)
+	}
+	return changes, nil
 }
 
-func handleFetchError(err error, currentTools []string) error {
-	logger.Warnf("Failed to fetch tools from MCP server: %v", err)
+func (u *specUpdate) handleFetchError(err error, currentTools []string) error {
+	u.warnf("Failed to fetch tools from MCP server: %v", err)
 
 	if len(currentTools) > 0 && addWarnings {
 		if !dryRun {
-			if err := toolhive.AddWarningComment(specPath, "Tool list fetch failed", "Manual verification may be required"); err != nil {
-				logger.Warnf("Failed to add warning comment: %v", err)
+			if err := toolhive.AddWarningComment(u.path, "Tool list fetch failed", "Manual verification may be required"); err != nil {
+				u.warnf("Failed to add warning comment: %v", err)
 			}
 		} else {
-			logger.Info("[DRY RUN] Would add warning comment about fetch failure")
+			u.infof("[DRY RUN] Would add warning comment about fetch failure")
 		}
 	}
 	return fmt.Errorf("failed to fetch tools: %w", err)
 }
 
-func handleEmptyTools(newTools, currentTools []string) error {
+func (u *specUpdate) handleEmptyTools(newTools, currentTools []string) error {
 	if len(newTools) == 0 && len(currentTools) > 0 {
-		logger.Warnf("No tools detected but spec file had %d tools previously", len(currentTools))
-		logger.Info("Keeping existing tools list")
+		u.warnf("No tools detected but spec file had %d tools previously", len(currentTools))
+		u.infof("Keeping existing tools list")
 
 		if addWarnings {
 			if !dryRun {
-				if err := toolhive.AddWarningComment(specPath, "Tool list could not be auto-updated",
+				if err := toolhive.AddWarningComment(u.path, "Tool list could not be auto-updated",
 					"Please verify the tools list manually"); err != nil {
-					logger.Warnf("Failed to add warning comment: %v", err)
+					u.warnf("Failed to add warning comment: %v", err)
 				}
 			} else {
-				logger.Info("[DRY RUN] Would add warning comment about empty tool list")
+				u.infof("[DRY RUN] Would add warning comment about empty tool list")
 			}
 		}
 		return fmt.Errorf("empty tools list detected")
@@ -139,36 +372,170 @@ func handleEmptyTools(newTools, currentTools []string) error {
 	return nil
 }
 
-func compareAndUpdateTools(currentTools, newTools []string) error {
+// compareAndUpdateTools writes the new tool list if it differs and returns the added and removed tools
+func (u *specUpdate) compareAndUpdateTools(currentTools, newTools []string) ([]string, []string, error) {
 	// Sort both lists for comparison
 	sort.Strings(currentTools)
 	sort.Strings(newTools)
 
 	// Check if tools changed using slices.Equal
 	if slices.Equal(currentTools, newTools) {
-		logger.Info("Tools list is already up to date")
-		return nil
+		u.infof("Tools list is already up to date")
+		return nil, nil, nil
 	}
 
 	// Show changes
-	logger.Info("Tools list changes detected:")
+	added, removed := diffNames(currentTools, newTools)
+	u.infof("Tools list changes detected:")
 	if verbose {
-		showDetailedDiff(currentTools, newTools)
+		u.showDetailedDiff(currentTools, newTools)
 	} else {
-		showSummaryDiff(currentTools, newTools)
+		u.showSummaryDiff("tools", added, removed)
 	}
 
 	// Update the spec file
 	if !dryRun {
-		if err := toolhive.UpdateSpecTools(specPath, newTools); err != nil {
-			return fmt.Errorf("failed to update spec file: %w", err)
+		if err := toolhive.UpdateSpecTools(u.path, newTools); err != nil {
+			return nil, nil, fmt.Errorf("failed to update spec file: %w", err)
 		}
-		logger.Info("Successfully updated tools list")
+		u.infof("Successfully updated tools list")
 	} else {
-		logger.Info("[DRY RUN] Would update tools list in spec file")
+		u.infof("[DRY RUN] Would update tools list in spec file")
 	}
 
-	return nil
+	return added, removed, nil
+}
+
+// updateToolDefinitions writes the tool definitions if they differ and reports whether they did
+func (u *specUpdate) updateToolDefinitions(current, definitions []types.ToolDefinition) (bool, error) {
+	keepManualSideEffects(current, definitions)
+
+	// Compare the YAML encodings, as schemas read from JSON and from YAML differ in number types
+	currentYAML, err := yaml.Marshal(current)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode tool definitions: %w", err)
+	}
+	newYAML, err := yaml.Marshal(definitions)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode tool definitions: %w", err)
+	}
+	if bytes.Equal(currentYAML, newYAML) {
+		u.infof("Tool definitions are already up to date")
+		return false, nil
+	}
+
+	if dryRun {
+		u.infof("[DRY RUN] Would update tool definitions in spec file")
+		return true, nil
+	}
+	if err := toolhive.UpdateSpecToolDefinitions(u.path, definitions); err != nil {
+		return false, fmt.Errorf("failed to update tool definitions: %w", err)
+	}
+	u.infof("Successfully updated tool definitions")
+	return true, nil
+}
+
+// updateCapabilities writes the resources, prompts and resource templates the server reported
+// if they differ and returns the changes for the report
+func (u *specUpdate) updateCapabilities(spec *types.RegistryEntry, found *discovery) ([]string, error) {
+	var changes []string
+	change, err := updateList(u, "resources", spec.Resources, found.resources,
+		func(r types.Resource) string { return r.URI }, toolhive.UpdateSpecResources)
+	if err != nil {
+		return nil, err
+	}
+	if change != "" {
+		changes = append(changes, change)
+	}
+
+	change, err = updateList(u, "prompts", spec.Prompts, found.prompts,
+		func(p types.Prompt) string { return p.Name }, toolhive.UpdateSpecPrompts)
+	if err != nil {
+		return nil, err
+	}
+	if change != "" {
+		changes = append(changes, change)
+	}
+
+	change, err = updateList(u, "resource templates", spec.ResourceTemplates, found.resourceTemplates,
+		func(r types.ResourceTemplate) string { return r.URITemplate }, toolhive.UpdateSpecResourceTemplates)
+	if err != nil {
+		return nil, err
+	}
+	if change != "" {
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// updateList writes a list the server reported, such as its resources, if it differs from the
+// spec. Items are matched by key for the summary of added and removed items. It returns the
+// change for the report, or "" if nothing changed.
+func updateList[T any](u *specUpdate, kind string, current, reported []T, key func(T) string,
+	write func(path string, items []T) error) (string, error) {
+	if len(reported) == 0 {
+		if len(current) > 0 {
+			u.warnf("No %s reported but spec file had %d; keeping the existing list", kind, len(current))
+		}
+		return "", nil
+	}
+
+	// Compare the YAML encodings, as that is how the lists are stored
+	currentYAML, err := yaml.Marshal(current)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %s: %w", kind, err)
+	}
+	reportedYAML, err := yaml.Marshal(reported)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %s: %w", kind, err)
+	}
+	if bytes.Equal(currentYAML, reportedYAML) {
+		u.infof("No changes to %s", kind)
+		return "", nil
+	}
+
+	change := kind
+	added, removed := diffNames(keys(current, key), keys(reported, key))
+	if len(added) > 0 || len(removed) > 0 {
+		u.infof("Changes to %s detected:", kind)
+		u.showSummaryDiff(kind, added, removed)
+		change = fmt.Sprintf("+%d -%d %s", len(added), len(removed), kind)
+	}
+
+	if dryRun {
+		u.infof("[DRY RUN] Would update %s in spec file", kind)
+		return change, nil
+	}
+	if err := write(u.path, reported); err != nil {
+		return "", fmt.Errorf("failed to update %s: %w", kind, err)
+	}
+	u.infof("Successfully updated %s", kind)
+	return change, nil
+}
+
+// keys returns the key of every item in a list
+func keys[T any](items []T, key func(T) string) []string {
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		result = append(result, key(item))
+	}
+	return result
+}
+
+// keepManualSideEffects carries over the side effects classified by hand for tools the server
+// doesn't annotate, which would otherwise become unknown
+func keepManualSideEffects(current, definitions []types.ToolDefinition) {
+	manual := make(map[string]string)
+	for _, definition := range current {
+		if len(definition.Annotations) == 0 && definition.SideEffect != "" {
+			manual[definition.Name] = definition.SideEffect
+		}
+	}
+	for i, definition := range definitions {
+		if effect, ok := manual[definition.Name]; ok && len(definition.Annotations) == 0 {
+			definitions[i].SideEffect = effect
+		}
+	}
 }
 
 func loadSpec(path string) (*types.RegistryEntry, error) {
@@ -185,61 +552,116 @@ func loadSpec(path string) (*types.RegistryEntry, error) {
 	return &entry, nil
 }
 
-func fetchToolsFromMCP(serverName string) ([]string, error) {
-	// Load the spec to get the configuration
-	spec, err := loadSpec(specPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load spec: %w", err)
-	}
+// discovery holds what a running server reported. Resources, prompts and resource templates
+// are left empty when they are not requested or the server fails to list them; thv doesn't
+// list resource templates, so only remote servers report them.
+type discovery struct {
+	tools []types.ToolDefinition
+	// namesOnly is set when only the names of the tools are known, so their definitions are
+	// left as they are
+	namesOnly bool
+	resources []types.Resource
+	prompts   []types.Prompt
+
+	resourceTemplates []types.ResourceTemplate
+}
 
+func (u *specUpdate) fetchFromMCP(ctx context.Context, spec *types.RegistryEntry) (*discovery, error) {
 	// Create ToolHive client
-	client, err := toolhive.NewClient(thvPath, verbose)
+	client, err := u.newClient()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create ToolHive client: %w", err)
 	}
 
 	// Run the MCP server
-	logger.Infof("Starting temporary MCP server: %s", serverName)
-	tempName, err := client.RunServer(spec, serverName)
+	u.infof("Starting temporary MCP server: %s", u.name)
+	tempName, err := client.RunServer(ctx, spec, u.name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to run server: %w", err)
 	}
-	defer func() {
-		// Clean up the temporary server
-		if err := client.StopServer(tempName); err != nil {
-			logger.Warnf("Failed to stop temporary server %s: %v", tempName, err)
-		}
-		//if err := client.RemoveServer(tempName); err != nil {
-		//	logger.Warnf("Failed to remove temporary server %s: %v", tempName, err)
-		//}
-	}()
+	// Clean up the temporary server
+	defer client.Cleanup(tempName)
 
 	// Query the server for tools
-	tools, err := client.ListTools(tempName)
+	found, err := u.listTools(client, tempName)
 	if err != nil {
 		// Get Logs for debugging
 		logs, logErr := client.Logs(tempName)
 		if logErr != nil {
-			logger.Warnf("Failed to fetch logs from temporary server %s: %v", tempName, logErr)
+			u.warnf("Failed to fetch logs from temporary server %s: %v", tempName, logErr)
 		}
 		if logs != "" {
-			logger.Infof("Logs from temporary server %s:\n%s", tempName, logs)
+			u.infof("Logs from temporary server %s:\n%s", tempName, logs)
 		}
 		return nil, fmt.Errorf("failed to list tools: %w", err)
 	}
+	if !capabilities {
+		return found, nil
+	}
 
-	return tools, nil
+	// Servers without resources or prompts may reject the listing; that isn't a failure
+	if found.resources, err = client.ListResources(tempName); err != nil {
+		u.warnf("Failed to list resources: %v", err)
+	}
+	if found.prompts, err = client.ListPrompts(tempName); err != nil {
+		u.warnf("Failed to list prompts: %v", err)
+	}
+	return found, nil
 }
 
-func showDetailedDiff(current, newTools []string) {
+// listTools lists the tools of a running server with their definitions. Without
+// --tool-definitions, or if thv's output can't be parsed as JSON, only the names are read,
+// falling back to thv's text output.
+func (u *specUpdate) listTools(client *toolhive.Client, serverName string) (*discovery, error) {
+	if toolDefinitions {
+		tools, err := client.ListToolDefinitions(serverName)
+		if err == nil {
+			return &discovery{tools: tools}, nil
+		}
+		if !errors.Is(err, toolhive.ErrUnparsableOutput) {
+			return nil, err
+		}
+		u.warnf("Failed to read tool definitions, listing only the tool names: %v", err)
+	}
+
+	names, err := client.ListTools(serverName)
+	if err != nil {
+		return nil, err
+	}
+	found := &discovery{tools: make([]types.ToolDefinition, 0, len(names)), namesOnly: true}
+	for _, name := range names {
+		found.tools = append(found.tools, types.ToolDefinition{Name: name})
+	}
+	return found, nil
+}
+
+func (u *specUpdate) showDetailedDiff(current, newTools []string) {
 	diff := cmp.Diff(current, newTools)
 	if diff != "" {
-		logger.Info("Detailed diff:")
+		u.infof("Detailed diff:")
 		fmt.Println(diff)
 	}
 }
 
-func showSummaryDiff(current, newTools []string) {
+// showSummaryDiff lists the added and removed items of a kind, such as tools
+func (u *specUpdate) showSummaryDiff(kind string, added, removed []string) {
+	if len(added) > 0 {
+		u.infof("  Added %s (%d):", kind, len(added))
+		for _, t := range added {
+			u.infof("    + %s", t)
+		}
+	}
+
+	if len(removed) > 0 {
+		u.infof("  Removed %s (%d):", kind, len(removed))
+		for _, t := range removed {
+			u.infof("    - %s", t)
+		}
+	}
+}
+
+// diffNames returns the sorted names that are only in newTools and only in current
+func diffNames(current, newTools []string) ([]string, []string) {
 	currentSet := make(map[string]bool)
 	newSet := make(map[string]bool)
 
@@ -268,18 +690,5 @@ func showSummaryDiff(current, newTools []string) {
 
 	sort.Strings(added)
 	sort.Strings(removed)
-
-	if len(added) > 0 {
-		logger.Infof("  Added tools (%d):", len(added))
-		for _, t := range added {
-			logger.Infof("    + %s", t)
-		}
-	}
-
-	if len(removed) > 0 {
-		logger.Infof("  Removed tools (%d):", len(removed))
-		for _, t := range removed {
-			logger.Infof("    - %s", t)
-		}
-	}
+	return added, removed
 }
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/stacklok/toolhive-registry/pkg/mcpclient"
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// remoteCredentials is what update-tools needs to connect to a remote server, read from the
+// credentials file under the entry name:
+//
+//	stripe:
+//	  headers:
+//	    Authorization: Bearer sk_test_...
+//	notion:
+//	  token: ntn_...
+type remoteCredentials struct {
+	// Headers are sent with every request to the server
+	Headers map[string]string `yaml:"headers"`
+	// Token is a pre-obtained OAuth access token, sent as a bearer token
+	Token string `yaml:"token"`
+}
+
+// loadCredentials reads a credentials file; an empty path gives no credentials
+func loadCredentials(path string) (map[string]remoteCredentials, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path) // #nosec G304 - path is given by the user
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+	var creds map[string]remoteCredentials
+	if err := yaml.Unmarshal(data, &creds); err != nil {
+		// The YAML error may quote the offending line, which can hold a secret
+		return nil, fmt.Errorf("failed to parse credentials file %s", path)
+	}
+	return creds, nil
+}
+
+// remoteOptions returns the HTTP options for connecting to a remote server with its
+// credentials, or the reason it can't be connected to
+func remoteOptions(spec *types.RegistryEntry, creds remoteCredentials) (mcpclient.Options, string) {
+	var opts mcpclient.Options
+	switch spec.GetTransport() {
+	case mcpclient.TransportSSE, mcpclient.TransportStreamableHTTP:
+	default:
+		return opts, fmt.Sprintf("unsupported remote transport '%s'", spec.GetTransport())
+	}
+
+	opts.Headers = make(http.Header)
+	for header, value := range creds.Headers {
+		opts.Headers.Set(header, value)
+	}
+	for _, header := range spec.RemoteServerMetadata.Headers {
+		if header.Required && opts.Headers.Get(header.Name) == "" {
+			return opts, fmt.Sprintf("no credentials for required header %s", header.Name)
+		}
+	}
+	if spec.OAuthConfig != nil {
+		if creds.Token == "" {
+			return opts, "OAuth server without a token in the credentials file"
+		}
+		opts.Headers.Set("Authorization", "Bearer "+creds.Token)
+	}
+	return opts, ""
+}
+
+// fetchFromRemote connects to a remote server and lists what it provides
+func (u *specUpdate) fetchFromRemote(ctx context.Context, spec *types.RegistryEntry, opts mcpclient.Options) (*discovery, error) {
+	ctx, cancel := context.WithTimeout(ctx, readyTimeout)
+	defer cancel()
+
+	u.infof("Connecting to remote MCP server: %s", spec.URL)
+	client, err := mcpclient.Connect(ctx, spec.GetTransport(), spec.URL, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := client.Close(); err != nil {
+			u.warnf("Failed to close connection: %v", err)
+		}
+	}()
+
+	tools, err := client.ListTools(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tools: %w", err)
+	}
+	found := &discovery{tools: tools}
+	if !capabilities {
+		return found, nil
+	}
+
+	if found.resources, err = client.ListResources(ctx); err != nil {
+		u.warnf("Failed to list resources: %v", err)
+	}
+	if found.prompts, err = client.ListPrompts(ctx); err != nil {
+		u.warnf("Failed to list prompts: %v", err)
+	}
+	if found.resourceTemplates, err = client.ListResourceTemplates(ctx); err != nil {
+		u.warnf("Failed to list resource templates: %v", err)
+	}
+	return found, nil
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/mcpclient"
+	"github.com/stacklok/toolhive-registry/pkg/mcpclient/mcptest"
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func testRemoteSpec(transport, url string) *types.RegistryEntry {
+	return &types.RegistryEntry{
+		RemoteServerMetadata: &toolhiveRegistry.RemoteServerMetadata{
+			BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{Transport: transport, Tools: []string{"search"}},
+			URL:                url,
+		},
+	}
+}
+
+func TestLoadCredentials(t *testing.T) {
+	t.Parallel()
+
+	creds, err := loadCredentials("")
+	require.NoError(t, err)
+	assert.Nil(t, creds)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "credentials.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`stripe:
+  headers:
+    Authorization: Bearer sk_test_123
+notion:
+  token: ntn_456
+`), 0600))
+	creds, err = loadCredentials(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]remoteCredentials{
+		"stripe": {Headers: map[string]string{"Authorization": "Bearer sk_test_123"}},
+		"notion": {Token: "ntn_456"},
+	}, creds)
+
+	// The parse error doesn't quote the file, which holds secrets
+	malformed := filepath.Join(dir, "malformed.yaml")
+	require.NoError(t, os.WriteFile(malformed, []byte("stripe:\n  token: [sk_live_789\n"), 0600))
+	_, err = loadCredentials(malformed)
+	require.ErrorContains(t, err, "failed to parse credentials file")
+	assert.NotContains(t, err.Error(), "sk_live_789")
+
+	_, err = loadCredentials(filepath.Join(dir, "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read credentials file")
+}
+
+func TestRemoteOptions(t *testing.T) {
+	t.Parallel()
+
+	withHeader := func(required bool) *types.RegistryEntry {
+		spec := testRemoteSpec(mcpclient.TransportStreamableHTTP, "https://example.com/mcp")
+		spec.RemoteServerMetadata.Headers = []*toolhiveRegistry.Header{{Name: "X-Api-Key", Required: required}}
+		return spec
+	}
+	withOAuth := func() *types.RegistryEntry {
+		spec := testRemoteSpec(mcpclient.TransportSSE, "https://example.com/sse")
+		spec.OAuthConfig = &toolhiveRegistry.OAuthConfig{Issuer: "https://auth.example.com"}
+		return spec
+	}
+
+	tests := []struct {
+		name        string
+		spec        *types.RegistryEntry
+		creds       remoteCredentials
+		wantReason  string
+		wantHeaders http.Header
+	}{
+		{
+			name:       "stdio transport",
+			spec:       testRemoteSpec("stdio", "https://example.com/mcp"),
+			wantReason: "unsupported remote transport 'stdio'",
+		},
+		{
+			name:       "missing required header",
+			spec:       withHeader(true),
+			creds:      remoteCredentials{Headers: map[string]string{"X-Other": "value"}},
+			wantReason: "no credentials for required header X-Api-Key",
+		},
+		{
+			name:        "missing optional header",
+			spec:        withHeader(false),
+			wantHeaders: http.Header{},
+		},
+		{
+			name:        "required header",
+			spec:        withHeader(true),
+			creds:       remoteCredentials{Headers: map[string]string{"x-api-key": "secret"}},
+			wantHeaders: http.Header{"X-Api-Key": {"secret"}},
+		},
+		{
+			name:       "OAuth without a token",
+			spec:       withOAuth(),
+			creds:      remoteCredentials{Headers: map[string]string{"X-Other": "value"}},
+			wantReason: "OAuth server without a token in the credentials file",
+		},
+		{
+			name:        "OAuth token",
+			spec:        withOAuth(),
+			creds:       remoteCredentials{Token: "ntn_456"},
+			wantHeaders: http.Header{"Authorization": {"Bearer ntn_456"}},
+		},
+		{
+			name: "OAuth token replaces an Authorization header",
+			spec: withOAuth(),
+			creds: remoteCredentials{
+				Headers: map[string]string{"Authorization": "Basic old", "X-Region": "eu"},
+				Token:   "ntn_456",
+			},
+			wantHeaders: http.Header{"Authorization": {"Bearer ntn_456"}, "X-Region": {"eu"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			opts, reason := remoteOptions(tt.spec, tt.creds)
+			assert.Equal(t, tt.wantReason, reason)
+			if tt.wantReason == "" {
+				assert.Equal(t, tt.wantHeaders, opts.Headers)
+			}
+		})
+	}
+}
+
+func TestFetchFromRemote(t *testing.T) {
+	t.Parallel()
+
+	server := &mcptest.Server{
+		Tools: []map[string]any{
+			{"name": "search", "description": "Searches", "annotations": map[string]any{"readOnlyHint": true}},
+		},
+		Resources:         []map[string]any{{"uri": "search://recent", "name": "recent"}},
+		ResourceTemplates: []map[string]any{{"uriTemplate": "search://{query}", "name": "results"}},
+		Prompts:           []map[string]any{{"name": "refine"}},
+	}
+	var mu sync.Mutex
+	var authorization []string
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authorization = append(authorization, r.Header.Get("Authorization"))
+		mu.Unlock()
+		server.ServeHTTP(w, r)
+	}))
+	defer httpServer.Close()
+
+	for _, transport := range []struct{ name, path string }{
+		{mcpclient.TransportStreamableHTTP, mcptest.StreamableHTTPPath},
+		{mcpclient.TransportSSE, mcptest.SSEPath},
+	} {
+		mu.Lock()
+		authorization = nil
+		mu.Unlock()
+		spec := testRemoteSpec(transport.name, httpServer.URL+transport.path)
+		spec.OAuthConfig = &toolhiveRegistry.OAuthConfig{Issuer: "https://auth.example.com"}
+		opts, reason := remoteOptions(spec, remoteCredentials{Token: "token"})
+		require.Empty(t, reason)
+
+		u := &specUpdate{name: "remote"}
+		found, err := u.fetchFromRemote(context.Background(), spec, opts)
+		require.NoError(t, err, transport.name)
+		assert.Equal(t, []types.ToolDefinition{{
+			Name: "search", Description: "Searches", Annotations: map[string]any{"readOnlyHint": true},
+			SideEffect: types.SideEffectReadOnly,
+		}}, found.tools)
+		assert.False(t, found.namesOnly)
+		assert.Equal(t, []types.Resource{{URI: "search://recent", Name: "recent"}}, found.resources)
+		assert.Equal(t, []types.ResourceTemplate{{URITemplate: "search://{query}", Name: "results"}},
+			found.resourceTemplates)
+		assert.Equal(t, []types.Prompt{{Name: "refine"}}, found.prompts)
+
+		// Every request carries the token, the messages of the SSE transport included
+		mu.Lock()
+		assert.NotEmpty(t, authorization)
+		for _, header := range authorization {
+			assert.Equal(t, "Bearer token", header, transport.name)
+		}
+		mu.Unlock()
+	}
+}
@@ -0,0 +1,103 @@
+// Package imageref parses OCI image references following the distribution reference grammar
+package imageref
+
+import (
+	"fmt"
+
+	"github.com/distribution/reference"
+)
+
+const (
+	// DockerHubDomain is the registry domain of images without an explicit registry
+	DockerHubDomain = "docker.io"
+
+	// LatestTag is the tag implied by references without a tag or digest
+	LatestTag = "latest"
+)
+
+// Reference is a parsed image reference such as registry.internal:5000/team/server:1.2.3@sha256:...
+type Reference struct {
+	// Domain is the registry host, including any port (e.g. ghcr.io, localhost:5000, docker.io)
+	Domain string
+	// Path is the repository path inside the registry, e.g. stacklok/server or library/nginx
+	Path string
+	// Tag is the image tag, empty if the reference has none
+	Tag string
+	// Digest is the content digest (e.g. sha256:...), empty if the reference has none
+	Digest string
+
+	named reference.Named
+}
+
+// Parse parses an image reference. References without a registry resolve to Docker Hub,
+// and single-segment Docker Hub paths to the library namespace, as the docker CLI does.
+func Parse(image string) (*Reference, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image reference %q: %w", image, err)
+	}
+
+	ref := &Reference{
+		Domain: reference.Domain(named),
+		Path:   reference.Path(named),
+		named:  named,
+	}
+	if tagged, ok := named.(reference.Tagged); ok {
+		ref.Tag = tagged.Tag()
+	}
+	if digested, ok := named.(reference.Digested); ok {
+		ref.Digest = digested.Digest().String()
+	}
+	return ref, nil
+}
+
+// Name returns the full repository name including the registry, e.g. docker.io/library/nginx
+func (r *Reference) Name() string {
+	return r.Domain + "/" + r.Path
+}
+
+// FamiliarName returns the repository name as users usually write it, omitting
+// docker.io and library/ for Docker Hub images
+func (r *Reference) FamiliarName() string {
+	return reference.FamiliarName(r.named)
+}
+
+// Repository returns the repository as it is published for the registry: the familiar
+// name for Docker Hub images and the path for every other registry
+func (r *Reference) Repository() string {
+	if r.IsDockerHub() {
+		return r.FamiliarName()
+	}
+	return r.Path
+}
+
+// IsDockerHub reports whether the image is hosted on Docker Hub
+func (r *Reference) IsDockerHub() bool {
+	return r.Domain == DockerHubDomain
+}
+
+// Version returns the tag, or the digest for digest-only references, or latest if neither is set
+func (r *Reference) Version() string {
+	switch {
+	case r.Tag != "":
+		return r.Tag
+	case r.Digest != "":
+		return r.Digest
+	default:
+		return LatestTag
+	}
+}
+
+// TagOrDigest returns the tag and digest as written after the repository name,
+// e.g. 1.2.3, sha256:... or 1.2.3@sha256:..., and latest if neither is set
+func (r *Reference) TagOrDigest() string {
+	if r.Tag != "" && r.Digest != "" {
+		return r.Tag + "@" + r.Digest
+	}
+	return r.Version()
+}
+
+// String returns the normalized reference, including the registry
+func (r *Reference) String() string {
+	return r.named.String()
+}
@@ -0,0 +1,73 @@
+package imageref
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		image      string
+		domain     string
+		path       string
+		tag        string
+		digest     string
+		repository string
+		version    string
+	}{
+		{"nginx", "docker.io", "library/nginx", "", "", "nginx", "latest"},
+		{"stacklok/server:1.2.3", "docker.io", "stacklok/server", "1.2.3", "", "stacklok/server", "1.2.3"},
+		{"docker.io/library/nginx:1.27", "docker.io", "library/nginx", "1.27", "", "nginx", "1.27"},
+		{"ghcr.io/org/team/server:v0.1.0", "ghcr.io", "org/team/server", "v0.1.0", "", "org/team/server", "v0.1.0"},
+		{"localhost/server:dev", "localhost", "server", "dev", "", "server", "dev"},
+		{"localhost:5000/server", "localhost:5000", "server", "", "", "server", "latest"},
+		{
+			"registry.internal:5000/mcp/server:2.0.0", "registry.internal:5000", "mcp/server", "2.0.0", "",
+			"mcp/server", "2.0.0",
+		},
+		{"ghcr.io/org/server@" + testDigest, "ghcr.io", "org/server", "", testDigest, "org/server", testDigest},
+		{
+			"registry.internal:5000/server:1.0.0@" + testDigest, "registry.internal:5000", "server", "1.0.0", testDigest,
+			"server", "1.0.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			t.Parallel()
+			ref, err := Parse(tt.image)
+			require.NoError(t, err)
+			assert.Equal(t, tt.domain, ref.Domain)
+			assert.Equal(t, tt.path, ref.Path)
+			assert.Equal(t, tt.tag, ref.Tag)
+			assert.Equal(t, tt.digest, ref.Digest)
+			assert.Equal(t, tt.repository, ref.Repository())
+			assert.Equal(t, tt.version, ref.Version())
+		})
+	}
+}
+
+func TestParse_TagAndDigest(t *testing.T) {
+	t.Parallel()
+
+	ref, err := Parse("registry.internal:5000/server:1.0.0@" + testDigest)
+	require.NoError(t, err)
+	assert.Equal(t, "registry.internal:5000/server", ref.Name())
+	assert.Equal(t, "1.0.0@"+testDigest, ref.TagOrDigest())
+	assert.Equal(t, "registry.internal:5000/server:1.0.0@"+testDigest, ref.String())
+}
+
+func TestParse_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, image := range []string{"", "org/Server", "server:bad tag", "server@sha256:short", "host:port:5000/server"} {
+		_, err := Parse(image)
+		assert.Error(t, err, image)
+	}
+}
@@ -0,0 +1,298 @@
+// Package mcpclient is a minimal MCP client for discovering the tools, resources and prompts
+// of a server. It speaks JSON-RPC directly over stdio, SSE and streamable HTTP, so discovery
+// doesn't depend on the thv binary.
+package mcpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+const (
+	// ProtocolVersion is the MCP protocol version the client asks for; servers answer with
+	// the version they support
+	ProtocolVersion = "2025-06-18"
+
+	clientName    = "toolhive-registry"
+	clientVersion = "1.0.0"
+
+	methodInitialize = "initialize"
+	// maxPages bounds the pages read from a list, in case a server never stops returning cursors
+	maxPages = 1000
+)
+
+// Transport names, as used in the transport field of spec files
+const (
+	TransportStdio          = "stdio"
+	TransportSSE            = "sse"
+	TransportStreamableHTTP = "streamable-http"
+)
+
+// transport carries JSON-RPC messages between the client and a server
+type transport interface {
+	// call sends a request and waits for its response
+	call(ctx context.Context, msg *message) (*message, error)
+	// notify sends a notification, which has no response
+	notify(ctx context.Context, msg *message) error
+	close() error
+}
+
+// Client is a connection to an MCP server. Initialize must be called before anything is listed.
+type Client struct {
+	transport transport
+	nextID    atomic.Int64
+	server    *InitializeResult
+}
+
+// InitializeResult is what a server reports about itself when the connection is initialized
+type InitializeResult struct {
+	ProtocolVersion string             `json:"protocolVersion"`
+	Capabilities    ServerCapabilities `json:"capabilities"`
+	ServerInfo      struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"serverInfo"`
+	Instructions string `json:"instructions,omitempty"`
+}
+
+// ServerCapabilities lists the features a server supports; a nil field means the feature
+// isn't supported
+type ServerCapabilities struct {
+	Tools     map[string]any `json:"tools,omitempty"`
+	Resources map[string]any `json:"resources,omitempty"`
+	Prompts   map[string]any `json:"prompts,omitempty"`
+}
+
+func newClient(t transport) *Client {
+	return &Client{transport: t}
+}
+
+// Connect creates a client for a server that is reachable at endpoint over the named HTTP
+// transport and initializes it. Servers that use stdio are started with Spawn instead.
+func Connect(ctx context.Context, transport, endpoint string, opts Options) (*Client, error) {
+	var client *Client
+	switch transport {
+	case TransportSSE:
+		var err error
+		if client, err = NewSSE(ctx, endpoint, opts); err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", endpoint, err)
+		}
+	case TransportStreamableHTTP:
+		client = NewStreamableHTTP(endpoint, opts)
+	case TransportStdio:
+		return nil, errors.New("stdio servers have no endpoint to connect to")
+	default:
+		return nil, fmt.Errorf("unsupported transport '%s'", transport)
+	}
+
+	if _, err := client.Initialize(ctx); err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// Initialize negotiates the protocol version and capabilities with the server
+func (c *Client) Initialize(ctx context.Context) (*InitializeResult, error) {
+	params := map[string]any{
+		"protocolVersion": ProtocolVersion,
+		"capabilities":    map[string]any{},
+		"clientInfo":      map[string]string{"name": clientName, "version": clientVersion},
+	}
+	var result InitializeResult
+	if err := c.call(ctx, methodInitialize, params, &result); err != nil {
+		return nil, err
+	}
+	if err := c.transport.notify(ctx, &message{JSONRPC: "2.0", Method: "notifications/initialized"}); err != nil {
+		return nil, fmt.Errorf("notifications/initialized: %w", err)
+	}
+
+	c.server = &result
+	return &result, nil
+}
+
+// Close ends the connection to the server
+func (c *Client) Close() error {
+	return c.transport.close()
+}
+
+// ListTools returns the tools of the server, sorted by name
+func (c *Client) ListTools(ctx context.Context) ([]types.ToolDefinition, error) {
+	if c.server == nil {
+		return nil, errors.New("client is not initialized")
+	}
+
+	tools, err := listAll[struct {
+		Name        string         `json:"name"`
+		Description string         `json:"description"`
+		InputSchema map[string]any `json:"inputSchema"`
+		Annotations map[string]any `json:"annotations"`
+	}](ctx, c, "tools/list", "tools")
+	if err != nil {
+		return nil, err
+	}
+
+	definitions := make([]types.ToolDefinition, 0, len(tools))
+	for _, tool := range tools {
+		definitions = append(definitions, types.ToolDefinition{
+			Name:        tool.Name,
+			Description: tool.Description,
+			InputSchema: tool.InputSchema,
+			Annotations: tool.Annotations,
+			SideEffect:  types.ClassifyTool(tool.Annotations),
+		})
+	}
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].Name < definitions[j].Name
+	})
+	return definitions, nil
+}
+
+// ListResources returns the resources of the server, sorted by URI. Servers without the
+// resources capability have none.
+func (c *Client) ListResources(ctx context.Context) ([]types.Resource, error) {
+	if c.server == nil {
+		return nil, errors.New("client is not initialized")
+	}
+	if c.server.Capabilities.Resources == nil {
+		return nil, nil
+	}
+
+	listed, err := listAll[struct {
+		URI         string `json:"uri"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		MIMEType    string `json:"mimeType"`
+	}](ctx, c, "resources/list", "resources")
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]types.Resource, 0, len(listed))
+	for _, resource := range listed {
+		resources = append(resources, types.Resource(resource))
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].URI < resources[j].URI
+	})
+	return resources, nil
+}
+
+// ListResourceTemplates returns the resource templates of the server, sorted by URI template.
+// Servers without the resources capability have none.
+func (c *Client) ListResourceTemplates(ctx context.Context) ([]types.ResourceTemplate, error) {
+	if c.server == nil {
+		return nil, errors.New("client is not initialized")
+	}
+	if c.server.Capabilities.Resources == nil {
+		return nil, nil
+	}
+
+	listed, err := listAll[struct {
+		URITemplate string `json:"uriTemplate"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		MIMEType    string `json:"mimeType"`
+	}](ctx, c, "resources/templates/list", "resourceTemplates")
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make([]types.ResourceTemplate, 0, len(listed))
+	for _, template := range listed {
+		templates = append(templates, types.ResourceTemplate(template))
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].URITemplate < templates[j].URITemplate
+	})
+	return templates, nil
+}
+
+// ListPrompts returns the prompt templates of the server, sorted by name. Servers without the
+// prompts capability have none.
+func (c *Client) ListPrompts(ctx context.Context) ([]types.Prompt, error) {
+	if c.server == nil {
+		return nil, errors.New("client is not initialized")
+	}
+	if c.server.Capabilities.Prompts == nil {
+		return nil, nil
+	}
+
+	prompts, err := listAll[types.Prompt](ctx, c, "prompts/list", "prompts")
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(prompts, func(i, j int) bool {
+		return prompts[i].Name < prompts[j].Name
+	})
+	return prompts, nil
+}
+
+// call sends a request and decodes its result into result
+func (c *Client) call(ctx context.Context, method string, params, result any) error {
+	msg := &message{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage(strconv.FormatInt(c.nextID.Add(1), 10)),
+		Method:  method,
+		Params:  params,
+	}
+	reply, err := c.transport.call(ctx, msg)
+	if err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	if reply.Error != nil {
+		return fmt.Errorf("%s: %w", method, reply.Error)
+	}
+	if err := json.Unmarshal(reply.Result, result); err != nil {
+		return fmt.Errorf("%s: failed to decode result: %w", method, err)
+	}
+	return nil
+}
+
+// listAll calls a paginated list method, following the cursors until the last page, and
+// returns the items the pages hold under field
+func listAll[T any](ctx context.Context, c *Client, method, field string) ([]T, error) {
+	var items []T
+	seen := make(map[string]bool)
+	var cursor string
+	for range maxPages {
+		var params any
+		if cursor != "" {
+			params = map[string]string{"cursor": cursor}
+		}
+		var page map[string]json.RawMessage
+		if err := c.call(ctx, method, params, &page); err != nil {
+			return nil, err
+		}
+
+		var pageItems []T
+		if raw, ok := page[field]; ok {
+			if err := json.Unmarshal(raw, &pageItems); err != nil {
+				return nil, fmt.Errorf("%s: failed to decode %s: %w", method, field, err)
+			}
+		}
+		items = append(items, pageItems...)
+
+		cursor = ""
+		if raw, ok := page["nextCursor"]; ok {
+			if err := json.Unmarshal(raw, &cursor); err != nil {
+				return nil, fmt.Errorf("%s: invalid cursor: %w", method, err)
+			}
+		}
+		if cursor == "" {
+			return items, nil
+		}
+		if seen[cursor] {
+			return nil, fmt.Errorf("%s: server repeated cursor '%s'", method, cursor)
+		}
+		seen[cursor] = true
+	}
+	return nil, fmt.Errorf("%s: more than %d pages", method, maxPages)
+}
@@ -0,0 +1,251 @@
+package mcpclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/mcpclient/mcptest"
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func newTestServer() *mcptest.Server {
+	return &mcptest.Server{
+		Tools: []map[string]any{
+			{"name": "write", "description": "Writes a file", "annotations": map[string]any{"destructiveHint": true}},
+			{
+				"name": "read", "description": "Reads a file", "inputSchema": map[string]any{"type": "object"},
+				"annotations": map[string]any{"readOnlyHint": true},
+			},
+			{"name": "list"},
+			{"name": "fetch", "annotations": map[string]any{"openWorldHint": true}},
+		},
+		Resources: []map[string]any{
+			{"uri": "file:///b.txt", "name": "b"},
+			{"uri": "file:///a.md", "name": "a", "mimeType": "text/markdown"},
+		},
+		ResourceTemplates: []map[string]any{
+			{"uriTemplate": "file:///{path}", "name": "file", "description": "A file by path"},
+		},
+		Prompts: []map[string]any{
+			{"name": "summarize", "arguments": []map[string]any{{"name": "path", "required": true}}},
+		},
+		PageSize: 2,
+	}
+}
+
+// stdioClient connects a client to the fake server over in-process pipes
+func stdioClient(t *testing.T, server *mcptest.Server) *Client {
+	t.Helper()
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+	go func() {
+		_ = server.ServeStdio(serverReader, serverWriter)
+		_ = serverWriter.Close()
+	}()
+	return NewStdio(clientReader, clientWriter)
+}
+
+func TestClient_Transports(t *testing.T) {
+	t.Parallel()
+
+	for name, connect := range map[string]func(t *testing.T, server *mcptest.Server) *Client{
+		"stdio": stdioClient,
+		"sse": func(t *testing.T, server *mcptest.Server) *Client {
+			t.Helper()
+			httpServer := httptest.NewServer(server)
+			t.Cleanup(httpServer.Close)
+			client, err := Connect(context.Background(), TransportSSE, httpServer.URL+mcptest.SSEPath, Options{})
+			require.NoError(t, err)
+			return client
+		},
+		"streamable-http": func(t *testing.T, server *mcptest.Server) *Client {
+			t.Helper()
+			httpServer := httptest.NewServer(server)
+			t.Cleanup(httpServer.Close)
+			client, err := Connect(context.Background(), TransportStreamableHTTP,
+				httpServer.URL+mcptest.StreamableHTTPPath, Options{})
+			require.NoError(t, err)
+			return client
+		},
+		"streamable-http with event streams": func(t *testing.T, server *mcptest.Server) *Client {
+			t.Helper()
+			server.Stream = true
+			httpServer := httptest.NewServer(server)
+			t.Cleanup(httpServer.Close)
+			client, err := Connect(context.Background(), TransportStreamableHTTP,
+				httpServer.URL+mcptest.StreamableHTTPPath, Options{})
+			require.NoError(t, err)
+			return client
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+
+			server := newTestServer()
+			client := connect(t, server)
+			defer client.Close()
+			if name == "stdio" {
+				_, err := client.Initialize(ctx)
+				require.NoError(t, err)
+			}
+
+			tools, err := client.ListTools(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, []types.ToolDefinition{
+				{Name: "fetch", Annotations: map[string]any{"openWorldHint": true}, SideEffect: types.SideEffectDestructive},
+				{Name: "list", SideEffect: types.SideEffectUnknown},
+				{
+					Name: "read", Description: "Reads a file", InputSchema: map[string]any{"type": "object"},
+					Annotations: map[string]any{"readOnlyHint": true}, SideEffect: types.SideEffectReadOnly,
+				},
+				{
+					Name: "write", Description: "Writes a file", Annotations: map[string]any{"destructiveHint": true},
+					SideEffect: types.SideEffectDestructive,
+				},
+			}, tools)
+
+			resources, err := client.ListResources(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, []types.Resource{
+				{URI: "file:///a.md", Name: "a", MIMEType: "text/markdown"},
+				{URI: "file:///b.txt", Name: "b"},
+			}, resources)
+
+			templates, err := client.ListResourceTemplates(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, []types.ResourceTemplate{
+				{URITemplate: "file:///{path}", Name: "file", Description: "A file by path"},
+			}, templates)
+
+			prompts, err := client.ListPrompts(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, []types.Prompt{
+				{Name: "summarize", Arguments: []types.PromptArgument{{Name: "path", Required: true}}},
+			}, prompts)
+
+			assert.Equal(t, []string{
+				"initialize", "notifications/initialized", "tools/list", "tools/list", "resources/list",
+				"resources/templates/list", "prompts/list",
+			}, server.Methods())
+		})
+	}
+}
+
+func TestClient_MissingCapabilities(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	server := &mcptest.Server{Tools: []map[string]any{{"name": "read"}}}
+	client := stdioClient(t, server)
+	defer client.Close()
+
+	_, err := client.ListTools(ctx)
+	require.ErrorContains(t, err, "not initialized")
+
+	result, err := client.Initialize(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "mcptest", result.ServerInfo.Name)
+
+	resources, err := client.ListResources(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, resources)
+	templates, err := client.ListResourceTemplates(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, templates)
+	prompts, err := client.ListPrompts(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, prompts)
+	assert.NotContains(t, server.Methods(), "resources/list")
+	assert.NotContains(t, server.Methods(), "resources/templates/list")
+	assert.NotContains(t, server.Methods(), "prompts/list")
+}
+
+func TestClient_Errors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	server := &mcptest.Server{Errors: map[string]string{"tools/list": "tools are unavailable"}}
+	client := stdioClient(t, server)
+	defer client.Close()
+	_, err := client.Initialize(ctx)
+	require.NoError(t, err)
+
+	_, err = client.ListTools(ctx)
+	var rpcErr *Error
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, "tools are unavailable", rpcErr.Message)
+	assert.Contains(t, err.Error(), "tools/list")
+
+	_, err = Connect(ctx, TransportStdio, "", Options{})
+	assert.ErrorContains(t, err, "no endpoint")
+
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	_, err = Connect(ctx, TransportStreamableHTTP, httpServer.URL+"/missing", Options{})
+	assert.ErrorContains(t, err, "404")
+}
+
+func TestNewSSE_RejectsForeignEndpoint(t *testing.T) {
+	t.Parallel()
+
+	for name, endpoint := range map[string]string{
+		"other host":   "http://attacker.example/message",
+		"other scheme": "https://{host}/message",
+		"other port":   "//127.0.0.1:1/message",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				fmt.Fprintf(w, "event: endpoint\ndata: %s\n\n", strings.ReplaceAll(endpoint, "{host}", r.Host))
+				w.(http.Flusher).Flush()
+				<-r.Context().Done()
+			}))
+			defer httpServer.Close()
+
+			// Messages would carry the headers of the stream, so they must not leave its origin
+			_, err := NewSSE(context.Background(), httpServer.URL+mcptest.SSEPath,
+				Options{Headers: http.Header{"Authorization": {"Bearer secret"}}})
+			assert.ErrorContains(t, err, "is not on "+httpServer.URL)
+		})
+	}
+}
+
+func TestSpawn(t *testing.T) {
+	t.Parallel()
+
+	cmd := exec.Command(os.Args[0], "-test.run=TestHelperProcess") // #nosec G204 - re-runs the test binary
+	cmd.Env = append(os.Environ(), "MCPCLIENT_HELPER_PROCESS=1")
+	client, err := Spawn(cmd)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = client.Initialize(ctx)
+	require.NoError(t, err)
+	tools, err := client.ListTools(ctx)
+	require.NoError(t, err)
+	assert.Len(t, tools, 4)
+	require.NoError(t, client.Close())
+}
+
+// TestHelperProcess serves the fake server on stdin and stdout when run by TestSpawn
+func TestHelperProcess(t *testing.T) {
+	t.Parallel()
+	if os.Getenv("MCPCLIENT_HELPER_PROCESS") != "1" {
+		return
+	}
+	_ = newTestServer().ServeStdio(os.Stdin, os.Stdout)
+	os.Exit(0)
+}
@@ -0,0 +1,315 @@
+package mcpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	sessionHeader         = "Mcp-Session-Id"
+	protocolVersionHeader = "MCP-Protocol-Version"
+	// maxErrorBody is how much of an error response body is included in the error
+	maxErrorBody = 512
+	// closeTimeout bounds the request that ends a streamable HTTP session
+	closeTimeout = 5 * time.Second
+)
+
+// Options configure the HTTP transports
+type Options struct {
+	// Headers are sent with every HTTP request, such as Authorization
+	Headers http.Header
+	// HTTPClient sends the HTTP requests; nil uses http.DefaultClient
+	HTTPClient *http.Client
+}
+
+func (o Options) httpClient() *http.Client {
+	if o.HTTPClient != nil {
+		return o.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// newRequest creates an HTTP request with the configured headers
+func (o Options) newRequest(ctx context.Context, method, target string, body []byte) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range o.Headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// checkStatus returns an error with the start of the body for a response that isn't a success
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return fmt.Errorf("unexpected status %s: %s", resp.Status, bytes.TrimSpace(body))
+}
+
+// NewStreamableHTTP creates a client for a server that uses the streamable HTTP transport at
+// endpoint
+func NewStreamableHTTP(endpoint string, opts Options) *Client {
+	return newClient(&httpTransport{endpoint: endpoint, opts: opts})
+}
+
+// httpTransport posts every message to the endpoint and reads the response from the reply,
+// which is either JSON or an event stream
+type httpTransport struct {
+	endpoint string
+	opts     Options
+
+	mu              sync.Mutex
+	session         string
+	protocolVersion string
+}
+
+func (t *httpTransport) call(ctx context.Context, msg *message) (*message, error) {
+	resp, err := t.post(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result *message
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		result = decodeMessage(data)
+	case "text/event-stream":
+		err := readEvents(resp.Body, func(_, data string) error {
+			if reply := decodeMessage([]byte(data)); reply != nil && reply.isResponse() &&
+				string(reply.ID) == string(msg.ID) {
+				result = reply
+				return errStopEvents
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response stream: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unexpected response content type '%s'", mediaType)
+	}
+	if result == nil {
+		return nil, errors.New("server sent no response")
+	}
+
+	if msg.Method == methodInitialize {
+		t.mu.Lock()
+		t.session = resp.Header.Get(sessionHeader)
+		var initialized InitializeResult
+		if json.Unmarshal(result.Result, &initialized) == nil {
+			t.protocolVersion = initialized.ProtocolVersion
+		}
+		t.mu.Unlock()
+	}
+	return result, nil
+}
+
+func (t *httpTransport) notify(ctx context.Context, msg *message) error {
+	resp, err := t.post(ctx, msg)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// close ends the session, if the server started one
+func (t *httpTransport) close() error {
+	t.mu.Lock()
+	session := t.session
+	t.mu.Unlock()
+	if session == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+	defer cancel()
+	req, err := t.opts.newRequest(ctx, http.MethodDelete, t.endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(sessionHeader, session)
+	resp, err := t.opts.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to end session: %w", err)
+	}
+	// Servers that don't let clients end sessions answer 405, which is fine
+	return resp.Body.Close()
+}
+
+func (t *httpTransport) post(ctx context.Context, msg *message) (*http.Response, error) {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode message: %w", err)
+	}
+	req, err := t.opts.newRequest(ctx, http.MethodPost, t.endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	t.mu.Lock()
+	if t.session != "" {
+		req.Header.Set(sessionHeader, t.session)
+	}
+	if t.protocolVersion != "" {
+		req.Header.Set(protocolVersionHeader, t.protocolVersion)
+	}
+	t.mu.Unlock()
+
+	resp, err := t.opts.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkStatus(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+// NewSSE connects to a server that uses the SSE transport, where endpoint is the URL of its
+// event stream. Requests are posted to the URL the server announces on the stream and the
+// responses arrive on the stream.
+func NewSSE(ctx context.Context, endpoint string, opts Options) (*Client, error) {
+	base, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %w", err)
+	}
+
+	// The stream lives until the client is closed, not just until ctx is done
+	streamCtx, cancel := context.WithCancel(context.Background())
+	req, err := opts.newRequest(streamCtx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := opts.httpClient().Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if err := checkStatus(resp); err != nil {
+		resp.Body.Close()
+		cancel()
+		return nil, err
+	}
+
+	pending := newPendingCalls()
+	endpoints := make(chan string, 1)
+	go readSSEStream(resp.Body, pending, endpoints)
+
+	postURL, err := waitForEndpoint(ctx, base, endpoints, pending)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return newClient(&streamTransport{
+		pending: pending,
+		send:    postMessage(opts, postURL),
+		stop:    stopStream(cancel),
+	}), nil
+}
+
+// readSSEStream reads the event stream of an SSE server until it ends, passing the message
+// endpoint the server announces to endpoints and delivering the responses to pending
+func readSSEStream(body io.ReadCloser, pending *pendingCalls, endpoints chan<- string) {
+	defer body.Close()
+	err := readEvents(body, func(event, data string) error {
+		switch event {
+		case "endpoint":
+			select {
+			case endpoints <- data:
+			default:
+			}
+		case "", "message":
+			if msg := decodeMessage([]byte(data)); msg != nil {
+				pending.deliver(msg)
+			}
+		default:
+		}
+		return nil
+	})
+	if err == nil {
+		err = errors.New("server closed the event stream")
+	}
+	pending.close(err)
+}
+
+// waitForEndpoint waits for the message endpoint the server announces and resolves it against
+// the URL of the event stream. Messages carry the same headers as the stream, credentials
+// included, so the endpoint must have the scheme and host of the stream.
+func waitForEndpoint(ctx context.Context, base *url.URL, endpoints <-chan string, pending *pendingCalls) (string, error) {
+	select {
+	case data := <-endpoints:
+		ref, err := url.Parse(data)
+		if err != nil {
+			return "", fmt.Errorf("invalid message endpoint '%s': %w", data, err)
+		}
+		resolved := base.ResolveReference(ref)
+		if resolved.Scheme != base.Scheme || !strings.EqualFold(resolved.Host, base.Host) {
+			return "", fmt.Errorf("message endpoint '%s' is not on %s://%s", data, base.Scheme, base.Host)
+		}
+		return resolved.String(), nil
+	case <-pending.done:
+		return "", fmt.Errorf("no message endpoint announced: %w", pending.err)
+	case <-ctx.Done():
+		return "", fmt.Errorf("no message endpoint announced: %w", ctx.Err())
+	}
+}
+
+// postMessage returns a function that posts messages to the message endpoint of an SSE server
+func postMessage(opts Options, postURL string) func(ctx context.Context, msg *message) error {
+	return func(ctx context.Context, msg *message) error {
+		body, err := json.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("failed to encode message: %w", err)
+		}
+		req, err := opts.newRequest(ctx, http.MethodPost, postURL, body)
+		if err != nil {
+			return err
+		}
+		resp, err := opts.httpClient().Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		return checkStatus(resp)
+	}
+}
+
+// stopStream returns a function that ends the event stream of an SSE server
+func stopStream(cancel context.CancelFunc) func() error {
+	return func() error {
+		cancel()
+		return nil
+	}
+}
@@ -0,0 +1,198 @@
+package mcpclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// message is a JSON-RPC 2.0 request, notification or response
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  any             `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// isResponse reports whether the message answers a request
+func (m *message) isResponse() bool {
+	return len(m.ID) > 0 && m.Method == ""
+}
+
+// Error is a JSON-RPC error returned by a server
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("server error %d: %s", e.Code, e.Message)
+}
+
+// pendingCalls matches the responses that arrive on a stream to the calls waiting for them
+type pendingCalls struct {
+	mu      sync.Mutex
+	waiting map[string]chan *message
+	err     error
+	done    chan struct{}
+}
+
+func newPendingCalls() *pendingCalls {
+	return &pendingCalls{
+		waiting: make(map[string]chan *message),
+		done:    make(chan struct{}),
+	}
+}
+
+// add registers a call that waits for the response with the given ID
+func (p *pendingCalls) add(id json.RawMessage) (chan *message, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return nil, p.err
+	}
+	ch := make(chan *message, 1)
+	p.waiting[string(id)] = ch
+	return ch, nil
+}
+
+func (p *pendingCalls) remove(id json.RawMessage) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.waiting, string(id))
+}
+
+// deliver hands a response to the call waiting for it. Notifications, requests from the
+// server and responses nobody waits for are dropped.
+func (p *pendingCalls) deliver(msg *message) {
+	if !msg.isResponse() {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if ch, ok := p.waiting[string(msg.ID)]; ok {
+		ch <- msg
+		delete(p.waiting, string(msg.ID))
+	}
+}
+
+// close fails all current and future calls with err once the stream has ended
+func (p *pendingCalls) close(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return
+	}
+	p.err = err
+	close(p.done)
+}
+
+// wait blocks until the response of a call arrives, the stream ends or ctx is done
+func (p *pendingCalls) wait(ctx context.Context, id json.RawMessage, ch chan *message) (*message, error) {
+	select {
+	case msg := <-ch:
+		return msg, nil
+	case <-p.done:
+		return nil, p.err
+	case <-ctx.Done():
+		p.remove(id)
+		return nil, ctx.Err()
+	}
+}
+
+// streamTransport sends messages one way and receives the responses asynchronously on a
+// stream, as the stdio and SSE transports do
+type streamTransport struct {
+	pending *pendingCalls
+	send    func(ctx context.Context, msg *message) error
+	stop    func() error
+}
+
+func (t *streamTransport) call(ctx context.Context, msg *message) (*message, error) {
+	ch, err := t.pending.add(msg.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.send(ctx, msg); err != nil {
+		t.pending.remove(msg.ID)
+		return nil, err
+	}
+	return t.pending.wait(ctx, msg.ID, ch)
+}
+
+func (t *streamTransport) notify(ctx context.Context, msg *message) error {
+	return t.send(ctx, msg)
+}
+
+func (t *streamTransport) close() error {
+	return t.stop()
+}
+
+// errStopEvents ends readEvents without an error
+var errStopEvents = errors.New("stop reading events")
+
+// readEvents reads a server-sent event stream and calls fn for every event until the stream
+// ends or fn returns an error. Returning errStopEvents stops reading without an error.
+func readEvents(r io.Reader, fn func(event, data string) error) error {
+	reader := bufio.NewReader(r)
+	var event string
+	var data []string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case line == "":
+			if len(data) > 0 {
+				if err := fn(event, strings.Join(data, "\n")); err != nil {
+					if errors.Is(err, errStopEvents) {
+						return nil
+					}
+					return err
+				}
+			}
+			event, data = "", nil
+		case strings.HasPrefix(line, ":"):
+			// Comment, used by servers as a keep-alive
+		default:
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "event":
+				event = value
+			case "data":
+				data = append(data, value)
+			default:
+			}
+		}
+	}
+}
+
+// decodeMessage decodes a JSON-RPC message, returning nil for anything else such as log
+// lines a server writes to stdout
+func decodeMessage(data []byte) *message {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		return nil
+	}
+	var msg message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil
+	}
+	return &msg
+}
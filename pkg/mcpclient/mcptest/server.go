@@ -0,0 +1,261 @@
+// Package mcptest provides a fake MCP server for tests. It answers initialize and the list
+// methods over stdio, SSE and streamable HTTP, with the tools, resources, resource templates
+// and prompts it is given in their JSON wire format.
+package mcptest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// HTTP paths of the fake server
+const (
+	// StreamableHTTPPath is the endpoint of the streamable HTTP transport
+	StreamableHTTPPath = "/mcp"
+	// SSEPath is the event stream of the SSE transport
+	SSEPath = "/sse"
+	// MessagePath is where clients of the SSE transport post their messages
+	MessagePath = "/message"
+
+	sessionID = "mcptest-session"
+)
+
+// Server is a fake MCP server. Set its fields before it serves its first request.
+type Server struct {
+	// Tools, Resources, ResourceTemplates and Prompts are returned by the list methods. The
+	// server only has the resources capability when Resources or ResourceTemplates aren't nil,
+	// and the prompts capability when Prompts isn't nil.
+	Tools             []map[string]any
+	Resources         []map[string]any
+	ResourceTemplates []map[string]any
+	Prompts           []map[string]any
+	// PageSize splits lists into pages of this many items; zero returns a single page
+	PageSize int
+	// Stream makes the streamable HTTP endpoint answer with event streams instead of JSON
+	Stream bool
+	// Errors maps methods to the error message they fail with
+	Errors map[string]string
+
+	mu       sync.Mutex
+	methods  []string
+	sessions map[string]chan []byte
+}
+
+// Methods returns the methods of the requests and notifications received so far, in order
+func (s *Server) Methods() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.methods...)
+}
+
+type request struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params struct {
+		Cursor string `json:"cursor"`
+	} `json:"params"`
+}
+
+// handle answers a JSON-RPC message, returning nil for notifications
+func (s *Server) handle(data []byte) []byte {
+	var req request
+	if err := json.Unmarshal(data, &req); err != nil {
+		return encode(nil, nil, &rpcError{Code: -32700, Message: err.Error()})
+	}
+	s.mu.Lock()
+	s.methods = append(s.methods, req.Method)
+	s.mu.Unlock()
+	if len(req.ID) == 0 {
+		return nil
+	}
+
+	if message, ok := s.Errors[req.Method]; ok {
+		return encode(req.ID, nil, &rpcError{Code: -32603, Message: message})
+	}
+	switch req.Method {
+	case "initialize":
+		capabilities := map[string]any{"tools": map[string]any{}}
+		if s.Resources != nil || s.ResourceTemplates != nil {
+			capabilities["resources"] = map[string]any{}
+		}
+		if s.Prompts != nil {
+			capabilities["prompts"] = map[string]any{}
+		}
+		return encode(req.ID, map[string]any{
+			"protocolVersion": "2025-06-18",
+			"capabilities":    capabilities,
+			"serverInfo":      map[string]string{"name": "mcptest", "version": "1.0.0"},
+		}, nil)
+	case "tools/list":
+		return s.page(req, "tools", s.Tools)
+	case "resources/list":
+		return s.page(req, "resources", s.Resources)
+	case "resources/templates/list":
+		return s.page(req, "resourceTemplates", s.ResourceTemplates)
+	case "prompts/list":
+		return s.page(req, "prompts", s.Prompts)
+	default:
+		return encode(req.ID, nil, &rpcError{Code: -32601, Message: "method not found: " + req.Method})
+	}
+}
+
+// page answers a list request with the page of items that starts at its cursor
+func (s *Server) page(req request, field string, items []map[string]any) []byte {
+	start := 0
+	if req.Params.Cursor != "" {
+		var err error
+		if start, err = strconv.Atoi(req.Params.Cursor); err != nil || start < 0 || start > len(items) {
+			return encode(req.ID, nil, &rpcError{Code: -32602, Message: "invalid cursor"})
+		}
+	}
+	end := len(items)
+	if s.PageSize > 0 && start+s.PageSize < end {
+		end = start + s.PageSize
+	}
+
+	result := map[string]any{field: append([]map[string]any{}, items[start:end]...)}
+	if end < len(items) {
+		result["nextCursor"] = strconv.Itoa(end)
+	}
+	return encode(req.ID, result, nil)
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func encode(id json.RawMessage, result any, rpcErr *rpcError) []byte {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	response := map[string]any{"jsonrpc": "2.0", "id": id}
+	if rpcErr != nil {
+		response["error"] = rpcErr
+	} else {
+		response["result"] = result
+	}
+	data, _ := json.Marshal(response)
+	return data
+}
+
+// ServeStdio answers newline-delimited messages read from r on w until r ends
+func (s *Server) ServeStdio(r io.Reader, w io.Writer) error {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			if response := s.handle(line); response != nil {
+				if _, err := w.Write(append(response, '\n')); err != nil {
+					return err
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// ServeHTTP serves the streamable HTTP transport at StreamableHTTPPath and the SSE transport
+// at SSEPath and MessagePath
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == StreamableHTTPPath && r.Method == http.MethodPost:
+		s.serveStreamable(w, r)
+	case r.URL.Path == StreamableHTTPPath && r.Method == http.MethodDelete:
+		w.WriteHeader(http.StatusOK)
+	case r.URL.Path == SSEPath && r.Method == http.MethodGet:
+		s.serveEvents(w, r)
+	case r.URL.Path == MessagePath && r.Method == http.MethodPost:
+		s.serveMessage(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) serveStreamable(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var req request
+	_ = json.Unmarshal(data, &req)
+	if req.Method == "initialize" {
+		w.Header().Set("Mcp-Session-Id", sessionID)
+	} else if r.Header.Get("Mcp-Session-Id") != sessionID {
+		http.Error(w, "missing session", http.StatusBadRequest)
+		return
+	}
+
+	response := s.handle(data)
+	switch {
+	case response == nil:
+		w.WriteHeader(http.StatusAccepted)
+	case s.Stream:
+		w.Header().Set("Content-Type", "text/event-stream")
+		// A notification ahead of the response, which clients must skip
+		fmt.Fprintf(w, "event: message\ndata: %s\n\n", `{"jsonrpc":"2.0","method":"notifications/message","params":{}}`)
+		fmt.Fprintf(w, "event: message\ndata: %s\n\n", response)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(response)
+	}
+}
+
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	responses := make(chan []byte, 16)
+	s.mu.Lock()
+	if s.sessions == nil {
+		s.sessions = make(map[string]chan []byte)
+	}
+	id := strconv.Itoa(len(s.sessions) + 1)
+	s.sessions[id] = responses
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	fmt.Fprintf(w, ": connected\n\nevent: endpoint\ndata: %s?session=%s\n\n", MessagePath, id)
+	flusher.Flush()
+	for {
+		select {
+		case response := <-responses:
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", response)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (s *Server) serveMessage(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	responses, ok := s.sessions[r.URL.Query().Get("session")]
+	s.mu.Unlock()
+	if !ok {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if response := s.handle(data); response != nil {
+		responses <- response
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
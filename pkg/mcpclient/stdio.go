@@ -0,0 +1,90 @@
+package mcpclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// stopTimeout is how long a spawned server gets to exit after its stdin is closed
+const stopTimeout = 5 * time.Second
+
+// NewStdio creates a client that talks to a server over newline-delimited JSON-RPC, reading
+// from r and writing to w. Closing the client closes w.
+func NewStdio(r io.Reader, w io.WriteCloser) *Client {
+	return newClient(newStdioTransport(r, w, w.Close))
+}
+
+// Spawn starts cmd and creates a client that talks to it over its stdin and stdout. Closing the
+// client closes stdin and waits for the process to exit, killing it if it doesn't.
+func Spawn(cmd *exec.Cmd) (*Client, error) {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stdout: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", cmd.Path, err)
+	}
+
+	stop := func() error {
+		_ = stdin.Close()
+		exited := make(chan error, 1)
+		go func() { exited <- cmd.Wait() }()
+		select {
+		case <-exited:
+			return nil
+		case <-time.After(stopTimeout):
+			_ = cmd.Process.Kill()
+			<-exited
+			return fmt.Errorf("%s did not exit after stdin was closed and was killed", cmd.Path)
+		}
+	}
+	return newClient(newStdioTransport(stdout, stdin, stop)), nil
+}
+
+func newStdioTransport(r io.Reader, w io.Writer, stop func() error) *streamTransport {
+	pending := newPendingCalls()
+	var mu sync.Mutex
+	send := func(_ context.Context, msg *message) error {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("failed to encode message: %w", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if _, err := w.Write(append(data, '\n')); err != nil {
+			return fmt.Errorf("failed to write message: %w", err)
+		}
+		return nil
+	}
+
+	go func() {
+		// Lines aren't limited in size, as tool lists with schemas can be large
+		reader := bufio.NewReader(r)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if msg := decodeMessage(line); msg != nil {
+				pending.deliver(msg)
+			}
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					err = errors.New("server closed its output")
+				}
+				pending.close(err)
+				return
+			}
+		}
+	}()
+
+	return &streamTransport{pending: pending, send: send, stop: stop}
+}
@@ -0,0 +1,129 @@
+package registry
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// Changelog renders a registry diff as a human-friendly markdown changelog
+type Changelog struct {
+	// Title is the release the changelog describes (e.g. a version or date)
+	Title string
+	// Diff is the semantic difference to the previous release
+	Diff *RegistryDiff
+	// Entries are the entries of the new release, used to describe new servers
+	Entries map[string]*types.RegistryEntry
+	// Previous are the entries of the previous release, used to describe removed servers
+	Previous map[string]*types.RegistryEntry
+}
+
+// NewChangelog compares two sets of entries and returns their changelog
+func NewChangelog(title string, previous, current map[string]*types.RegistryEntry) *Changelog {
+	return &Changelog{
+		Title:    title,
+		Diff:     DiffRegistries(previous, current),
+		Entries:  current,
+		Previous: previous,
+	}
+}
+
+// WriteMarkdown writes the changelog grouped into new servers, updated servers,
+// deprecations, removals and security-relevant changes
+func (c *Changelog) WriteMarkdown(w io.Writer) error {
+	ew := &errWriter{w: w}
+
+	title := c.Title
+	if title == "" {
+		title = "Unreleased"
+	}
+	ew.printf("# Registry changelog: %s\n", title)
+
+	if !c.Diff.HasChanges() {
+		ew.printf("\nNo changes since the previous release.\n")
+		return ew.err
+	}
+
+	ew.printf("\n%s.\n", c.Diff.Summary())
+
+	c.writeServerList(ew, "New servers", c.Diff.Added, c.Entries)
+	c.writeChanges(ew, "Updated servers", c.updates())
+	c.writeServerList(ew, "Deprecated servers", c.Diff.Deprecated, c.Entries)
+	c.writeServerList(ew, "Removed servers", c.Diff.Removed, c.Previous)
+	c.writeChanges(ew, "Security-relevant changes", c.Diff.SecurityChanges())
+
+	return ew.err
+}
+
+// updates returns the changed entries without the changes reported in other sections
+func (c *Changelog) updates() []EntryDiff {
+	var result []EntryDiff
+	for _, entry := range c.Diff.Changed {
+		var changes []Change
+		for _, change := range entry.Changes {
+			if change.Security || (change.Kind == ChangeStatus && change.New == types.StatusDeprecated) {
+				continue
+			}
+			changes = append(changes, change)
+		}
+		if len(changes) > 0 {
+			result = append(result, EntryDiff{Name: entry.Name, Changes: changes})
+		}
+	}
+	return result
+}
+
+// writeServerList writes a section listing servers with their description
+func (*Changelog) writeServerList(ew *errWriter, title string, names []string, entries map[string]*types.RegistryEntry) {
+	if len(names) == 0 {
+		return
+	}
+	ew.printf("\n## %s\n\n", title)
+	for _, name := range names {
+		ew.printf("- **%s**%s\n", name, describeEntry(entries[name]))
+	}
+}
+
+// writeChanges writes a section listing the changes of each server
+func (*Changelog) writeChanges(ew *errWriter, title string, entries []EntryDiff) {
+	if len(entries) == 0 {
+		return
+	}
+	ew.printf("\n## %s\n\n", title)
+	for _, entry := range entries {
+		ew.printf("- **%s**\n", entry.Name)
+		for _, change := range entry.Changes {
+			ew.printf("  - %s\n", capitalize(change.Message))
+		}
+	}
+}
+
+// describeEntry returns a short description of an entry for changelog lists
+func describeEntry(entry *types.RegistryEntry) string {
+	if entry == nil {
+		return ""
+	}
+
+	var source string
+	switch {
+	case entry.IsImage():
+		source = fmt.Sprintf(" (`%s`)", entry.Image)
+	case entry.IsRemote():
+		source = fmt.Sprintf(" (remote: %s)", entry.URL)
+	}
+
+	if description := entry.GetDescription(); description != "" {
+		return ": " + description + source
+	}
+	return source
+}
+
+// capitalize upper-cases the first letter of a message
+func capitalize(message string) string {
+	if message == "" {
+		return message
+	}
+	return strings.ToUpper(message[:1]) + message[1:]
+}
@@ -0,0 +1,73 @@
+package registry
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func TestChangelog_WriteMarkdown(t *testing.T) {
+	t.Parallel()
+
+	previous := map[string]*types.RegistryEntry{
+		"kept":    diffTestImageEntry("kept", "example/kept:1.0.0", []string{"api.example.com"}, "a"),
+		"old":     diffTestImageEntry("old", "example/old:1.0.0", nil, "a"),
+		"removed": diffTestImageEntry("removed", "example/removed:1.0.0", nil, "a"),
+	}
+
+	kept := diffTestImageEntry("kept", "example/kept:1.1.0", []string{"api.example.com", "new.example.com"}, "a", "b")
+	deprecated := diffTestImageEntry("old", "example/old:1.0.0", nil, "a")
+	deprecated.ImageMetadata.Status = types.StatusDeprecated
+	current := map[string]*types.RegistryEntry{
+		"kept":  kept,
+		"old":   deprecated,
+		"added": diffTestImageEntry("added", "example/added:1.0.0", nil, "a"),
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, NewChangelog("v2", previous, current).WriteMarkdown(&out))
+
+	assert.Equal(t, `# Registry changelog: v2
+
+1 added, 1 removed, 1 deprecated, 2 changed.
+
+## New servers
+
+- **added**: Test server (`+"`example/added:1.0.0`"+`)
+
+## Updated servers
+
+- **kept**
+  - Image tag bumped from 1.0.0 to 1.1.0
+  - Tool(s) added: b
+
+## Deprecated servers
+
+- **old**: Test server (`+"`example/old:1.0.0`"+`)
+
+## Removed servers
+
+- **removed**: Test server (`+"`example/removed:1.0.0`"+`)
+
+## Security-relevant changes
+
+- **kept**
+  - Permissions widened: new allowed host(s) new.example.com
+`, out.String())
+}
+
+func TestChangelog_NoChanges(t *testing.T) {
+	t.Parallel()
+
+	entries := map[string]*types.RegistryEntry{
+		"kept": diffTestImageEntry("kept", "example/kept:1.0.0", nil, "a"),
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, NewChangelog("", entries, entries).WriteMarkdown(&out))
+	assert.Equal(t, "# Registry changelog: Unreleased\n\nNo changes since the previous release.\n", out.String())
+}
@@ -0,0 +1,139 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SourceDateEpochEnv is the standard environment variable for reproducible build timestamps
+// (see https://reproducible-builds.org/specs/source-date-epoch/)
+const SourceDateEpochEnv = "SOURCE_DATE_EPOCH"
+
+// serverIDNamespace is the UUIDv5 namespace used to derive deterministic server IDs
+var serverIDNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://github.com/stacklok/toolhive-registry"))
+
+// BuildClock decides which timestamps and IDs are stamped into built registries.
+// In reproducible mode, two builds of the same commit produce byte-identical output:
+// timestamps come from SOURCE_DATE_EPOCH or the git history of the registry, and
+// server IDs are derived from the server name and version.
+type BuildClock struct {
+	loader       *Loader
+	reproducible bool
+	epoch        *time.Time
+	buildTime    time.Time
+	entryTimes   map[string]time.Time
+}
+
+// NewBuildClock creates a clock for the given loader.
+// Reproducible mode is enabled if requested or if SOURCE_DATE_EPOCH is set. A reproducible
+// clock fails without SOURCE_DATE_EPOCH or a git history to take its build time from,
+// such as when the registry comes from a tarball.
+func NewBuildClock(loader *Loader, reproducible bool) (*BuildClock, error) {
+	clock := &BuildClock{
+		loader:       loader,
+		reproducible: reproducible,
+		entryTimes:   make(map[string]time.Time),
+	}
+
+	if value := os.Getenv(SourceDateEpochEnv); value != "" {
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %w", SourceDateEpochEnv, value, err)
+		}
+		epoch := time.Unix(seconds, 0).UTC()
+		clock.epoch = &epoch
+		clock.reproducible = true
+	}
+
+	switch {
+	case !clock.reproducible:
+	case clock.epoch != nil:
+		clock.buildTime = *clock.epoch
+	default:
+		// Use the last commit touching the registry directory
+		commitTime, err := gitCommitTime(loader.registryPath)
+		if err != nil {
+			return nil, fmt.Errorf("no build time for a reproducible build (%w); set %s", err, SourceDateEpochEnv)
+		}
+		clock.buildTime = commitTime
+	}
+
+	return clock, nil
+}
+
+// Reproducible returns true if the clock produces deterministic output
+func (c *BuildClock) Reproducible() bool {
+	return c != nil && c.reproducible
+}
+
+// BuildTime returns the timestamp for the registry as a whole.
+// Outside reproducible mode this is the current time.
+func (c *BuildClock) BuildTime() time.Time {
+	if !c.Reproducible() {
+		return time.Now().UTC()
+	}
+	return c.buildTime
+}
+
+// EntryTime returns the timestamp for a single entry.
+// In reproducible mode this is SOURCE_DATE_EPOCH if set, and otherwise the last git commit
+// touching the entry's directory, falling back to BuildTime if the entry is not tracked by git.
+func (c *BuildClock) EntryTime(name string) time.Time {
+	if !c.Reproducible() {
+		return time.Now().UTC()
+	}
+	if c.epoch != nil {
+		return *c.epoch
+	}
+	if entryTime, ok := c.entryTimes[name]; ok {
+		return entryTime
+	}
+
+	entryTime := c.buildTime
+	if path := c.loader.GetEntryPath(name); path != "" {
+		if commitTime, err := gitCommitTime(filepath.Dir(path)); err == nil {
+			entryTime = commitTime
+		}
+	}
+
+	c.entryTimes[name] = entryTime
+	return entryTime
+}
+
+// ServerID returns the registry ID for a server.
+// In reproducible mode the ID is a UUIDv5 derived from the server name and version.
+func (c *BuildClock) ServerID(serverName, version string) string {
+	if !c.Reproducible() {
+		return uuid.NewString()
+	}
+	return uuid.NewSHA1(serverIDNamespace, []byte(serverName+"@"+version)).String()
+}
+
+// gitCommitTime returns the committer time of the last commit touching path
+func gitCommitTime(path string) (time.Time, error) {
+	cmd := exec.Command("git", "log", "-1", "--format=%ct", "--", filepath.Base(path)) // #nosec G204 - fixed command
+	cmd.Dir = filepath.Dir(path)
+	output, err := cmd.Output()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("git log failed for %s: %w", path, err)
+	}
+
+	value := strings.TrimSpace(string(output))
+	if value == "" {
+		return time.Time{}, fmt.Errorf("no commits found for %s", path)
+	}
+
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid commit time %q: %w", value, err)
+	}
+
+	return time.Unix(seconds, 0).UTC(), nil
+}
@@ -0,0 +1,96 @@
+package registry
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func TestBuildClock_SourceDateEpoch(t *testing.T) {
+	t.Setenv(SourceDateEpochEnv, "1700000000")
+
+	loader := NewLoader("")
+	loader.entries = map[string]*types.RegistryEntry{
+		"test-server": {
+			ImageMetadata: &toolhiveRegistry.ImageMetadata{
+				BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+					Name:        "test-server",
+					Description: "Test server",
+					Transport:   "stdio",
+					Tier:        types.TierCommunity,
+					Status:      types.StatusActive,
+					Tools:       []string{"test-tool"},
+				},
+				Image: "test/image:1.0.0",
+			},
+		},
+	}
+
+	build := func() []byte {
+		clock, err := NewBuildClock(loader, false)
+		require.NoError(t, err)
+		require.True(t, clock.Reproducible())
+
+		official := NewOfficialRegistry(loader)
+		official.SetClock(clock)
+		data, err := json.Marshal(official.build())
+		require.NoError(t, err)
+
+		builder := NewBuilder(loader)
+		builder.SetClock(clock)
+		registry, err := builder.Build()
+		require.NoError(t, err)
+		assert.Equal(t, "2023-11-14T22:13:20Z", registry.LastUpdated)
+
+		return data
+	}
+
+	first := build()
+	assert.Equal(t, string(first), string(build()))
+
+	var registry ToolHiveRegistryType
+	require.NoError(t, json.Unmarshal(first, &registry))
+	assert.Equal(t, "2023-11-14T22:13:20Z", registry.Meta.LastUpdated)
+	official := registry.Data.Servers[0].Meta.Official
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), official.PublishedAt)
+	id, err := uuid.Parse(official.ID)
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Version(5), id.Version())
+	assert.Equal(t, uuid.NewSHA1(serverIDNamespace, []byte("io.stacklok.toolhive/test-server@1.0.0")), id)
+
+	// SOURCE_DATE_EPOCH wins over the git history of tracked entries
+	tracked := NewLoader("../../registry")
+	require.NoError(t, tracked.LoadAll())
+	clock, err := NewBuildClock(tracked, false)
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), clock.EntryTime("fetch"))
+}
+
+func TestBuildClock_NonReproducible(t *testing.T) {
+	t.Parallel()
+
+	var clock *BuildClock
+	assert.False(t, clock.Reproducible())
+	assert.NotEqual(t, clock.ServerID("a", "1.0.0"), clock.ServerID("a", "1.0.0"))
+	assert.WithinDuration(t, time.Now(), clock.BuildTime(), time.Minute)
+}
+
+func TestBuildClock_NoBuildTime(t *testing.T) {
+	// Without SOURCE_DATE_EPOCH, the build time comes from git, which knows nothing of a temp dir
+	t.Setenv(SourceDateEpochEnv, "")
+
+	_, err := NewBuildClock(NewLoader(t.TempDir()), true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), SourceDateEpochEnv)
+
+	clock, err := NewBuildClock(NewLoader(t.TempDir()), false)
+	require.NoError(t, err)
+	assert.False(t, clock.Reproducible())
+}
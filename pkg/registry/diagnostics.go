@@ -0,0 +1,197 @@
+package registry
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severity describes how serious a diagnostic is
+type Severity string
+
+const (
+	// SeverityError marks a problem that makes the entry invalid
+	SeverityError Severity = "error"
+	// SeverityWarning marks a problem that should be fixed but does not block the build
+	SeverityWarning Severity = "warning"
+	// SeverityInfo marks an informational finding
+	SeverityInfo Severity = "info"
+)
+
+// Diagnostic describes a single problem found in a registry entry
+type Diagnostic struct {
+	// Entry is the name of the registry entry the problem belongs to
+	Entry string `json:"entry"`
+	// Path is the spec.yaml file the entry was loaded from
+	Path string `json:"path,omitempty"`
+	// Line is the 1-based line in Path the problem points at (0 if unknown)
+	Line int `json:"line,omitempty"`
+	// Column is the 1-based column in Path the problem points at (0 if unknown)
+	Column int `json:"column,omitempty"`
+	// Field is the dotted path of the offending field (e.g. "env_vars.0.name")
+	Field string `json:"field,omitempty"`
+	// Rule is the identifier of the check that produced the diagnostic
+	Rule string `json:"rule"`
+	// Severity is the severity of the diagnostic
+	Severity Severity `json:"severity"`
+	// Message is the human-readable description of the problem
+	Message string `json:"message"`
+}
+
+// String formats the diagnostic as "path:line:column: severity [rule] entry: message"
+func (d Diagnostic) String() string {
+	var location string
+	switch {
+	case d.Path != "" && d.Line > 0:
+		location = fmt.Sprintf("%s:%d:%d: ", d.Path, d.Line, d.Column)
+	case d.Path != "":
+		location = d.Path + ": "
+	}
+	return fmt.Sprintf("%s%s [%s] %s: %s", location, d.Severity, d.Rule, d.Entry, d.Message)
+}
+
+// Diagnostics is a list of diagnostics collected while loading or validating entries
+type Diagnostics []Diagnostic
+
+// HasErrors returns true if any diagnostic has error severity
+func (d Diagnostics) HasErrors() bool {
+	return d.Count(SeverityError) > 0
+}
+
+// Count returns the number of diagnostics with the given severity
+func (d Diagnostics) Count(severity Severity) int {
+	count := 0
+	for _, diag := range d {
+		if diag.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// Entries returns the sorted, de-duplicated names of the entries that have diagnostics
+func (d Diagnostics) Entries() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, diag := range d {
+		if !seen[diag.Entry] {
+			seen[diag.Entry] = true
+			names = append(names, diag.Entry)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Sort orders diagnostics by path, line, column and rule for stable output
+func (d Diagnostics) Sort() {
+	sort.SliceStable(d, func(i, j int) bool {
+		if d[i].Path != d[j].Path {
+			return d[i].Path < d[j].Path
+		}
+		if d[i].Entry != d[j].Entry {
+			return d[i].Entry < d[j].Entry
+		}
+		if d[i].Line != d[j].Line {
+			return d[i].Line < d[j].Line
+		}
+		if d[i].Column != d[j].Column {
+			return d[i].Column < d[j].Column
+		}
+		return d[i].Rule < d[j].Rule
+	})
+}
+
+// Err returns the diagnostics as an error, or nil if there are no error-severity diagnostics
+func (d Diagnostics) Err() error {
+	if !d.HasErrors() {
+		return nil
+	}
+	return &ValidationError{Diagnostics: d}
+}
+
+// ValidationError is returned when one or more entries fail validation.
+// It carries every diagnostic that was collected, not just the first one.
+type ValidationError struct {
+	Diagnostics Diagnostics
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	var errs []string
+	for _, diag := range e.Diagnostics {
+		if diag.Severity == SeverityError {
+			errs = append(errs, fmt.Sprintf("entry '%s': %s", diag.Entry, diag.Message))
+		}
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return fmt.Sprintf("%d validation errors: %s", len(errs), strings.Join(errs, "; "))
+}
+
+// yamlLinePattern extracts the line number from yaml.v3 parse errors ("yaml: line 3: ...")
+var yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// yamlErrorLine returns the line number reported in a yaml.v3 error, or 0 if none
+func yamlErrorLine(err error) int {
+	match := yamlLinePattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return 0
+	}
+	line, convErr := strconv.Atoi(match[1])
+	if convErr != nil {
+		return 0
+	}
+	return line
+}
+
+// locateField returns the position of a dotted field path (e.g. "env_vars.0.name") in a YAML document.
+// If the full path cannot be found, the position of the deepest matching ancestor is returned.
+func locateField(root *yaml.Node, field string) (line, column int) {
+	node := root
+	if node == nil {
+		return 0, 0
+	}
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	line, column = node.Line, node.Column
+
+	if field == "" {
+		return line, column
+	}
+
+	for _, part := range strings.Split(field, ".") {
+		switch node.Kind {
+		case yaml.MappingNode:
+			found := false
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == part {
+					line, column = node.Content[i].Line, node.Content[i].Column
+					node = node.Content[i+1]
+					found = true
+					break
+				}
+			}
+			if !found {
+				return line, column
+			}
+		case yaml.SequenceNode:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(node.Content) {
+				return line, column
+			}
+			node = node.Content[index]
+			line, column = node.Line, node.Column
+		default:
+			return line, column
+		}
+	}
+
+	return line, column
+}
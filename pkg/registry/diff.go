@@ -0,0 +1,581 @@
+package registry
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/stacklok/toolhive/pkg/permissions"
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+
+	"github.com/stacklok/toolhive-registry/pkg/imageref"
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// ChangeKind identifies which aspect of an entry changed
+type ChangeKind string
+
+const (
+	// ChangeType is a switch between image-based and remote server
+	ChangeType ChangeKind = "type"
+	// ChangeStatus is a status change such as a deprecation
+	ChangeStatus ChangeKind = "status"
+	// ChangeImage is a change of the container image or its tag
+	ChangeImage ChangeKind = "image"
+	// ChangeURL is a change of the remote server URL
+	ChangeURL ChangeKind = "url"
+	// ChangeTransport is a change of the transport
+	ChangeTransport ChangeKind = "transport"
+	// ChangeTools is a change of the tool list
+	ChangeTools ChangeKind = "tools"
+	// ChangeEnvVars is a change of the environment variables
+	ChangeEnvVars ChangeKind = "env_vars"
+	// ChangeHeaders is a change of the remote server headers
+	ChangeHeaders ChangeKind = "headers"
+	// ChangePermissions is a change of the permission profile
+	ChangePermissions ChangeKind = "permissions"
+	// ChangeOAuth is a change of the OAuth configuration
+	ChangeOAuth ChangeKind = "oauth"
+	// ChangeProvenance is a change of the provenance information
+	ChangeProvenance ChangeKind = "provenance"
+	// ChangeDescription is a change of the description
+	ChangeDescription ChangeKind = "description"
+	// ChangeTier is a change of the tier
+	ChangeTier ChangeKind = "tier"
+)
+
+// Change is a single semantic change to a registry entry
+type Change struct {
+	// Kind identifies the changed aspect
+	Kind ChangeKind `json:"kind"`
+	// Message is a human-readable description of the change
+	Message string `json:"message"`
+	// Old is the previous value, if the change is a single value
+	Old string `json:"old,omitempty"`
+	// New is the new value, if the change is a single value
+	New string `json:"new,omitempty"`
+	// Added lists items added to a list-valued field
+	Added []string `json:"added,omitempty"`
+	// Removed lists items removed from a list-valued field
+	Removed []string `json:"removed,omitempty"`
+	// Security is true if the change affects what the server can access or how it is trusted
+	Security bool `json:"security,omitempty"`
+}
+
+// EntryDiff lists the changes to a single entry present in both registries
+type EntryDiff struct {
+	// Name is the entry name
+	Name string `json:"name"`
+	// Changes lists the changes in a stable order
+	Changes []Change `json:"changes"`
+}
+
+// RegistryDiff is the semantic difference between two registries
+type RegistryDiff struct {
+	// Added lists entries only present in the new registry
+	Added []string `json:"added"`
+	// Removed lists entries only present in the old registry
+	Removed []string `json:"removed"`
+	// Deprecated lists entries that became deprecated
+	Deprecated []string `json:"deprecated"`
+	// Changed lists entries present in both registries with at least one change
+	Changed []EntryDiff `json:"changed"`
+}
+
+// HasChanges returns true if the registries differ
+func (d *RegistryDiff) HasChanges() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || len(d.Changed) > 0
+}
+
+// SecurityChanges returns the changed entries restricted to security-relevant changes
+func (d *RegistryDiff) SecurityChanges() []EntryDiff {
+	var result []EntryDiff
+	for _, entry := range d.Changed {
+		var changes []Change
+		for _, change := range entry.Changes {
+			if change.Security {
+				changes = append(changes, change)
+			}
+		}
+		if len(changes) > 0 {
+			result = append(result, EntryDiff{Name: entry.Name, Changes: changes})
+		}
+	}
+	return result
+}
+
+// DiffRegistries computes the semantic difference between two sets of entries
+func DiffRegistries(oldEntries, newEntries map[string]*types.RegistryEntry) *RegistryDiff {
+	diff := &RegistryDiff{
+		Added:      []string{},
+		Removed:    []string{},
+		Deprecated: []string{},
+		Changed:    []EntryDiff{},
+	}
+
+	for _, name := range sortedEntryNames(newEntries) {
+		oldEntry, ok := oldEntries[name]
+		if !ok {
+			diff.Added = append(diff.Added, name)
+			continue
+		}
+
+		changes := DiffEntry(oldEntry, newEntries[name])
+		if len(changes) == 0 {
+			continue
+		}
+		diff.Changed = append(diff.Changed, EntryDiff{Name: name, Changes: changes})
+		if oldEntry.GetStatus() != types.StatusDeprecated && newEntries[name].GetStatus() == types.StatusDeprecated {
+			diff.Deprecated = append(diff.Deprecated, name)
+		}
+	}
+
+	for _, name := range sortedEntryNames(oldEntries) {
+		if _, ok := newEntries[name]; !ok {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+
+	return diff
+}
+
+// DiffEntry returns the semantic changes between two versions of the same entry
+func DiffEntry(oldEntry, newEntry *types.RegistryEntry) []Change {
+	var changes []Change
+
+	if oldEntry.IsImage() != newEntry.IsImage() {
+		changes = append(changes, Change{
+			Kind:     ChangeType,
+			Message:  fmt.Sprintf("server type changed from %s to %s", entryType(oldEntry), entryType(newEntry)),
+			Old:      entryType(oldEntry),
+			New:      entryType(newEntry),
+			Security: true,
+		})
+	}
+
+	changes = append(changes, diffStatus(oldEntry.GetStatus(), newEntry.GetStatus())...)
+
+	if oldEntry.IsImage() && newEntry.IsImage() {
+		changes = append(changes, diffImage(oldEntry.Image, newEntry.Image)...)
+		changes = append(changes, diffPermissions(oldEntry.Permissions, newEntry.Permissions)...)
+		changes = append(changes, diffProvenance(oldEntry.Provenance, newEntry.Provenance)...)
+	}
+
+	if oldEntry.IsRemote() && newEntry.IsRemote() {
+		changes = append(changes, diffValue(ChangeURL, "url", oldEntry.URL, newEntry.URL, true)...)
+		changes = append(changes, diffHeaders(oldEntry.Headers, newEntry.Headers)...)
+		changes = append(changes, diffOAuth(oldEntry.OAuthConfig, newEntry.OAuthConfig)...)
+	}
+
+	changes = append(changes, diffValue(ChangeTransport, "transport", oldEntry.GetTransport(), newEntry.GetTransport(), false)...)
+	changes = append(changes, diffList(ChangeTools, "tool", oldEntry.GetTools(), newEntry.GetTools())...)
+	changes = append(changes, diffEnvVars(entryEnvVars(oldEntry), entryEnvVars(newEntry))...)
+	changes = append(changes, diffValue(ChangeTier, "tier", oldEntry.GetTier(), newEntry.GetTier(), false)...)
+
+	if oldEntry.GetDescription() != newEntry.GetDescription() {
+		changes = append(changes, Change{
+			Kind:    ChangeDescription,
+			Message: "description updated",
+			Old:     oldEntry.GetDescription(),
+			New:     newEntry.GetDescription(),
+		})
+	}
+
+	return changes
+}
+
+// entryType returns "image" or "remote" for an entry
+func entryType(entry *types.RegistryEntry) string {
+	if entry.IsRemote() {
+		return "remote"
+	}
+	return "image"
+}
+
+// diffStatus reports deprecations and reactivations
+func diffStatus(oldStatus, newStatus string) []Change {
+	if oldStatus == "" {
+		oldStatus = types.StatusActive
+	}
+	if newStatus == "" {
+		newStatus = types.StatusActive
+	}
+	if oldStatus == newStatus {
+		return nil
+	}
+
+	message := fmt.Sprintf("status changed from %s to %s", oldStatus, newStatus)
+	if newStatus == types.StatusDeprecated {
+		message = "server deprecated"
+	}
+	return []Change{{Kind: ChangeStatus, Message: message, Old: oldStatus, New: newStatus}}
+}
+
+// diffImage distinguishes tag bumps of the same repository from image replacements
+func diffImage(oldImage, newImage string) []Change {
+	if oldImage == newImage {
+		return nil
+	}
+
+	oldRepository, oldTag := splitImageTag(oldImage)
+	newRepository, newTag := splitImageTag(newImage)
+	if oldRepository == newRepository {
+		return []Change{{
+			Kind:    ChangeImage,
+			Message: fmt.Sprintf("image tag bumped from %s to %s", oldTag, newTag),
+			Old:     oldImage,
+			New:     newImage,
+		}}
+	}
+
+	return []Change{{
+		Kind:     ChangeImage,
+		Message:  fmt.Sprintf("image changed from %s to %s", oldImage, newImage),
+		Old:      oldImage,
+		New:      newImage,
+		Security: true,
+	}}
+}
+
+// splitImageTag splits an image reference into its normalized repository and its tag and/or digest
+func splitImageTag(image string) (repository, tag string) {
+	ref, err := imageref.Parse(image)
+	if err != nil {
+		return image, ""
+	}
+	return ref.Name(), ref.TagOrDigest()
+}
+
+// diffValue reports a change of a single-valued field
+func diffValue(kind ChangeKind, field, oldValue, newValue string, security bool) []Change {
+	if oldValue == newValue {
+		return nil
+	}
+	return []Change{{
+		Kind:     kind,
+		Message:  fmt.Sprintf("%s changed from %s to %s", field, displayValue(oldValue), displayValue(newValue)),
+		Old:      oldValue,
+		New:      newValue,
+		Security: security,
+	}}
+}
+
+// displayValue renders empty values readably in change messages
+func displayValue(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
+
+// diffList reports items added to and removed from a list-valued field
+func diffList(kind ChangeKind, item string, oldItems, newItems []string) []Change {
+	added, removed := diffSets(oldItems, newItems)
+	var changes []Change
+	if len(added) > 0 {
+		changes = append(changes, Change{
+			Kind:    kind,
+			Message: fmt.Sprintf("%s(s) added: %s", item, strings.Join(added, ", ")),
+			Added:   added,
+		})
+	}
+	if len(removed) > 0 {
+		changes = append(changes, Change{
+			Kind:    kind,
+			Message: fmt.Sprintf("%s(s) removed: %s", item, strings.Join(removed, ", ")),
+			Removed: removed,
+		})
+	}
+	return changes
+}
+
+// diffSets returns the sorted items only present in newItems and only present in oldItems
+func diffSets(oldItems, newItems []string) (added, removed []string) {
+	oldSet := make(map[string]bool, len(oldItems))
+	for _, item := range oldItems {
+		oldSet[item] = true
+	}
+	newSet := make(map[string]bool, len(newItems))
+	for _, item := range newItems {
+		newSet[item] = true
+		if !oldSet[item] {
+			added = append(added, item)
+		}
+	}
+	for _, item := range oldItems {
+		if !newSet[item] {
+			removed = append(removed, item)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// diffEnvVars reports added, removed and newly required env vars and changes to their secret flag
+func diffEnvVars(oldVars, newVars []*toolhiveRegistry.EnvVar) []Change {
+	oldByName := make(map[string]*toolhiveRegistry.EnvVar)
+	var oldNames, newNames []string
+	for _, envVar := range oldVars {
+		if envVar != nil {
+			oldByName[envVar.Name] = envVar
+			oldNames = append(oldNames, envVar.Name)
+		}
+	}
+
+	var changes []Change
+	for _, envVar := range newVars {
+		if envVar == nil {
+			continue
+		}
+		newNames = append(newNames, envVar.Name)
+		old, ok := oldByName[envVar.Name]
+		if !ok {
+			continue
+		}
+		changes = append(changes, diffVariable("env var", ChangeEnvVars, envVar.Name,
+			old.Required, envVar.Required, old.Secret, envVar.Secret)...)
+	}
+
+	return append(diffVariables("env var", ChangeEnvVars, oldNames, newNames, requiredEnvVars(newVars)), changes...)
+}
+
+// diffHeaders reports added, removed and newly required headers and changes to their secret flag
+func diffHeaders(oldHeaders, newHeaders []*toolhiveRegistry.Header) []Change {
+	oldByName := make(map[string]*toolhiveRegistry.Header)
+	var oldNames, newNames []string
+	for _, header := range oldHeaders {
+		if header != nil {
+			oldByName[header.Name] = header
+			oldNames = append(oldNames, header.Name)
+		}
+	}
+
+	required := make(map[string]bool)
+	var changes []Change
+	for _, header := range newHeaders {
+		if header == nil {
+			continue
+		}
+		newNames = append(newNames, header.Name)
+		required[header.Name] = header.Required
+		old, ok := oldByName[header.Name]
+		if !ok {
+			continue
+		}
+		changes = append(changes, diffVariable("header", ChangeHeaders, header.Name,
+			old.Required, header.Required, old.Secret, header.Secret)...)
+	}
+
+	return append(diffVariables("header", ChangeHeaders, oldNames, newNames, required), changes...)
+}
+
+// requiredEnvVars maps env var names to their required flag
+func requiredEnvVars(envVars []*toolhiveRegistry.EnvVar) map[string]bool {
+	required := make(map[string]bool)
+	for _, envVar := range envVars {
+		if envVar != nil {
+			required[envVar.Name] = envVar.Required
+		}
+	}
+	return required
+}
+
+// diffVariables reports added and removed env vars or headers, noting which new ones are required
+func diffVariables(item string, kind ChangeKind, oldNames, newNames []string, required map[string]bool) []Change {
+	added, removed := diffSets(oldNames, newNames)
+	var changes []Change
+	for _, name := range added {
+		message := fmt.Sprintf("optional %s %s added", item, name)
+		if required[name] {
+			message = fmt.Sprintf("required %s %s added", item, name)
+		}
+		changes = append(changes, Change{Kind: kind, Message: message, Added: []string{name}})
+	}
+	if len(removed) > 0 {
+		changes = append(changes, Change{
+			Kind:    kind,
+			Message: fmt.Sprintf("%s(s) removed: %s", item, strings.Join(removed, ", ")),
+			Removed: removed,
+		})
+	}
+	return changes
+}
+
+// diffVariable reports required and secret flag changes of a single env var or header
+func diffVariable(item string, kind ChangeKind, name string, oldRequired, newRequired, oldSecret, newSecret bool) []Change {
+	var changes []Change
+	if !oldRequired && newRequired {
+		changes = append(changes, Change{Kind: kind, Message: fmt.Sprintf("%s %s is now required", item, name)})
+	}
+	if oldRequired && !newRequired {
+		changes = append(changes, Change{Kind: kind, Message: fmt.Sprintf("%s %s is no longer required", item, name)})
+	}
+	if oldSecret != newSecret {
+		message := fmt.Sprintf("%s %s is now marked secret", item, name)
+		if !newSecret {
+			message = fmt.Sprintf("%s %s is no longer marked secret", item, name)
+		}
+		changes = append(changes, Change{Kind: kind, Message: message, Security: true})
+	}
+	return changes
+}
+
+// diffPermissions reports widening and narrowing of the permission profile
+func diffPermissions(oldProfile, newProfile *permissions.Profile) []Change {
+	if oldProfile == nil {
+		oldProfile = &permissions.Profile{}
+	}
+	if newProfile == nil {
+		newProfile = &permissions.Profile{}
+	}
+
+	oldOutbound := outboundPermissions(oldProfile)
+	newOutbound := outboundPermissions(newProfile)
+
+	return slices.Concat(
+		diffPermissionList("allowed host", oldOutbound.AllowHost, newOutbound.AllowHost),
+		diffPermissionList("allowed port", portStrings(oldOutbound.AllowPort), portStrings(newOutbound.AllowPort)),
+		diffPermissionList("read mount", mountStrings(oldProfile.Read), mountStrings(newProfile.Read)),
+		diffPermissionList("write mount", mountStrings(oldProfile.Write), mountStrings(newProfile.Write)),
+		diffPermissionFlag("insecure_allow_all", oldOutbound.InsecureAllowAll, newOutbound.InsecureAllowAll),
+		diffPermissionFlag("privileged", oldProfile.Privileged, newProfile.Privileged),
+	)
+}
+
+// outboundPermissions returns the outbound network permissions of a profile, never nil
+func outboundPermissions(profile *permissions.Profile) *permissions.OutboundNetworkPermissions {
+	if profile.Network == nil || profile.Network.Outbound == nil {
+		return &permissions.OutboundNetworkPermissions{}
+	}
+	return profile.Network.Outbound
+}
+
+// diffPermissionList reports added (widening) and removed (narrowing) permission items
+func diffPermissionList(item string, oldItems, newItems []string) []Change {
+	added, removed := diffSets(oldItems, newItems)
+	var changes []Change
+	if len(added) > 0 {
+		changes = append(changes, Change{
+			Kind:     ChangePermissions,
+			Message:  fmt.Sprintf("permissions widened: new %s(s) %s", item, strings.Join(added, ", ")),
+			Added:    added,
+			Security: true,
+		})
+	}
+	if len(removed) > 0 {
+		changes = append(changes, Change{
+			Kind:     ChangePermissions,
+			Message:  fmt.Sprintf("permissions narrowed: removed %s(s) %s", item, strings.Join(removed, ", ")),
+			Removed:  removed,
+			Security: true,
+		})
+	}
+	return changes
+}
+
+// diffPermissionFlag reports a boolean permission being turned on or off
+func diffPermissionFlag(flag string, oldValue, newValue bool) []Change {
+	if oldValue == newValue {
+		return nil
+	}
+	message := fmt.Sprintf("permissions widened: %s enabled", flag)
+	if !newValue {
+		message = fmt.Sprintf("permissions narrowed: %s disabled", flag)
+	}
+	return []Change{{
+		Kind:     ChangePermissions,
+		Message:  message,
+		Old:      strconv.FormatBool(oldValue),
+		New:      strconv.FormatBool(newValue),
+		Security: true,
+	}}
+}
+
+// portStrings renders ports as strings for set comparison
+func portStrings(ports []int) []string {
+	result := make([]string, 0, len(ports))
+	for _, port := range ports {
+		result = append(result, strconv.Itoa(port))
+	}
+	return result
+}
+
+// mountStrings renders mount declarations as strings for set comparison
+func mountStrings(mounts []permissions.MountDeclaration) []string {
+	result := make([]string, 0, len(mounts))
+	for _, mount := range mounts {
+		result = append(result, string(mount))
+	}
+	return result
+}
+
+// diffOAuth reports OAuth configuration being added, removed or changed
+func diffOAuth(oldConfig, newConfig *toolhiveRegistry.OAuthConfig) []Change {
+	switch {
+	case oldConfig == nil && newConfig == nil:
+		return nil
+	case oldConfig == nil:
+		return []Change{{Kind: ChangeOAuth, Message: "OAuth authentication added", Security: true}}
+	case newConfig == nil:
+		return []Change{{Kind: ChangeOAuth, Message: "OAuth authentication removed", Security: true}}
+	}
+
+	var changes []Change
+	changes = append(changes, diffValue(ChangeOAuth, "OAuth issuer", oldConfig.Issuer, newConfig.Issuer, true)...)
+	changes = append(changes, diffValue(ChangeOAuth, "OAuth authorize URL", oldConfig.AuthorizeURL, newConfig.AuthorizeURL, true)...)
+	changes = append(changes, diffValue(ChangeOAuth, "OAuth token URL", oldConfig.TokenURL, newConfig.TokenURL, true)...)
+	changes = append(changes, diffValue(ChangeOAuth, "OAuth client ID", oldConfig.ClientID, newConfig.ClientID, true)...)
+
+	scopes := diffList(ChangeOAuth, "OAuth scope", oldConfig.Scopes, newConfig.Scopes)
+	for i := range scopes {
+		scopes[i].Security = true
+	}
+	changes = append(changes, scopes...)
+
+	if oldConfig.UsePKCE != newConfig.UsePKCE {
+		changes = append(changes, Change{
+			Kind:     ChangeOAuth,
+			Message:  fmt.Sprintf("OAuth PKCE changed from %t to %t", oldConfig.UsePKCE, newConfig.UsePKCE),
+			Old:      strconv.FormatBool(oldConfig.UsePKCE),
+			New:      strconv.FormatBool(newConfig.UsePKCE),
+			Security: true,
+		})
+	}
+	return changes
+}
+
+// diffProvenance reports provenance information being added, removed or changed
+func diffProvenance(oldProvenance, newProvenance *toolhiveRegistry.Provenance) []Change {
+	switch {
+	case reflect.DeepEqual(oldProvenance, newProvenance):
+		return nil
+	case oldProvenance == nil:
+		return []Change{{Kind: ChangeProvenance, Message: "provenance information added", Security: true}}
+	case newProvenance == nil:
+		return []Change{{Kind: ChangeProvenance, Message: "provenance information removed", Security: true}}
+	}
+
+	var changes []Change
+	changes = append(changes, diffValue(ChangeProvenance, "provenance signer identity",
+		oldProvenance.SignerIdentity, newProvenance.SignerIdentity, true)...)
+	changes = append(changes, diffValue(ChangeProvenance, "provenance repository",
+		oldProvenance.RepositoryURI, newProvenance.RepositoryURI, true)...)
+	if len(changes) == 0 {
+		changes = append(changes, Change{Kind: ChangeProvenance, Message: "provenance information updated", Security: true})
+	}
+	return changes
+}
+
+// sortedEntryNames returns the keys of an entry map in sorted order
+func sortedEntryNames(entries map[string]*types.RegistryEntry) []string {
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
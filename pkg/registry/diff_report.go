@@ -0,0 +1,127 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ReportFormatMarkdown is the markdown report format, suited for pull request comments
+const ReportFormatMarkdown = "markdown"
+
+// WriteDiff writes the diff in the requested format (text, markdown or json)
+func (d *RegistryDiff) WriteDiff(w io.Writer, format string) error {
+	switch format {
+	case ReportFormatText, "":
+		return d.WriteText(w)
+	case ReportFormatMarkdown:
+		return d.WriteMarkdown(w)
+	case ReportFormatJSON:
+		return d.WriteJSON(w)
+	default:
+		return fmt.Errorf("unsupported diff format: %s (supported: %s, %s, %s)",
+			format, ReportFormatText, ReportFormatMarkdown, ReportFormatJSON)
+	}
+}
+
+// Summary returns a one-line summary of the diff
+func (d *RegistryDiff) Summary() string {
+	if !d.HasChanges() {
+		return "No changes"
+	}
+	return fmt.Sprintf("%d added, %d removed, %d deprecated, %d changed",
+		len(d.Added), len(d.Removed), len(d.Deprecated), len(d.Changed))
+}
+
+// WriteText writes a human-readable diff
+func (d *RegistryDiff) WriteText(w io.Writer) error {
+	ew := &errWriter{w: w}
+	ew.printf("Registry diff: %s\n", d.Summary())
+
+	if len(d.Added) > 0 {
+		ew.printf("\nAdded:\n")
+		for _, name := range d.Added {
+			ew.printf("  + %s\n", name)
+		}
+	}
+	if len(d.Removed) > 0 {
+		ew.printf("\nRemoved:\n")
+		for _, name := range d.Removed {
+			ew.printf("  - %s\n", name)
+		}
+	}
+	if len(d.Changed) > 0 {
+		ew.printf("\nChanged:\n")
+		for _, entry := range d.Changed {
+			ew.printf("  %s\n", entry.Name)
+			for _, change := range entry.Changes {
+				marker := "~"
+				if change.Security {
+					marker = "!"
+				}
+				ew.printf("    %s %s\n", marker, change.Message)
+			}
+		}
+	}
+
+	return ew.err
+}
+
+// WriteMarkdown writes the diff as markdown
+func (d *RegistryDiff) WriteMarkdown(w io.Writer) error {
+	ew := &errWriter{w: w}
+	ew.printf("## Registry changes\n\n%s\n", d.Summary())
+
+	writeNames := func(title string, names []string) {
+		if len(names) == 0 {
+			return
+		}
+		ew.printf("\n### %s\n\n", title)
+		for _, name := range names {
+			ew.printf("- `%s`\n", name)
+		}
+	}
+	writeNames("Added servers", d.Added)
+	writeNames("Removed servers", d.Removed)
+	writeNames("Deprecated servers", d.Deprecated)
+
+	if len(d.Changed) > 0 {
+		ew.printf("\n### Changed servers\n")
+		for _, entry := range d.Changed {
+			ew.printf("\n#### `%s`\n\n", entry.Name)
+			for _, change := range entry.Changes {
+				if change.Security {
+					ew.printf("- **security:** %s\n", change.Message)
+				} else {
+					ew.printf("- %s\n", change.Message)
+				}
+			}
+		}
+	}
+
+	return ew.err
+}
+
+// WriteJSON writes the diff as indented JSON
+func (d *RegistryDiff) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(d); err != nil {
+		return fmt.Errorf("failed to encode diff: %w", err)
+	}
+	return nil
+}
+
+// errWriter remembers the first write error so callers can check it once
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+// printf formats to the underlying writer unless an earlier write failed
+func (ew *errWriter) printf(format string, args ...interface{}) {
+	if ew.err != nil {
+		return
+	}
+	_, ew.err = fmt.Fprintf(ew.w, format, args...)
+}
@@ -0,0 +1,134 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stacklok/toolhive/pkg/permissions"
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func diffTestImageEntry(name, image string, hosts []string, tools ...string) *types.RegistryEntry {
+	return &types.RegistryEntry{
+		ImageMetadata: &toolhiveRegistry.ImageMetadata{
+			BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+				Name:        name,
+				Description: "Test server",
+				Transport:   "stdio",
+				Tier:        types.TierCommunity,
+				Status:      types.StatusActive,
+				Tools:       tools,
+			},
+			Image: image,
+			EnvVars: []*toolhiveRegistry.EnvVar{
+				{Name: "API_TOKEN", Description: "Token", Secret: true},
+			},
+			Permissions: &permissions.Profile{
+				Network: &permissions.NetworkPermissions{
+					Outbound: &permissions.OutboundNetworkPermissions{AllowHost: hosts, AllowPort: []int{443}},
+				},
+			},
+		},
+	}
+}
+
+func TestDiffRegistries(t *testing.T) {
+	t.Parallel()
+
+	oldEntries := map[string]*types.RegistryEntry{
+		"kept":    diffTestImageEntry("kept", "ghcr.io/example/kept:1.0.0", []string{"api.example.com"}, "a", "b"),
+		"removed": diffTestImageEntry("removed", "example/removed:1.0.0", nil, "a"),
+		"old":     diffTestImageEntry("old", "example/old:1.0.0", nil, "a"),
+	}
+
+	kept := diffTestImageEntry("kept", "ghcr.io/example/kept:1.1.0", []string{"api.example.com", "evil.example.com"}, "b", "c")
+	kept.ImageMetadata.EnvVars[0].Required = true
+	kept.Permissions.Network.Outbound.AllowPort = []int{443, 8443}
+	deprecated := diffTestImageEntry("old", "example/old:1.0.0", nil, "a")
+	deprecated.ImageMetadata.Status = types.StatusDeprecated
+
+	newEntries := map[string]*types.RegistryEntry{
+		"kept":  kept,
+		"old":   deprecated,
+		"added": diffTestImageEntry("added", "example/added:1.0.0", nil, "a"),
+	}
+
+	diff := DiffRegistries(oldEntries, newEntries)
+
+	assert.Equal(t, []string{"added"}, diff.Added)
+	assert.Equal(t, []string{"removed"}, diff.Removed)
+	assert.Equal(t, []string{"old"}, diff.Deprecated)
+	require.Len(t, diff.Changed, 2)
+	assert.Equal(t, "kept", diff.Changed[0].Name)
+
+	var messages []string
+	for _, change := range diff.Changed[0].Changes {
+		messages = append(messages, change.Message)
+	}
+	assert.Equal(t, []string{
+		"image tag bumped from 1.0.0 to 1.1.0",
+		"permissions widened: new allowed host(s) evil.example.com",
+		"permissions widened: new allowed port(s) 8443",
+		"tool(s) added: c",
+		"tool(s) removed: a",
+		"env var API_TOKEN is now required",
+	}, messages)
+
+	security := diff.SecurityChanges()
+	require.Len(t, security, 1)
+	assert.Len(t, security[0].Changes, 2)
+}
+
+func TestDiffEntry_RemoteOAuthAndHeaders(t *testing.T) {
+	t.Parallel()
+
+	remote := func(scopes []string, required bool) *types.RegistryEntry {
+		return &types.RegistryEntry{
+			RemoteServerMetadata: &toolhiveRegistry.RemoteServerMetadata{
+				BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+					Name:        "remote",
+					Description: "Remote server",
+					Transport:   "streamable-http",
+					Tools:       []string{"a"},
+				},
+				URL:         "https://mcp.example.com",
+				Headers:     []*toolhiveRegistry.Header{{Name: "X-API-Key", Required: required, Secret: true}},
+				OAuthConfig: &toolhiveRegistry.OAuthConfig{Issuer: "https://auth.example.com", Scopes: scopes},
+			},
+		}
+	}
+
+	changes := DiffEntry(remote([]string{"read"}, false), remote([]string{"read", "write"}, true))
+	require.Len(t, changes, 2)
+	assert.Equal(t, "header X-API-Key is now required", changes[0].Message)
+	assert.Equal(t, ChangeOAuth, changes[1].Kind)
+	assert.Equal(t, []string{"write"}, changes[1].Added)
+	assert.True(t, changes[1].Security)
+}
+
+func TestParseRegistryJSON_OfficialFormatMatchesSource(t *testing.T) {
+	t.Parallel()
+
+	entry := diffTestImageEntry("test-server", "ghcr.io/example/server:1.0.0", []string{"api.example.com"}, "a")
+	loader := NewLoader("")
+	loader.entries = map[string]*types.RegistryEntry{"test-server": entry}
+
+	data, err := json.Marshal(NewOfficialRegistry(loader).build())
+	require.NoError(t, err)
+
+	entries, err := ParseRegistryJSON(data)
+	require.NoError(t, err)
+	require.Contains(t, entries, "test-server")
+
+	diff := DiffRegistries(loader.entries, entries)
+	assert.False(t, diff.HasChanges(), "unexpected changes: %+v", diff.Changed)
+
+	var out bytes.Buffer
+	require.NoError(t, diff.WriteDiff(&out, ReportFormatMarkdown))
+	assert.Contains(t, out.String(), "No changes")
+}
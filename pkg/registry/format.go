@@ -0,0 +1,347 @@
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// specLayout is the canonical layout of a spec.yaml mapping
+type specLayout struct {
+	// order is the canonical key order; other keys follow in their original order
+	order []string
+	// fields holds the layout of nested mappings and of the items of lists of mappings
+	fields map[string]*specLayout
+	// sorted lists the fields that are lists of scalars whose order is not meaningful
+	sorted []string
+	// dropEmpty removes empty lists and false booleans, which are the same as unset in a spec.yaml
+	dropEmpty bool
+}
+
+var (
+	variableLayout = &specLayout{order: []string{"name", "description", "required", "default", "secret", "choices"}}
+
+	permissionsLayout = &specLayout{
+		order:     []string{"name", "read", "write", "network", "privileged"},
+		sorted:    []string{"read", "write"},
+		dropEmpty: true,
+		fields: map[string]*specLayout{
+			"network": {
+				order:     []string{"outbound"},
+				dropEmpty: true,
+				fields: map[string]*specLayout{
+					"outbound": {
+						order:     []string{"insecure_allow_all", "allow_host", "allow_port"},
+						sorted:    []string{"allow_host", "allow_port"},
+						dropEmpty: true,
+					},
+				},
+			},
+		},
+	}
+
+	// specFileLayout follows the spec layout documented in docs/adding-entries-llm.md: what the
+	// server is and how to reach it, who makes it, its classification and tools, then how to
+	// run it, with generated fields and the registry's extended fields last
+	specFileLayout = &specLayout{
+		order: []string{
+			"name", "image", "url", "description", "transport", "target_port", "repository_url", "homepage", "license",
+			"author", "tier", "status", "tags", "tools", "env_vars", "headers", "args", "permissions", "oauth",
+			"oauth_config", "docker_tags", "provenance", "metadata", "custom_metadata", "version", "examples",
+			"tool_definitions", "resources", "prompts", "resource_templates",
+		},
+		sorted: []string{"tools", "tags"},
+		fields: map[string]*specLayout{
+			"metadata":    {order: []string{"stars", "pulls", "last_updated"}},
+			"permissions": permissionsLayout,
+			"headers":     variableLayout,
+			"env_vars":    variableLayout,
+			"provenance": {order: []string{
+				"sigstore_url", "repository_uri", "repository_ref", "signer_identity",
+				"runner_environment", "cert_issuer", "attestation",
+			}},
+			"oauth": {order: []string{
+				"issuer", "authorize_url", "token_url", "client_id", "scopes", "use_pkce", "oauth_params", "callback_port",
+			}},
+			"examples":         {order: []string{"name", "description", "sample"}},
+			"tool_definitions": {order: []string{"name", "description", "input_schema", "annotations", "side_effect"}},
+			"resources":        {order: []string{"uri", "name", "description", "mime_type"}},
+			"prompts": {
+				order:  []string{"name", "description", "arguments"},
+				fields: map[string]*specLayout{"arguments": {order: []string{"name", "description", "required"}}},
+			},
+			"resource_templates": {order: []string{"uri_template", "name", "description", "mime_type"}},
+		},
+	}
+)
+
+// FormatSpec rewrites a spec.yaml in the canonical format: keys in the canonical order, tools,
+// tags and permission lists sorted, empty permission fields removed, block style with 2-space
+// indentation and quotes only where YAML needs them. Comments are kept with the keys they
+// belong to. A comment of several lines above the first key is the file's header and stays at
+// the top, as do the comments below the last key.
+func FormatSpec(data []byte) ([]byte, error) {
+	return formatSpec(data, true)
+}
+
+// FormatOverlay rewrites a version overlay like FormatSpec, except that empty permission
+// fields are kept because in an overlay they override the base spec
+func FormatOverlay(data []byte) ([]byte, error) {
+	return formatSpec(data, false)
+}
+
+// formatSpec formats a spec document, removing empty permission fields if prune is set
+func formatSpec(data []byte, prune bool) ([]byte, error) {
+	doc, err := parseSpecDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Header and footer comments belong to the file, not to the first and last keys
+	root := doc.Content[0]
+	var header, footer string
+	if n := len(root.Content); n >= 2 {
+		if strings.Contains(root.Content[0].HeadComment, "\n") {
+			header, root.Content[0].HeadComment = root.Content[0].HeadComment, ""
+		}
+		footer, root.Content[n-2].FootComment = root.Content[n-2].FootComment, ""
+	}
+	specFileLayout.apply(root, prune)
+	if n := len(root.Content); n >= 2 {
+		root.Content[0].HeadComment = joinComments(header, root.Content[0].HeadComment)
+		root.Content[n-2].FootComment = joinComments(root.Content[n-2].FootComment, footer)
+	}
+	normalizeStyle(doc)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close YAML encoder: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// FormatFile formats a spec.yaml, or a version overlay if it is in a versions directory,
+// rewriting it unless checkOnly is set, and reports whether it was not already formatted
+func FormatFile(path string, checkOnly bool) (bool, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - path is a spec file in the registry
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	format := FormatSpec
+	if filepath.Base(filepath.Dir(path)) == VersionsDir {
+		format = FormatOverlay
+	}
+	formatted, err := format(data)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", path, err)
+	}
+	if bytes.Equal(data, formatted) {
+		return false, nil
+	}
+	if checkOnly {
+		return true, nil
+	}
+	if err := writeFileAtomic(path, formatted, 0600); err != nil {
+		return true, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// SpecFiles returns the spec.yaml and version overlay files of every entry in the registry
+func SpecFiles(registryPath string) ([]string, error) {
+	dirs, err := os.ReadDir(registryPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry directory: %w", err)
+	}
+
+	var files []string
+	for _, dir := range dirs {
+		if !dir.IsDir() || strings.HasPrefix(dir.Name(), ".") {
+			continue
+		}
+		specPath := filepath.Join(registryPath, dir.Name(), "spec.yaml")
+		if _, err := os.Stat(specPath); err != nil {
+			continue
+		}
+		files = append(files, specPath)
+
+		overlays, err := os.ReadDir(filepath.Join(registryPath, dir.Name(), VersionsDir))
+		if err != nil {
+			continue
+		}
+		for _, overlay := range overlays {
+			ext := filepath.Ext(overlay.Name())
+			if !overlay.IsDir() && (ext == ".yaml" || ext == ".yml") {
+				files = append(files, filepath.Join(registryPath, dir.Name(), VersionsDir, overlay.Name()))
+			}
+		}
+	}
+	return files, nil
+}
+
+// apply rewrites a mapping node, or every mapping in a list node, in the layout
+func (l *specLayout) apply(node *yaml.Node, prune bool) {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if item.Kind == yaml.MappingNode {
+				l.apply(item, prune)
+			}
+		}
+	case yaml.MappingNode:
+		l.applyMapping(node, prune)
+	default:
+	}
+}
+
+// applyMapping orders and sorts the fields of a mapping node, removing empty ones if prune is set
+func (l *specLayout) applyMapping(node *yaml.Node, prune bool) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if nested, ok := l.fields[key.Value]; ok {
+			nested.apply(value, prune)
+		}
+		if slices.Contains(l.sorted, key.Value) {
+			sortScalars(value)
+		}
+	}
+	orderMappingKeys(node, l.order)
+	if prune && l.dropEmpty {
+		pruneMapping(node)
+	}
+}
+
+// pruneMapping removes the fields of a mapping node that hold empty values. Comments of a
+// removed field move to the next field; without one, the last removed field is kept for them.
+func pruneMapping(node *yaml.Node) {
+	content := make([]*yaml.Node, 0, len(node.Content))
+	var pendingComment string
+	var lastPruned []*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if isEmptyValue(value) {
+			pendingComment = joinComments(pendingComment, key.HeadComment)
+			lastPruned = node.Content[i : i+2]
+			continue
+		}
+		key.HeadComment = joinComments(pendingComment, key.HeadComment)
+		pendingComment = ""
+		content = append(content, key, value)
+	}
+	if pendingComment != "" {
+		lastPruned[0].HeadComment = pendingComment
+		content = append(content, lastPruned...)
+	}
+	node.Content = content
+}
+
+// orderMappingKeys sorts the keys of a mapping node into the given order, keeping the
+// original order of keys that aren't listed after the listed ones
+func orderMappingKeys(node *yaml.Node, order []string) {
+	type field struct{ key, value *yaml.Node }
+	fields := make([]field, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		fields = append(fields, field{node.Content[i], node.Content[i+1]})
+	}
+
+	rank := func(key string) int {
+		if i := slices.Index(order, key); i >= 0 {
+			return i
+		}
+		return len(order)
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		return rank(fields[i].key.Value) < rank(fields[j].key.Value)
+	})
+
+	node.Content = node.Content[:0]
+	for _, f := range fields {
+		node.Content = append(node.Content, f.key, f.value)
+	}
+}
+
+// isEmptyValue reports whether a node is an empty list or a false boolean
+func isEmptyValue(node *yaml.Node) bool {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		return len(node.Content) == 0
+	case yaml.ScalarNode:
+		return node.Tag == "!!bool" && node.Value == "false"
+	default:
+		return false
+	}
+}
+
+// sortScalars sorts a list of scalars, numerically when both values are integers. Lists
+// holding anything but scalars, or grouped under comments between the items, are left alone.
+func sortScalars(node *yaml.Node) {
+	if node.Kind != yaml.SequenceNode {
+		return
+	}
+	for _, item := range node.Content {
+		if item.Kind != yaml.ScalarNode || item.HeadComment != "" || item.FootComment != "" {
+			return
+		}
+	}
+	sort.SliceStable(node.Content, func(i, j int) bool {
+		a, b := node.Content[i].Value, node.Content[j].Value
+		aInt, aErr := strconv.Atoi(a)
+		bInt, bErr := strconv.Atoi(b)
+		if aErr == nil && bErr == nil {
+			return aInt < bInt
+		}
+		return a < b
+	})
+}
+
+// normalizeStyle switches every node to block style and plain scalars, keeping literal and
+// folded blocks; the encoder quotes scalars that would otherwise change type
+func normalizeStyle(node *yaml.Node) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+			node.Style = 0
+		}
+	case yaml.SequenceNode, yaml.MappingNode:
+		node.Style &^= yaml.FlowStyle
+	default:
+	}
+	node.HeadComment = trimCommentLines(node.HeadComment)
+	node.LineComment = trimCommentLines(node.LineComment)
+	node.FootComment = trimCommentLines(node.FootComment)
+	for _, child := range node.Content {
+		normalizeStyle(child)
+	}
+}
+
+// joinComments joins two comments, either of which may be empty
+func joinComments(first, second string) string {
+	if first == "" || second == "" {
+		return first + second
+	}
+	return first + "\n" + second
+}
+
+// trimCommentLines removes trailing whitespace from every line of a comment
+func trimCommentLines(comment string) string {
+	if comment == "" {
+		return comment
+	}
+	lines := strings.Split(comment, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.Join(lines, "\n")
+}
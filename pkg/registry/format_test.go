@@ -0,0 +1,267 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func TestFormatSpec(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name: "canonical key order and sorted lists",
+			input: `image: "ghcr.io/example/server:1.0.0"
+tags: [search, api]
+description: 'A server'
+transport: stdio
+tools:
+  - write
+  - read
+status: Active
+tier: Community
+metadata:
+  last_updated: "2025-01-01T00:00:00Z"
+  stars: 1
+  pulls: 2
+env_vars:
+  - description: API key
+    name: API_KEY
+    required: true
+license: MIT
+`,
+			want: `image: ghcr.io/example/server:1.0.0
+description: A server
+transport: stdio
+license: MIT
+tier: Community
+status: Active
+tags:
+  - api
+  - search
+tools:
+  - read
+  - write
+env_vars:
+  - name: API_KEY
+    description: API key
+    required: true
+metadata:
+  stars: 1
+  pulls: 2
+  last_updated: "2025-01-01T00:00:00Z"
+`,
+		},
+		{
+			name: "permissions are normalized",
+			input: `image: ghcr.io/example/server:1.0.0
+permissions:
+  network:
+    outbound:
+      allow_port: [8080, 443]
+      allow_host:
+        - z.example.com
+        - a.example.com
+      insecure_allow_all: false
+  read: []
+  write: []
+`,
+			want: `image: ghcr.io/example/server:1.0.0
+permissions:
+  network:
+    outbound:
+      allow_host:
+        - a.example.com
+        - z.example.com
+      allow_port:
+        - 443
+        - 8080
+`,
+		},
+		{
+			name: "comments are kept",
+			input: `# Imported entry
+# ---
+name: server
+# Docker image
+image: ghcr.io/example/server:1.0.0
+description: A server # short
+tools:
+  # Read tools
+  - read_file
+  # Write tools
+  - write_file
+  - delete_file
+permissions:
+  # File system access
+  read: []
+  network:
+    outbound:
+      allow_host: [example.com]
+`,
+			want: `# Imported entry
+# ---
+name: server
+# Docker image
+image: ghcr.io/example/server:1.0.0
+description: A server # short
+tools:
+  # Read tools
+  - read_file
+  # Write tools
+  - write_file
+  - delete_file
+permissions:
+  # File system access
+  network:
+    outbound:
+      allow_host:
+        - example.com
+`,
+		},
+		{
+			name: "multi-line comment above the first key stays at the top",
+			input: `# WARNING: Tool list fetch failed
+# Manual verification may be required
+url: https://mcp.example.com/mcp
+description: Remote server
+`,
+			want: `# WARNING: Tool list fetch failed
+# Manual verification may be required
+url: https://mcp.example.com/mcp
+description: Remote server
+`,
+		},
+		{
+			name: "remote server fields",
+			input: `url: https://mcp.example.com/mcp
+author: Example
+homepage: https://example.com
+oauth:
+  scopes: [read]
+  issuer: https://auth.example.com
+headers:
+  - required: true
+    name: X-Region
+    choices: [us, eu]
+description: Remote server
+`,
+			want: `url: https://mcp.example.com/mcp
+description: Remote server
+homepage: https://example.com
+author: Example
+headers:
+  - name: X-Region
+    required: true
+    choices:
+      - us
+      - eu
+oauth:
+  issuer: https://auth.example.com
+  scopes:
+    - read
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			formatted, err := FormatSpec([]byte(tt.input))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(formatted))
+
+			again, err := FormatSpec(formatted)
+			require.NoError(t, err)
+			assert.Equal(t, string(formatted), string(again), "formatting must be idempotent")
+		})
+	}
+}
+
+func TestFormatOverlay_KeepsOverrides(t *testing.T) {
+	t.Parallel()
+
+	overlay := "permissions:\n  read: []\n  privileged: false\nimage: ghcr.io/example/server:0.9.0\ntags: null\n"
+	formatted, err := FormatOverlay([]byte(overlay))
+	require.NoError(t, err)
+	assert.Equal(t, "image: ghcr.io/example/server:0.9.0\ntags: null\npermissions:\n  read: []\n  privileged: false\n",
+		string(formatted))
+}
+
+func TestFormatSpec_MarshalSpecKeyOrder(t *testing.T) {
+	t.Parallel()
+
+	entry := &types.RegistryEntry{
+		RemoteServerMetadata: &toolhiveRegistry.RemoteServerMetadata{
+			BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+				Name:           "remote",
+				Description:    "Remote server",
+				Transport:      "sse",
+				Tools:          []string{"a", "b"},
+				CustomMetadata: map[string]any{"homepage": "https://example.com", "author": "Example"},
+			},
+			URL:         "https://mcp.example.com/sse",
+			OAuthConfig: &toolhiveRegistry.OAuthConfig{Issuer: "https://auth.example.com", UsePKCE: true},
+		},
+		License: "MIT",
+		Version: "1.0.0",
+	}
+
+	data, err := MarshalSpec(entry)
+	require.NoError(t, err)
+	formatted, err := FormatSpec(data)
+	require.NoError(t, err)
+	assert.Equal(t, string(data), string(formatted))
+}
+
+func TestFormatFile(t *testing.T) {
+	t.Parallel()
+
+	registryPath := t.TempDir()
+	for path, content := range map[string]string{
+		"server/spec.yaml":           "image: ghcr.io/example/server:1.0.0\nname: server\n",
+		"server/versions/0.9.0.yaml": "permissions:\n  read: []\n",
+		"server/README.md":           "# server\n",
+		".hidden/spec.yaml":          "name: hidden\n",
+	} {
+		path = filepath.Join(registryPath, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+
+	files, err := SpecFiles(registryPath)
+	require.NoError(t, err)
+	specPath := filepath.Join(registryPath, "server", "spec.yaml")
+	overlayPath := filepath.Join(registryPath, "server", VersionsDir, "0.9.0.yaml")
+	assert.Equal(t, []string{specPath, overlayPath}, files)
+
+	changed, err := FormatFile(specPath, true)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	data, err := os.ReadFile(specPath)
+	require.NoError(t, err)
+	assert.Equal(t, "image: ghcr.io/example/server:1.0.0\nname: server\n", string(data), "check must not write")
+
+	changed, err = FormatFile(specPath, false)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	data, err = os.ReadFile(specPath)
+	require.NoError(t, err)
+	assert.Equal(t, "name: server\nimage: ghcr.io/example/server:1.0.0\n", string(data))
+
+	changed, err = FormatFile(overlayPath, false)
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
@@ -0,0 +1,345 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	upstream "github.com/modelcontextprotocol/registry/pkg/api/v0"
+	"golang.org/x/mod/semver"
+	"gopkg.in/yaml.v3"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// ImportBaseFile is the file in an entry directory that records the spec as it was last
+// imported. It is the common ancestor when the entry is imported again, so that local
+// edits made since then can be told apart from upstream changes.
+const ImportBaseFile = ".import-base.yaml"
+
+// importBaseHeader is written at the top of ImportBaseFile
+const importBaseHeader = "# The spec as last imported, used to merge the next import. Do not edit.\n"
+
+// ErrMergeConflicts is returned when an import is blocked by conflicting local and upstream changes
+var ErrMergeConflicts = errors.New("conflicting local and upstream changes")
+
+// SpecImport is an imported spec.yaml, merged with the local spec if the entry already exists
+type SpecImport struct {
+	// EntryDir is the entry directory
+	EntryDir string
+	// Spec is the imported spec, recorded as the base of the next import
+	Spec []byte
+	// Data is the spec.yaml to write; it is nil when conflicts block the import
+	Data []byte
+	// Created reports whether the entry is new
+	Created bool
+	// Changed reports whether spec.yaml changes
+	Changed bool
+	// Updated lists the fields of an existing entry that are updated from upstream
+	Updated []string
+	// Conflicts lists the fields of an existing entry that changed both locally and upstream
+	Conflicts []MergeConflict
+}
+
+// MergeImport prepares the import of a spec into entryDir. A new entry gets the spec with the
+// header comment. An existing spec.yaml is three-way merged with the spec, using the spec of
+// the previous import as the common ancestor, so that local edits, comments and key order
+// survive and only fields that changed upstream are updated. Conflicting fields are resolved
+// with the strategy; with MergeStrategyFail they are returned with an ErrMergeConflicts error.
+func MergeImport(entryDir, header string, spec []byte, strategy MergeStrategy) (*SpecImport, error) {
+	specPath := filepath.Join(entryDir, "spec.yaml")
+	imported := &SpecImport{EntryDir: entryDir, Spec: spec}
+
+	local, err := os.ReadFile(specPath) // #nosec G304 - path is built from the registry directory
+	if os.IsNotExist(err) {
+		imported.Created = true
+		imported.Changed = true
+		imported.Data = append([]byte(header), spec...)
+		return imported, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", specPath, err)
+	}
+
+	base, err := os.ReadFile(filepath.Join(entryDir, ImportBaseFile)) // #nosec G304 - path is built from the registry directory
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read import base: %w", err)
+	}
+
+	result, err := MergeSpec(base, local, spec, strategy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge %s: %w", specPath, err)
+	}
+	imported.Updated = result.Updated
+	imported.Conflicts = result.Conflicts
+	if result.Data == nil {
+		return imported, fmt.Errorf("%s: %w", specPath, ErrMergeConflicts)
+	}
+
+	var merged types.RegistryEntry
+	if err := yaml.Unmarshal(result.Data, &merged); err != nil {
+		return imported, fmt.Errorf("merged %s is not a valid entry: %w", specPath, err)
+	}
+	imported.Data = result.Data
+	imported.Changed = !bytes.Equal(result.Data, local)
+	return imported, nil
+}
+
+// Write writes spec.yaml if it changed, and records the imported spec as the base of the next import
+func (s *SpecImport) Write() error {
+	if s.Data == nil {
+		return fmt.Errorf("%s: %w", s.EntryDir, ErrMergeConflicts)
+	}
+	if err := os.MkdirAll(s.EntryDir, 0750); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if s.Changed {
+		if err := writeFileAtomic(filepath.Join(s.EntryDir, "spec.yaml"), s.Data, 0600); err != nil {
+			return fmt.Errorf("failed to write spec.yaml: %w", err)
+		}
+	}
+	base := append([]byte(importBaseHeader), s.Spec...)
+	if err := writeFileAtomic(filepath.Join(s.EntryDir, ImportBaseFile), base, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", ImportBaseFile, err)
+	}
+	return nil
+}
+
+// ImportedEntry is an entry converted from the official format, ready to be written as a spec directory
+type ImportedEntry struct {
+	// Name is the entry name, used as the directory name
+	Name string
+	// Entry is the latest version, written as spec.yaml
+	Entry *types.RegistryEntry
+	// Versions holds the other versions keyed by version, written as versions/<version>.yaml overlays
+	Versions map[string]*types.RegistryEntry
+}
+
+// ParseServerList parses official-format servers from an official-registry.json, a
+// GET /v0/servers response or a plain JSON array of servers
+func ParseServerList(data []byte) ([]upstream.ServerJSON, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var servers []upstream.ServerJSON
+		if err := json.Unmarshal(trimmed, &servers); err != nil {
+			return nil, fmt.Errorf("failed to parse server list: %w", err)
+		}
+		return servers, nil
+	}
+
+	var probe struct {
+		Data    *struct{ Servers []upstream.ServerJSON } `json:"data"`
+		Servers []upstream.ServerJSON                    `json:"servers"`
+	}
+	if err := json.Unmarshal(trimmed, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse official registry JSON: %w", err)
+	}
+	if probe.Data != nil {
+		return probe.Data.Servers, nil
+	}
+	if probe.Servers == nil {
+		return nil, fmt.Errorf("no servers found; expected an official-registry.json or a server list")
+	}
+	return probe.Servers, nil
+}
+
+// ImportServers converts official-format servers into entries. Versions of the same
+// server are grouped: the latest becomes the entry and the rest become version overlays.
+// Servers that can't be converted are reported as errors and skipped.
+func ImportServers(servers []upstream.ServerJSON) ([]*ImportedEntry, []error) {
+	var errs []error
+	byName := make(map[string][]upstream.ServerJSON)
+	for _, server := range servers {
+		name := entryNameFromServerName(server.Name)
+		byName[name] = append(byName[name], server)
+	}
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	imported := make([]*ImportedEntry, 0, len(names))
+	for _, name := range names {
+		entry, entryErrs := importServerVersions(name, byName[name])
+		errs = append(errs, entryErrs...)
+		if entry != nil {
+			imported = append(imported, entry)
+		}
+	}
+	return imported, errs
+}
+
+// importServerVersions converts all versions of one server
+func importServerVersions(name string, versions []upstream.ServerJSON) (*ImportedEntry, []error) {
+	latest := latestServerIndex(versions)
+	_, entry, err := EntryFromServerJSON(versions[latest])
+	if err != nil {
+		return nil, []error{err}
+	}
+	entry.SetName(name)
+
+	var errs []error
+	imported := &ImportedEntry{Name: name, Entry: entry, Versions: make(map[string]*types.RegistryEntry)}
+	for i, server := range versions {
+		if i == latest || server.Version == versions[latest].Version {
+			continue
+		}
+		if !isSemver(server.Version) {
+			errs = append(errs, fmt.Errorf("server %s version %q is not a semantic version; skipping it",
+				server.Name, server.Version))
+			continue
+		}
+		_, versionEntry, err := EntryFromServerJSON(server)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		versionEntry.SetName(name)
+		imported.Versions[strings.TrimPrefix(server.Version, "v")] = versionEntry
+	}
+	return imported, errs
+}
+
+// latestServerIndex returns the index of the version marked as latest, or of the highest semantic version
+func latestServerIndex(versions []upstream.ServerJSON) int {
+	latest := 0
+	for i, server := range versions {
+		if isLatestServer(server) {
+			return i
+		}
+		if isSemver(server.Version) && (!isSemver(versions[latest].Version) ||
+			semver.Compare(semverCanonical(server.Version), semverCanonical(versions[latest].Version)) > 0) {
+			latest = i
+		}
+	}
+	return latest
+}
+
+// Merge prepares the import of the entry's spec.yaml into <registryPath>/<name> with MergeImport
+func (e *ImportedEntry) Merge(registryPath, header string, strategy MergeStrategy) (*SpecImport, error) {
+	data, err := MarshalSpec(e.Entry)
+	if err != nil {
+		return nil, err
+	}
+	return MergeImport(filepath.Join(registryPath, e.Name), header, data, strategy)
+}
+
+// Write writes the merged spec.yaml plus a versions/<version>.yaml overlay for every other
+// version. Overlays describe the imported versions and are always replaced.
+func (e *ImportedEntry) Write(spec *SpecImport) error {
+	if err := spec.Write(); err != nil {
+		return err
+	}
+
+	if len(e.Versions) == 0 {
+		return nil
+	}
+	versionsDir := filepath.Join(spec.EntryDir, VersionsDir)
+	if err := os.MkdirAll(versionsDir, 0750); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	for version, entry := range e.Versions {
+		overlay, err := MarshalOverlay(e.Entry, entry)
+		if err != nil {
+			return fmt.Errorf("version %s: %w", version, err)
+		}
+		if err := writeFileAtomic(filepath.Join(versionsDir, version+".yaml"), overlay, 0600); err != nil {
+			return fmt.Errorf("failed to write version %s: %w", version, err)
+		}
+	}
+	return nil
+}
+
+// MarshalOverlay encodes the fields in which a version differs from the base entry, so that
+// merging the overlay over the base spec reproduces the version. Mappings are compared key by
+// key, as they are merged, and fields the version doesn't have are cleared with null.
+func MarshalOverlay(base, version *types.RegistryEntry) ([]byte, error) {
+	baseNode, err := SpecNode(base)
+	if err != nil {
+		return nil, err
+	}
+	versionNode, err := SpecNode(version)
+	if err != nil {
+		return nil, err
+	}
+
+	// The name can't change and the version comes from the overlay's file name
+	for _, node := range []*yaml.Node{baseNode, versionNode} {
+		deleteMappingKey(node, "name")
+		deleteMappingKey(node, "version")
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(overlayMapping(baseNode, versionNode)); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close YAML encoder: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// overlayMapping returns the mapping that turns base into target when merged with mergeYAMLNodes
+func overlayMapping(base, target *yaml.Node) *yaml.Node {
+	overlay := &yaml.Node{Kind: yaml.MappingNode}
+	for i := 0; i+1 < len(target.Content); i += 2 {
+		key, value := target.Content[i], target.Content[i+1]
+		baseValue := mappingValue(base, key.Value)
+		switch {
+		case baseValue == nil:
+			overlay.Content = append(overlay.Content, key, value)
+		case baseValue.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			if nested := overlayMapping(baseValue, value); len(nested.Content) > 0 {
+				overlay.Content = append(overlay.Content, key, nested)
+			}
+		case !sameYAML(baseValue, value):
+			overlay.Content = append(overlay.Content, key, value)
+		}
+	}
+	for i := 0; i+1 < len(base.Content); i += 2 {
+		key := base.Content[i]
+		if mappingValue(target, key.Value) == nil {
+			overlay.Content = append(overlay.Content, key, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"})
+		}
+	}
+	return overlay
+}
+
+// deleteMappingKey removes a key and its value from a mapping node
+func deleteMappingKey(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// mappingValue returns the value of a key in a mapping node, or nil if it is missing
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// sameYAML reports whether two nodes encode to the same YAML
+func sameYAML(a, b *yaml.Node) bool {
+	aData, aErr := yaml.Marshal(a)
+	bData, bErr := yaml.Marshal(b)
+	return aErr == nil && bErr == nil && bytes.Equal(aData, bData)
+}
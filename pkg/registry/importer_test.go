@@ -0,0 +1,228 @@
+package registry
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	upstream "github.com/modelcontextprotocol/registry/pkg/api/v0"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const importTestImageSpec = `description: Image server
+image: registry.internal:5000/mcp/server:2.0.0
+transport: stdio
+tier: Official
+status: Active
+repository_url: https://github.com/example/server
+tools:
+  - read
+  - write
+tags:
+  - files
+target_port: 8080
+args:
+  - --verbose
+env_vars:
+  - name: API_KEY
+    description: API key
+    required: true
+    secret: true
+permissions:
+  read:
+    - /data
+  network:
+    outbound:
+      allow_host:
+        - api.example.com
+      allow_port:
+        - 443
+provenance:
+  sigstore_url: tuf-repo-cdn.sigstore.dev
+  repository_uri: https://github.com/example/server
+  cert_issuer: https://token.actions.githubusercontent.com
+metadata:
+  stars: 10
+  pulls: 20
+  last_updated: "2025-01-01T00:00:00Z"
+license: MIT
+examples:
+  - name: basic
+    description: Basic usage
+    sample: thv run server
+tool_definitions:
+  - name: read
+    description: Reads a file
+    input_schema:
+      type: object
+      properties:
+        path:
+          type: string
+        limit:
+          type: integer
+          minimum: 1
+      required: [path]
+    annotations:
+      readOnlyHint: true
+resources:
+  - uri: file:///data/readme.md
+    name: readme
+    mime_type: text/markdown
+prompts:
+  - name: summarize
+    description: Summarize a file
+    arguments:
+      - name: path
+        required: true
+`
+
+const importTestRemoteSpec = `url: https://mcp.example.com/sse
+description: Remote server
+transport: sse
+tier: Community
+status: Active
+version: 0.3.0
+homepage: https://example.com
+author: Example
+tools:
+  - search
+headers:
+  - name: X-Region
+    description: Region
+    required: true
+    default: eu
+    choices: [eu, us]
+env_vars:
+  - name: WORKSPACE
+    description: Workspace to use
+oauth:
+  issuer: https://auth.example.com
+  client_id: toolhive
+  scopes: [read]
+  use_pkce: false
+resource_templates:
+  - uri_template: search://{query}
+    name: results
+    description: Results of a search
+`
+
+func writeImportTestRegistry(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for path, content := range map[string]string{
+		"image/spec.yaml":           importTestImageSpec,
+		"image/versions/1.0.0.yaml": "image: registry.internal:5000/mcp/server:1.0.0\npermissions:\n  read: null\ntools:\n  - read\n",
+		"remote/spec.yaml":          importTestRemoteSpec,
+	} {
+		path = filepath.Join(dir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+	return dir
+}
+
+func buildImportTestServers(t *testing.T, dir string) []upstream.ServerJSON {
+	t.Helper()
+	loader := NewLoader(dir)
+	require.NoError(t, loader.LoadAll())
+	clock, err := NewBuildClock(loader, true)
+	require.NoError(t, err)
+	servers, err := BuildOfficialServers(loader, clock)
+	require.NoError(t, err)
+
+	// Timestamps come from file modification times, which differ between the two trees
+	for i := range servers {
+		servers[i].Meta.Official.PublishedAt = time.Time{}
+		servers[i].Meta.Official.UpdatedAt = time.Time{}
+	}
+	return servers
+}
+
+func TestImportServers_RoundTrip(t *testing.T) {
+	// The temporary registries have no git history to take the build time from
+	t.Setenv(SourceDateEpochEnv, "1700000000")
+
+	original := buildImportTestServers(t, writeImportTestRegistry(t))
+	require.Len(t, original, 3)
+
+	data, err := json.Marshal(ToolHiveRegistryType{Data: Data{Servers: original}})
+	require.NoError(t, err)
+	servers, err := ParseServerList(data)
+	require.NoError(t, err)
+
+	entries, errs := ImportServers(servers)
+	require.Empty(t, errs)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "image", entries[0].Name)
+	assert.Contains(t, entries[0].Versions, "1.0.0")
+
+	importDir := t.TempDir()
+	for _, entry := range entries {
+		spec, err := entry.Merge(importDir, "# imported\n", MergeStrategyFail)
+		require.NoError(t, err)
+		assert.True(t, spec.Created)
+		require.NoError(t, entry.Write(spec))
+	}
+
+	overlay, err := os.ReadFile(filepath.Join(importDir, "image", VersionsDir, "1.0.0.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(overlay), "read: null")
+
+	assert.Equal(t, original, buildImportTestServers(t, importDir))
+
+	spec, err := os.ReadFile(filepath.Join(importDir, "image", "spec.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(spec), "tool_definitions:\n  - name: read\n    description: Reads a file\n")
+	assert.Contains(t, string(spec), "resources:\n  - uri: file:///data/readme.md\n")
+	assert.Contains(t, string(spec), "prompts:\n  - name: summarize\n")
+
+	spec, err = os.ReadFile(filepath.Join(importDir, "remote", "spec.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(spec), "resource_templates:\n  - uri_template: search://{query}\n")
+}
+
+func TestImportServers_ForeignServers(t *testing.T) {
+	t.Parallel()
+
+	servers, err := ParseServerList([]byte(`{"servers": [
+		{"name": "io.github.example/weather", "description": "Weather", "version": "1.2.0",
+		 "packages": [{"registry_type": "npm", "identifier": "weather"},
+		              {"registry_type": "oci", "registry_base_url": "https://docker.io",
+		               "identifier": "example/weather", "version": "1.2.0", "transport": {"type": "stdio"}}]},
+		{"name": "io.github.example/weather", "description": "Weather", "version": "nightly",
+		 "packages": [{"registry_type": "oci", "identifier": "example/weather", "version": "nightly"}]},
+		{"name": "io.github.example/npm-only", "description": "npm only", "version": "1.0.0",
+		 "packages": [{"registry_type": "npm", "identifier": "npm-only"}]}
+	], "metadata": {"count": 3}}`))
+	require.NoError(t, err)
+
+	entries, errs := ImportServers(servers)
+	require.Len(t, errs, 2)
+	assert.ErrorContains(t, errs[0], "no OCI package")
+	assert.ErrorContains(t, errs[1], "not a semantic version")
+
+	require.Len(t, entries, 1)
+	entry := entries[0]
+	assert.Equal(t, "weather", entry.Name)
+	assert.Equal(t, "example/weather:1.2.0", entry.Entry.Image)
+	assert.Equal(t, "stdio", entry.Entry.GetTransport())
+	assert.Empty(t, entry.Entry.Version)
+	assert.Empty(t, entry.Versions)
+}
+
+func TestParseServerList(t *testing.T) {
+	t.Parallel()
+
+	servers, err := ParseServerList([]byte(`[{"name": "a", "version": "1.0.0"}]`))
+	require.NoError(t, err)
+	assert.Equal(t, []upstream.ServerJSON{{Name: "a", Version: "1.0.0"}}, servers)
+
+	_, err = ParseServerList([]byte(`{"servers": {"a": {}}}`))
+	assert.Error(t, err)
+
+	_, err = ParseServerList([]byte(`{"version": "1.0.0"}`))
+	assert.ErrorContains(t, err, "no servers found")
+}
@@ -0,0 +1,231 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// DefaultLintConfigFile is the lint configuration file looked up at the repository root
+const DefaultLintConfigFile = ".registry-lint.yaml"
+
+// LintFinding is a single problem reported by a lint rule
+type LintFinding struct {
+	// Field is the dotted path of the offending field (e.g. "env_vars.0.name")
+	Field string
+	// Message describes the problem
+	Message string
+}
+
+// LintRule is a named check that can be individually enabled, disabled and re-graded
+type LintRule struct {
+	// Name identifies the rule in configuration and diagnostics
+	Name string
+	// Description explains what the rule checks
+	Description string
+	// Severity is the default severity of the rule's findings
+	Severity Severity
+	// Enabled reports whether the rule runs when the configuration does not mention it
+	Enabled bool
+	// Check inspects an entry and returns its findings
+	Check func(entry *types.RegistryEntry, options RuleOptions) []LintFinding
+}
+
+// RuleOptions holds rule-specific settings from the lint configuration
+type RuleOptions map[string]interface{}
+
+// Int returns an integer option or the default if it is not set
+func (o RuleOptions) Int(key string, def int) int {
+	if value, ok := o[key].(int); ok {
+		return value
+	}
+	return def
+}
+
+// Strings returns a string list option or the default if it is not set
+func (o RuleOptions) Strings(key string, def []string) []string {
+	raw, ok := o[key].([]interface{})
+	if !ok {
+		return def
+	}
+	values := make([]string, 0, len(raw))
+	for _, item := range raw {
+		if s, ok := item.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+// LintRuleConfig configures a single rule in .registry-lint.yaml
+type LintRuleConfig struct {
+	// Enabled turns the rule on or off, overriding its default
+	Enabled *bool `yaml:"enabled,omitempty"`
+	// Severity overrides the rule's default severity
+	Severity Severity `yaml:"severity,omitempty"`
+	// Options holds any other rule-specific keys
+	Options RuleOptions `yaml:",inline"`
+}
+
+// LintConfig is the content of .registry-lint.yaml
+type LintConfig struct {
+	// Rules maps rule names to their configuration
+	Rules map[string]LintRuleConfig `yaml:"rules"`
+}
+
+// LoadLintConfig reads a lint configuration file.
+// A missing file is not an error and yields the default configuration.
+func LoadLintConfig(path string) (*LintConfig, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - path comes from command line flag
+	if errors.Is(err, os.ErrNotExist) {
+		return &LintConfig{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lint config: %w", err)
+	}
+
+	var config LintConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse lint config: %w", err)
+	}
+
+	return &config, nil
+}
+
+// Linter runs lint rules against registry entries
+type Linter struct {
+	rules  []LintRule
+	config *LintConfig
+}
+
+// NewLinter creates a linter with the built-in rules plus any extra rules.
+// It returns an error if the configuration refers to unknown rules or severities.
+func NewLinter(config *LintConfig, extraRules ...LintRule) (*Linter, error) {
+	if config == nil {
+		config = &LintConfig{}
+	}
+
+	l := &Linter{config: config}
+	for _, rule := range append(DefaultLintRules(), extraRules...) {
+		if err := l.register(rule); err != nil {
+			return nil, err
+		}
+	}
+
+	for name, ruleConfig := range config.Rules {
+		if _, ok := l.rule(name); !ok {
+			return nil, fmt.Errorf("unknown lint rule in config: %s", name)
+		}
+		switch ruleConfig.Severity {
+		case "", SeverityError, SeverityWarning, SeverityInfo:
+		default:
+			return nil, fmt.Errorf("invalid severity %q for lint rule %s", ruleConfig.Severity, name)
+		}
+	}
+
+	return l, nil
+}
+
+// register adds a rule, rejecting duplicate names
+func (l *Linter) register(rule LintRule) error {
+	if rule.Name == "" || rule.Check == nil {
+		return fmt.Errorf("lint rule must have a name and a check function")
+	}
+	if _, exists := l.rule(rule.Name); exists {
+		return fmt.Errorf("duplicate lint rule: %s", rule.Name)
+	}
+	l.rules = append(l.rules, rule)
+	return nil
+}
+
+// rule looks up a registered rule by name
+func (l *Linter) rule(name string) (LintRule, bool) {
+	for _, rule := range l.rules {
+		if rule.Name == name {
+			return rule, true
+		}
+	}
+	return LintRule{}, false
+}
+
+// Rules returns all registered rules sorted by name
+func (l *Linter) Rules() []LintRule {
+	rules := make([]LintRule, len(l.rules))
+	copy(rules, l.rules)
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Name < rules[j].Name
+	})
+	return rules
+}
+
+// IsEnabled reports whether a rule runs under the current configuration
+func (l *Linter) IsEnabled(rule LintRule) bool {
+	if ruleConfig, ok := l.config.Rules[rule.Name]; ok && ruleConfig.Enabled != nil {
+		return *ruleConfig.Enabled
+	}
+	return rule.Enabled
+}
+
+// SeverityOf returns the configured severity of a rule
+func (l *Linter) SeverityOf(rule LintRule) Severity {
+	if ruleConfig, ok := l.config.Rules[rule.Name]; ok && ruleConfig.Severity != "" {
+		return ruleConfig.Severity
+	}
+	return rule.Severity
+}
+
+// RuleDescriptions returns the descriptions of all registered rules keyed by name
+func (l *Linter) RuleDescriptions() map[string]string {
+	descriptions := make(map[string]string, len(l.rules))
+	for _, rule := range l.rules {
+		descriptions[rule.Name] = rule.Description
+	}
+	return descriptions
+}
+
+// LintEntry runs every enabled rule against a single entry
+func (l *Linter) LintEntry(entry *types.RegistryEntry, name string) Diagnostics {
+	var diags Diagnostics
+	for _, rule := range l.Rules() {
+		if !l.IsEnabled(rule) {
+			continue
+		}
+		options := l.config.Rules[rule.Name].Options
+		for _, finding := range rule.Check(entry, options) {
+			diags = append(diags, Diagnostic{
+				Entry:    name,
+				Field:    finding.Field,
+				Rule:     rule.Name,
+				Severity: l.SeverityOf(rule),
+				Message:  finding.Message,
+			})
+		}
+	}
+	return diags
+}
+
+// Lint runs every enabled rule against all entries of a loader.
+// Diagnostics point at the entries' source spec.yaml files.
+func (l *Linter) Lint(loader *Loader) Diagnostics {
+	entries := loader.GetEntries()
+
+	var names []string
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var diags Diagnostics
+	for _, name := range names {
+		diags = append(diags, l.LintEntry(entries[name], name)...)
+	}
+
+	loader.Locate(diags)
+	diags.Sort()
+	return diags
+}
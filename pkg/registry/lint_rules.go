@@ -0,0 +1,218 @@
+package registry
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+
+	"github.com/stacklok/toolhive-registry/pkg/imageref"
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// defaultDescriptionMaxLength matches the maxLength of the description field in the official server format
+const defaultDescriptionMaxLength = 100
+
+// defaultSecretEnvPatterns are env var name patterns that usually carry credentials
+var defaultSecretEnvPatterns = []string{"*_TOKEN", "*_KEY", "*_SECRET", "*_PASSWORD", "*_CREDENTIALS"}
+
+// DefaultLintRules returns the built-in lint rules
+func DefaultLintRules() []LintRule {
+	return []LintRule{
+		{
+			Name:        "image-tag-latest",
+			Description: "Images should be pinned to a specific tag or digest instead of latest",
+			Severity:    SeverityWarning,
+			Enabled:     true,
+			Check:       checkImageTagLatest,
+		},
+		{
+			Name:        "description-length",
+			Description: "Descriptions should fit the official registry limit (option: max)",
+			Severity:    SeverityWarning,
+			Enabled:     true,
+			Check:       checkDescriptionLength,
+		},
+		{
+			Name:        "description-period",
+			Description: "Descriptions should not end with a period",
+			Severity:    SeverityInfo,
+			Enabled:     true,
+			Check:       checkDescriptionPeriod,
+		},
+		{
+			Name:        "approved-tags",
+			Description: "Tags must come from the approved vocabulary (option: tags)",
+			Severity:    SeverityWarning,
+			Enabled:     false,
+			Check:       checkApprovedTags,
+		},
+		{
+			Name:        "repository-url-https",
+			Description: "Repository URLs should use https",
+			Severity:    SeverityWarning,
+			Enabled:     true,
+			Check:       checkRepositoryURLHTTPS,
+		},
+		{
+			Name:        "secret-env-vars",
+			Description: "Env vars and headers that look like credentials should be marked secret (option: patterns)",
+			Severity:    SeverityWarning,
+			Enabled:     true,
+			Check:       checkSecretEnvVars,
+		},
+		{
+			Name:        "remote-url-tls",
+			Description: "Remote server URLs must use https",
+			Severity:    SeverityError,
+			Enabled:     true,
+			Check:       checkRemoteURLTLS,
+		},
+	}
+}
+
+// checkImageTagLatest flags images that use the latest tag or no tag at all, unless pinned by digest
+func checkImageTagLatest(entry *types.RegistryEntry, _ RuleOptions) []LintFinding {
+	if !entry.IsImage() {
+		return nil
+	}
+	ref, err := imageref.Parse(entry.Image)
+	if err != nil || ref.Digest != "" || ref.Version() != imageref.LatestTag {
+		return nil
+	}
+	return []LintFinding{{
+		Field:   "image",
+		Message: fmt.Sprintf("image %s uses the latest tag; pin a specific version", entry.Image),
+	}}
+}
+
+// checkDescriptionLength flags descriptions longer than the configured maximum
+func checkDescriptionLength(entry *types.RegistryEntry, options RuleOptions) []LintFinding {
+	maxLength := options.Int("max", defaultDescriptionMaxLength)
+	description := entry.GetDescription()
+	if length := len([]rune(description)); length > maxLength {
+		return []LintFinding{{
+			Field:   "description",
+			Message: fmt.Sprintf("description is %d characters long (max %d)", length, maxLength),
+		}}
+	}
+	return nil
+}
+
+// checkDescriptionPeriod flags descriptions ending with a period
+func checkDescriptionPeriod(entry *types.RegistryEntry, _ RuleOptions) []LintFinding {
+	if strings.HasSuffix(strings.TrimSpace(entry.GetDescription()), ".") {
+		return []LintFinding{{
+			Field:   "description",
+			Message: "description should not end with a period",
+		}}
+	}
+	return nil
+}
+
+// checkApprovedTags flags tags that are not part of the configured vocabulary
+func checkApprovedTags(entry *types.RegistryEntry, options RuleOptions) []LintFinding {
+	approved := make(map[string]bool)
+	for _, tag := range options.Strings("tags", nil) {
+		approved[tag] = true
+	}
+	if len(approved) == 0 {
+		return nil
+	}
+
+	var findings []LintFinding
+	for i, tag := range entryTags(entry) {
+		if !approved[tag] {
+			findings = append(findings, LintFinding{
+				Field:   fmt.Sprintf("tags.%d", i),
+				Message: fmt.Sprintf("tag %q is not in the approved vocabulary", tag),
+			})
+		}
+	}
+	return findings
+}
+
+// checkRepositoryURLHTTPS flags repository URLs that do not use https
+func checkRepositoryURLHTTPS(entry *types.RegistryEntry, _ RuleOptions) []LintFinding {
+	metadata := entry.GetServerMetadata()
+	if metadata == nil {
+		return nil
+	}
+	repositoryURL := metadata.GetRepositoryURL()
+	if repositoryURL == "" || strings.HasPrefix(repositoryURL, "https://") {
+		return nil
+	}
+	return []LintFinding{{
+		Field:   "repository_url",
+		Message: fmt.Sprintf("repository_url %s should use https", repositoryURL),
+	}}
+}
+
+// checkSecretEnvVars flags credential-looking env vars and headers that are not marked secret
+func checkSecretEnvVars(entry *types.RegistryEntry, options RuleOptions) []LintFinding {
+	patterns := options.Strings("patterns", defaultSecretEnvPatterns)
+	looksSecret := func(name string) bool {
+		for _, pattern := range patterns {
+			if matched, _ := path.Match(pattern, strings.ToUpper(name)); matched {
+				return true
+			}
+		}
+		return false
+	}
+
+	var findings []LintFinding
+	for i, envVar := range entryEnvVars(entry) {
+		if envVar != nil && !envVar.Secret && looksSecret(envVar.Name) {
+			findings = append(findings, LintFinding{
+				Field:   fmt.Sprintf("env_vars.%d.name", i),
+				Message: fmt.Sprintf("env var %s looks like a credential but is not marked secret: true", envVar.Name),
+			})
+		}
+	}
+
+	if entry.IsRemote() {
+		for i, header := range entry.Headers {
+			if header != nil && !header.Secret && (looksSecret(header.Name) || strings.EqualFold(header.Name, "Authorization")) {
+				findings = append(findings, LintFinding{
+					Field:   fmt.Sprintf("headers.%d.name", i),
+					Message: fmt.Sprintf("header %s looks like a credential but is not marked secret: true", header.Name),
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+// checkRemoteURLTLS flags remote servers that are not reached over https
+func checkRemoteURLTLS(entry *types.RegistryEntry, _ RuleOptions) []LintFinding {
+	if !entry.IsRemote() {
+		return nil
+	}
+	parsed, err := url.Parse(entry.URL)
+	if err != nil {
+		return []LintFinding{{Field: "url", Message: fmt.Sprintf("url %s is not a valid URL: %v", entry.URL, err)}}
+	}
+	if parsed.Scheme != "https" {
+		return []LintFinding{{Field: "url", Message: fmt.Sprintf("remote url %s must use https", entry.URL)}}
+	}
+	return nil
+}
+
+// entryTags returns the tags of an entry regardless of its type
+func entryTags(entry *types.RegistryEntry) []string {
+	if metadata := entry.GetServerMetadata(); metadata != nil {
+		return metadata.GetTags()
+	}
+	return nil
+}
+
+// entryEnvVars returns the env vars of an entry regardless of its type
+func entryEnvVars(entry *types.RegistryEntry) []*toolhiveRegistry.EnvVar {
+	if metadata := entry.GetServerMetadata(); metadata != nil {
+		return metadata.GetEnvVars()
+	}
+	return nil
+}
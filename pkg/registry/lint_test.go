@@ -0,0 +1,90 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func TestLinter_LintEntry(t *testing.T) {
+	t.Parallel()
+
+	configPath := filepath.Join(t.TempDir(), DefaultLintConfigFile)
+	require.NoError(t, os.WriteFile(configPath, []byte(`rules:
+  image-tag-latest:
+    severity: error
+  description-period:
+    enabled: false
+  description-length:
+    max: 10
+  approved-tags:
+    enabled: true
+    tags: [api]
+`), 0644))
+
+	config, err := LoadLintConfig(configPath)
+	require.NoError(t, err)
+	linter, err := NewLinter(config)
+	require.NoError(t, err)
+
+	entry := &types.RegistryEntry{
+		ImageMetadata: &toolhiveRegistry.ImageMetadata{
+			BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+				Description:   "A server that does things.",
+				Transport:     "stdio",
+				Tools:         []string{"tool"},
+				Tags:          []string{"api", "misc"},
+				RepositoryURL: "http://github.com/example/server",
+			},
+			Image: "example/server",
+			EnvVars: []*toolhiveRegistry.EnvVar{
+				{Name: "API_TOKEN", Required: true},
+				{Name: "DB_PASSWORD", Secret: true},
+			},
+		},
+	}
+
+	diags := linter.LintEntry(entry, "server")
+
+	found := make(map[string]Diagnostic)
+	for _, diag := range diags {
+		found[diag.Rule+":"+diag.Field] = diag
+	}
+
+	assert.Equal(t, SeverityError, found["image-tag-latest:image"].Severity)
+	assert.Contains(t, found, "description-length:description")
+	assert.NotContains(t, found, "description-period:description")
+	assert.Contains(t, found, "approved-tags:tags.1")
+	assert.NotContains(t, found, "approved-tags:tags.0")
+	assert.Contains(t, found, "repository-url-https:repository_url")
+	assert.Contains(t, found, "secret-env-vars:env_vars.0.name")
+	assert.NotContains(t, found, "secret-env-vars:env_vars.1.name")
+	assert.Len(t, diags, 5)
+}
+
+func TestNewLinter_RejectsUnknownRules(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewLinter(&LintConfig{Rules: map[string]LintRuleConfig{"no-such-rule": {}}})
+	assert.ErrorContains(t, err, "unknown lint rule")
+
+	_, err = NewLinter(&LintConfig{Rules: map[string]LintRuleConfig{"image-tag-latest": {Severity: "fatal"}}})
+	assert.ErrorContains(t, err, "invalid severity")
+
+	_, err = NewLinter(nil, LintRule{Name: "image-tag-latest", Check: checkImageTagLatest})
+	assert.ErrorContains(t, err, "duplicate lint rule")
+}
+
+func TestLoadLintConfig_MissingFile(t *testing.T) {
+	t.Parallel()
+
+	config, err := LoadLintConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.NoError(t, err)
+	assert.Empty(t, config.Rules)
+}
@@ -2,6 +2,7 @@
 package registry
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -18,6 +19,15 @@ import (
 type Loader struct {
 	registryPath string
 	entries      map[string]*types.RegistryEntry
+	versions     map[string][]*EntryVersion
+	sources      map[string]*entrySource
+	diagnostics  Diagnostics
+}
+
+// entrySource records where an entry was loaded from so diagnostics can point back at it
+type entrySource struct {
+	path string
+	root *yaml.Node
 }
 
 // NewLoader creates a new registry loader
@@ -25,11 +35,30 @@ func NewLoader(registryPath string) *Loader {
 	return &Loader{
 		registryPath: registryPath,
 		entries:      make(map[string]*types.RegistryEntry),
+		versions:     make(map[string][]*EntryVersion),
+		sources:      make(map[string]*entrySource),
 	}
 }
 
-// LoadAll loads all registry entries from the registry directory
+// loadedSpec is a spec.yaml that was read during LoadAll, before name conflicts are resolved
+type loadedSpec struct {
+	dirName  string
+	key      string
+	path     string
+	entry    *types.RegistryEntry
+	versions []*EntryVersion
+	root     *yaml.Node
+	valid    bool
+}
+
+// LoadAll loads all registry entries from the registry directory.
+// Every spec.yaml is loaded and validated even if earlier ones fail; if any entry is invalid
+// a *ValidationError carrying all collected diagnostics is returned at the end.
 func (l *Loader) LoadAll() error {
+	l.diagnostics = nil
+
+	var specs []*loadedSpec
+
 	// Walk through the registry directory
 	err := filepath.Walk(l.registryPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -59,27 +88,227 @@ func (l *Loader) LoadAll() error {
 		specPath := filepath.Join(path, "spec.yaml")
 		if _, err := os.Stat(specPath); err == nil {
 			// Use directory name as the entry name
-			entryName := info.Name()
-
-			entry, err := l.LoadEntryWithName(specPath, entryName)
-			if err != nil {
-				return fmt.Errorf("failed to load %s: %w", specPath, err)
+			spec, diags := l.loadSpec(specPath, info.Name())
+			l.diagnostics = append(l.diagnostics, diags...)
+			if spec != nil {
+				// Broken entries are skipped so every problem is reported in one run
+				specs = append(specs, spec)
 			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	l.checkNames(specs)
+
+	for _, spec := range specs {
+		if spec.valid {
+			l.storeSpec(spec)
+		}
+	}
+
+	l.diagnostics.Sort()
+	return l.diagnostics.Err()
+}
+
+// loadSpec loads and validates a spec.yaml together with its version overlays.
+// The spec is nil if the file could not be read or parsed.
+func (l *Loader) loadSpec(specPath, dirName string) (*loadedSpec, Diagnostics) {
+	entry, root, diags := l.loadEntryDiagnostics(specPath, dirName)
+	if entry == nil {
+		return nil, diags
+	}
+
+	var versions []*EntryVersion
+	if !diags.HasErrors() {
+		var versionDiags Diagnostics
+		versions, versionDiags = l.loadEntryVersions(dirName, specPath, entry, root)
+		diags = append(diags, versionDiags...)
+	}
 
-			// Override with explicit name if set in the spec
-			if entry.GetName() != "" && entry.GetName() != entryName {
-				entryName = entry.GetName()
-			} else {
-				entry.SetName(entryName)
+	// The published key is the explicit name if set in the spec, otherwise the directory name
+	key := dirName
+	if entry.GetName() != "" {
+		key = entry.GetName()
+	}
+
+	return &loadedSpec{
+		dirName:  dirName,
+		key:      key,
+		path:     specPath,
+		entry:    entry,
+		versions: versions,
+		root:     root,
+		valid:    !diags.HasErrors(),
+	}, diags
+}
+
+// storeSpec makes a valid spec available under its key, publishing its latest version
+func (l *Loader) storeSpec(spec *loadedSpec) {
+	for _, version := range spec.versions {
+		if version.Entry.GetName() == "" {
+			version.Entry.SetName(spec.key)
+		}
+	}
+
+	entry := spec.entry
+	if latest := latestVersion(spec.versions); latest != nil {
+		entry = latest.Entry
+	}
+
+	l.entries[spec.key] = entry
+	l.versions[spec.key] = spec.versions
+	l.sources[spec.key] = &entrySource{path: spec.path, root: spec.root}
+}
+
+// removeSource drops every entry that was loaded from path
+func (l *Loader) removeSource(path string) string {
+	var removed string
+	for key, source := range l.sources {
+		if source.path == path {
+			removed = key
+			delete(l.entries, key)
+			delete(l.versions, key)
+			delete(l.sources, key)
+		}
+	}
+	return removed
+}
+
+// checkNames reports entries whose explicit name differs from their directory and
+// entries that claim a name already used by another spec, marking them invalid
+func (l *Loader) checkNames(specs []*loadedSpec) {
+	byKey := make(map[string][]*loadedSpec)
+	for _, spec := range specs {
+		byKey[spec.key] = append(byKey[spec.key], spec)
+	}
+
+	for _, spec := range specs {
+		if spec.key != spec.dirName {
+			line, column := locateField(spec.root, "name")
+			l.diagnostics = append(l.diagnostics, Diagnostic{
+				Entry:    spec.dirName,
+				Path:     spec.path,
+				Line:     line,
+				Column:   column,
+				Field:    "name",
+				Rule:     "name-mismatch",
+				Severity: SeverityError,
+				Message: fmt.Sprintf("name '%s' does not match directory name '%s'; rename the directory or the entry",
+					spec.key, spec.dirName),
+			})
+			spec.valid = false
+		}
+
+		claimants := byKey[spec.key]
+		if len(claimants) < 2 || spec.key == spec.dirName {
+			// The spec living in the directory of the same name owns the key
+			continue
+		}
+
+		var others []string
+		for _, other := range claimants {
+			if other != spec {
+				others = append(others, other.path)
 			}
+		}
+		line, column := locateField(spec.root, "name")
+		l.diagnostics = append(l.diagnostics, Diagnostic{
+			Entry:    spec.dirName,
+			Path:     spec.path,
+			Line:     line,
+			Column:   column,
+			Field:    "name",
+			Rule:     "duplicate-name",
+			Severity: SeverityError,
+			Message: fmt.Sprintf("name '%s' is also used by %s; each entry must have a unique name",
+				spec.key, strings.Join(others, ", ")),
+		})
+		spec.valid = false
+	}
+}
+
+// ReloadEntry reloads and revalidates the single spec.yaml at path and its version
+// overlays, updating the loaded entries in place, and returns the entry name and its
+// diagnostics. A removed spec drops its entry. An invalid spec keeps the previously
+// loaded version of the entry, so outputs are never built from a broken spec.
+func (l *Loader) ReloadEntry(path string) (string, Diagnostics) {
+	dirName := filepath.Base(filepath.Dir(path))
 
-			l.entries[entryName] = entry
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		name := dirName
+		if removed := l.removeSource(path); removed != "" {
+			name = removed
 		}
+		l.replaceDiagnostics(filepath.Dir(path), nil)
+		return name, nil
+	}
 
-		return nil
-	})
+	spec, diags := l.loadSpec(path, dirName)
+	if spec == nil {
+		l.replaceDiagnostics(filepath.Dir(path), diags)
+		return dirName, diags
+	}
 
-	return err
+	// Check the name against every other loaded entry, keeping only this spec's findings
+	specs := []*loadedSpec{spec}
+	for otherKey, source := range l.sources {
+		if source.path != path {
+			specs = append(specs, &loadedSpec{
+				dirName: filepath.Base(filepath.Dir(source.path)),
+				key:     otherKey,
+				path:    source.path,
+				root:    source.root,
+				valid:   true,
+			})
+		}
+	}
+	previous := l.diagnostics
+	l.diagnostics = nil
+	l.checkNames(specs)
+	for _, diag := range l.diagnostics {
+		if diag.Path == path {
+			diags = append(diags, diag)
+		}
+	}
+	l.diagnostics = previous
+	diags.Sort()
+	l.replaceDiagnostics(filepath.Dir(path), diags)
+
+	if !spec.valid {
+		return spec.key, diags
+	}
+
+	// The spec may have been renamed, so drop whatever it was loaded as before
+	l.removeSource(path)
+	l.storeSpec(spec)
+
+	return spec.key, diags
+}
+
+// hasSourcePath reports whether a loaded entry was read from path
+func (l *Loader) hasSourcePath(path string) bool {
+	for _, source := range l.sources {
+		if source.path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceDiagnostics replaces the collected diagnostics of the files in an entry directory
+func (l *Loader) replaceDiagnostics(dir string, diags Diagnostics) {
+	var kept Diagnostics
+	for _, diag := range l.diagnostics {
+		if diag.Path != filepath.Join(dir, "spec.yaml") && filepath.Dir(diag.Path) != filepath.Join(dir, VersionsDir) {
+			kept = append(kept, diag)
+		}
+	}
+	l.diagnostics = append(kept, diags...)
+	l.diagnostics.Sort()
 }
 
 // LoadEntry loads a single registry entry from a YAML file without validation
@@ -90,36 +319,102 @@ func (l *Loader) LoadEntry(path string) (*types.RegistryEntry, error) {
 
 // LoadEntryWithName loads a single registry entry from a YAML file with validation
 func (l *Loader) LoadEntryWithName(path string, name string) (*types.RegistryEntry, error) {
+	entry, _, diags := l.loadEntryDiagnostics(path, name)
+	if entry == nil {
+		return nil, diags.Err()
+	}
+	if err := diags.Err(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	return entry, nil
+}
+
+// loadEntryDiagnostics loads and validates a single entry, returning the parsed YAML node tree
+// and every problem found. The entry is nil if the file could not be read or parsed.
+func (*Loader) loadEntryDiagnostics(path string, name string) (*types.RegistryEntry, *yaml.Node, Diagnostics) {
+	fail := func(rule string, line int, format string, err error) Diagnostics {
+		return Diagnostics{{
+			Entry:    name,
+			Path:     path,
+			Line:     line,
+			Rule:     rule,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf(format, err),
+		}}
+	}
+
 	file, err := os.Open(path) // #nosec G304 - path is constructed from known directory structure
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %w", err)
+		return nil, nil, fail("read", 0, "failed to open file: %v", err)
 	}
 	defer file.Close()
 
 	data, err := io.ReadAll(file)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+		return nil, nil, fail("read", 0, "failed to read file: %v", err)
+	}
+
+	// Parse into a node tree first so diagnostics can point at line/column positions
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, nil, fail("yaml", yamlErrorLine(err), "failed to parse YAML: %v", err)
 	}
 
 	var entry types.RegistryEntry
-	if err := yaml.Unmarshal(data, &entry); err != nil {
-		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	if err := root.Decode(&entry); err != nil {
+		return nil, nil, fail("yaml", yamlErrorLine(err), "failed to parse YAML: %v", err)
 	}
 
 	// Validate with the actual name if provided
-	if err := l.validateEntry(&entry, name); err != nil {
-		return nil, fmt.Errorf("validation failed: %w", err)
+	diags := NewSchemaValidator().DiagnoseEntry(&entry, name)
+	for i := range diags {
+		diags[i].Path = path
+		diags[i].Line, diags[i].Column = locateField(&root, diags[i].Field)
 	}
 
-	return &entry, nil
+	return &entry, &root, diags
+}
+
+// Diagnostics returns the diagnostics collected by the last LoadAll call
+func (l *Loader) Diagnostics() Diagnostics {
+	return l.diagnostics
 }
 
-// validateEntry validates a registry entry using comprehensive schema-based validation
-func (*Loader) validateEntry(entry *types.RegistryEntry, name string) error {
-	// Use the new schema validator for comprehensive validation
-	validator := NewSchemaValidator()
+// GetEntryPath returns the spec.yaml path a loaded entry was read from, or "" if unknown
+func (l *Loader) GetEntryPath(name string) string {
+	if source, ok := l.sources[name]; ok {
+		return source.path
+	}
+	return ""
+}
 
-	return validator.ValidateComplete(entry, name)
+// Locate fills in the source path, line and column of diagnostics for loaded entries
+func (l *Loader) Locate(diags Diagnostics) {
+	for i := range diags {
+		source, ok := l.sources[diags[i].Entry]
+		if !ok {
+			continue
+		}
+		if diags[i].Path == "" {
+			diags[i].Path = source.path
+		}
+		if diags[i].Line == 0 {
+			diags[i].Line, diags[i].Column = locateField(source.root, diags[i].Field)
+		}
+	}
+}
+
+// GetVersions returns every version of an entry sorted by ascending version.
+// Entries without version overlays have a single version.
+func (l *Loader) GetVersions(name string) []*EntryVersion {
+	if versions, ok := l.versions[name]; ok && len(versions) > 0 {
+		return versions
+	}
+	entry, ok := l.entries[name]
+	if !ok {
+		return nil
+	}
+	return []*EntryVersion{{Version: entryVersion(entry), Entry: entry, Path: l.GetEntryPath(name), IsLatest: true}}
 }
 
 // GetEntries returns all loaded entries
@@ -50,15 +50,15 @@ tags:
 	assert.Len(t, entry.GetTools(), 2)
 }
 
-func TestLoader_ValidateEntry(t *testing.T) {
+func TestSchemaValidator_DiagnoseEntry(t *testing.T) {
 	t.Parallel()
-	loader := NewLoader("")
+	validator := NewSchemaValidator()
 
 	tests := []struct {
-		name    string
-		entry   *types.RegistryEntry
-		wantErr bool
-		errMsg  string
+		name      string
+		entry     *types.RegistryEntry
+		wantRules []string
+		errMsg    string
 	}{
 		{
 			name: "valid entry",
@@ -74,7 +74,6 @@ func TestLoader_ValidateEntry(t *testing.T) {
 					Image: "test/image:latest",
 				},
 			},
-			wantErr: false,
 		},
 		{
 			name: "missing image",
@@ -87,8 +86,8 @@ func TestLoader_ValidateEntry(t *testing.T) {
 					},
 				},
 			},
-			wantErr: true,
-			errMsg:  "description is required",
+			wantRules: []string{"image-required"},
+			errMsg:    "description is required",
 		},
 		{
 			name: "missing description",
@@ -101,8 +100,8 @@ func TestLoader_ValidateEntry(t *testing.T) {
 					Image: "test/image:latest",
 				},
 			},
-			wantErr: true,
-			errMsg:  "description is required",
+			wantRules: []string{"description-required"},
+			errMsg:    "description is required",
 		},
 		{
 			name: "missing transport",
@@ -115,8 +114,8 @@ func TestLoader_ValidateEntry(t *testing.T) {
 					Image: "test/image:latest",
 				},
 			},
-			wantErr: true,
-			errMsg:  "transport is required",
+			wantRules: []string{"transport-required"},
+			errMsg:    "transport is required",
 		},
 		{
 			name: "missing tools",
@@ -129,8 +128,8 @@ func TestLoader_ValidateEntry(t *testing.T) {
 					Image: "test/image:latest",
 				},
 			},
-			wantErr: true,
-			errMsg:  "at least one tool must be specified",
+			wantRules: []string{"tools-required"},
+			errMsg:    "at least one tool must be specified",
 		},
 		{
 			name: "invalid transport",
@@ -144,8 +143,8 @@ func TestLoader_ValidateEntry(t *testing.T) {
 					Image: "test/image:latest",
 				},
 			},
-			wantErr: true,
-			errMsg:  "schema validation failed",
+			wantRules: []string{"schema"},
+			errMsg:    "schema validation failed",
 		},
 		{
 			name: "invalid tier",
@@ -160,8 +159,8 @@ func TestLoader_ValidateEntry(t *testing.T) {
 					Image: "test/image:latest",
 				},
 			},
-			wantErr: true,
-			errMsg:  "schema validation failed",
+			wantRules: []string{"schema"},
+			errMsg:    "schema validation failed",
 		},
 		{
 			name: "invalid status",
@@ -176,22 +175,43 @@ func TestLoader_ValidateEntry(t *testing.T) {
 					Image: "test/image:latest",
 				},
 			},
-			wantErr: true,
-			errMsg:  "schema validation failed",
+			wantRules: []string{"schema"},
+			errMsg:    "schema validation failed",
+		},
+		{
+			name: "tool definition for unlisted tool",
+			entry: &types.RegistryEntry{
+				ImageMetadata: &toolhiveRegistry.ImageMetadata{
+					BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+						Description: "Test server",
+						Transport:   "stdio",
+						Tier:        types.TierOfficial,
+						Status:      types.StatusActive,
+						Tools:       []string{"test-tool"},
+					},
+					Image: "test/image:latest",
+				},
+				ToolDefinitions: []types.ToolDefinition{{Name: "test-tool"}, {Name: "other-tool"}},
+			},
+			wantRules: []string{"tool-definition-unknown"},
+			errMsg:    "tool definition 'other-tool' is not listed in tools",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			err := loader.validateEntry(tt.entry, "test-entry")
-			if tt.wantErr {
-				assert.Error(t, err)
-				if tt.errMsg != "" {
-					assert.Contains(t, err.Error(), tt.errMsg)
-				}
+			diags := validator.DiagnoseEntry(tt.entry, "test-entry")
+			var rules []string
+			for _, diag := range diags {
+				rules = append(rules, diag.Rule)
+				assert.Equal(t, "test-entry", diag.Entry)
+			}
+			assert.Subset(t, rules, tt.wantRules)
+			if tt.errMsg != "" {
+				assert.ErrorContains(t, diags.Err(), tt.errMsg)
 			} else {
-				assert.NoError(t, err)
+				assert.Empty(t, diags)
 			}
 		})
 	}
@@ -250,3 +270,139 @@ tools:
 	sortedEntries := loader.GetSortedEntries()
 	assert.Len(t, sortedEntries, 2)
 }
+
+func TestLoader_LoadAllCollectsDiagnostics(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+
+	entries := map[string]string{
+		"good": `description: Good server
+image: test/good:latest
+transport: stdio
+tier: Community
+status: Active
+tools:
+  - tool1`,
+		// Missing both description and tools
+		"broken": `image: test/broken:latest
+transport: stdio
+tier: Community
+status: Active`,
+		// Remote server using stdio, with an invalid tier
+		"remote": `url: https://example.com/mcp
+description: Remote server
+transport: stdio
+tier: Unknown
+status: Active
+tools:
+  - tool1`,
+		// Side effects that aren't valid or contradict the annotations
+		"effects": `description: Effects server
+image: test/effects:latest
+transport: stdio
+tier: Community
+status: Active
+tools:
+  - delete
+  - fetch
+  - search
+tool_definitions:
+  - name: delete
+    annotations:
+      destructiveHint: true
+    side_effect: read-only
+  - name: fetch
+    side_effect: harmless
+  - name: search
+    annotations:
+      openWorldHint: true
+    side_effect: unknown`,
+	}
+
+	for name, yamlContent := range entries {
+		dir := filepath.Join(tmpDir, name)
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "spec.yaml"), []byte(yamlContent), 0644))
+	}
+
+	loader := NewLoader(tmpDir)
+	err := loader.LoadAll()
+	require.Error(t, err)
+
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []string{"broken", "effects", "remote"}, validationErr.Diagnostics.Entries())
+
+	rules := make(map[string][]string)
+	for _, diag := range validationErr.Diagnostics {
+		rules[diag.Entry] = append(rules[diag.Entry], diag.Rule)
+		assert.Equal(t, filepath.Join(tmpDir, diag.Entry, "spec.yaml"), diag.Path)
+		assert.Equal(t, SeverityError, diag.Severity)
+	}
+	assert.ElementsMatch(t, []string{"description-required", "tools-required"}, rules["broken"])
+	assert.ElementsMatch(t, []string{"remote-transport", "schema"}, rules["remote"])
+	// Annotated tools take MCP's defaults for the hints they leave out, so search is destructive
+	assert.ElementsMatch(t, []string{"tool-side-effect-mismatch", "tool-side-effect-invalid", "tool-side-effect-mismatch"},
+		rules["effects"])
+
+	// Diagnostics point at the offending field in the source file
+	for _, diag := range validationErr.Diagnostics {
+		if diag.Entry == "remote" && diag.Rule == "remote-transport" {
+			assert.Equal(t, 3, diag.Line)
+			assert.Equal(t, 1, diag.Column)
+		}
+	}
+
+	// Valid entries are still loaded
+	assert.Contains(t, loader.GetEntries(), "good")
+	assert.Equal(t, validationErr.Diagnostics, loader.Diagnostics())
+}
+
+func TestLoader_LoadAllDetectsDuplicateNames(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+
+	spec := func(name string) string {
+		return `name: ` + name + `
+description: Test server
+image: test/server:latest
+transport: stdio
+tier: Community
+status: Active
+tools:
+  - tool1`
+	}
+
+	entries := map[string]string{
+		"heroku":            spec("heroku"),
+		"heroku-mcp-server": spec("heroku"),
+		"other":             spec("other"),
+	}
+	for name, yamlContent := range entries {
+		dir := filepath.Join(tmpDir, name)
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "spec.yaml"), []byte(yamlContent), 0644))
+	}
+
+	loader := NewLoader(tmpDir)
+	err := loader.LoadAll()
+
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+
+	rules := make(map[string]Diagnostic)
+	for _, diag := range validationErr.Diagnostics {
+		assert.Equal(t, "heroku-mcp-server", diag.Entry)
+		assert.Equal(t, 1, diag.Line)
+		rules[diag.Rule] = diag
+	}
+	require.Contains(t, rules, "duplicate-name")
+	require.Contains(t, rules, "name-mismatch")
+	assert.Contains(t, rules["duplicate-name"].Message, filepath.Join(tmpDir, "heroku", "spec.yaml"))
+	assert.Equal(t, filepath.Join(tmpDir, "heroku-mcp-server", "spec.yaml"), rules["duplicate-name"].Path)
+
+	// The entry living in the matching directory keeps the name and is not overwritten
+	loaded := loader.GetEntries()
+	assert.Len(t, loaded, 2)
+	assert.Equal(t, filepath.Join(tmpDir, "heroku", "spec.yaml"), loader.GetEntryPath("heroku"))
+}
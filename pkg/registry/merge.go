@@ -0,0 +1,235 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MergeStrategy decides how fields that changed both locally and upstream are resolved
+type MergeStrategy string
+
+const (
+	// MergeStrategyOurs keeps the local value of conflicting fields
+	MergeStrategyOurs MergeStrategy = "ours"
+	// MergeStrategyTheirs takes the upstream value of conflicting fields
+	MergeStrategyTheirs MergeStrategy = "theirs"
+	// MergeStrategyFail leaves the local spec untouched when there are conflicts
+	MergeStrategyFail MergeStrategy = "fail"
+)
+
+// ParseMergeStrategy parses a merge strategy flag value
+func ParseMergeStrategy(value string) (MergeStrategy, error) {
+	switch strategy := MergeStrategy(value); strategy {
+	case MergeStrategyOurs, MergeStrategyTheirs, MergeStrategyFail:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("invalid merge strategy %q: must be one of ours, theirs, fail", value)
+	}
+}
+
+// MergeConflict is a field that changed both locally and upstream
+type MergeConflict struct {
+	// Path is the dotted path of the field, e.g. permissions.network
+	Path string
+	// Ours is the local value, or "(removed)" if the field was deleted locally
+	Ours string
+	// Theirs is the upstream value, or "(removed)" if the field was deleted upstream
+	Theirs string
+}
+
+// String returns a one-line description of the conflict
+func (c MergeConflict) String() string {
+	return fmt.Sprintf("%s: local %s, upstream %s", c.Path, c.Ours, c.Theirs)
+}
+
+// MergeResult is the outcome of a three-way spec merge
+type MergeResult struct {
+	// Data is the merged spec.yaml. It is nil when the strategy is MergeStrategyFail and
+	// there are conflicts, and the unchanged local spec when nothing was taken from upstream.
+	Data []byte
+	// Updated lists the fields that were updated from upstream
+	Updated []string
+	// Conflicts lists the fields that changed on both sides, resolved with the strategy
+	Conflicts []MergeConflict
+}
+
+// MergeSpec three-way merges spec.yaml documents. Fields that only changed upstream since base
+// are updated, fields that only changed locally are kept, and fields that changed on both sides
+// are conflicts resolved with the strategy. Mappings are merged key by key; other values,
+// including lists, are compared as a whole. The local document's comments and key order are
+// preserved, and new upstream fields are appended. A nil base means there is no common
+// ancestor, so every field that differs between the two sides is a conflict.
+func MergeSpec(base, ours, theirs []byte, strategy MergeStrategy) (*MergeResult, error) {
+	oursDoc, err := parseSpecDocument(ours)
+	if err != nil {
+		return nil, fmt.Errorf("local spec: %w", err)
+	}
+	theirsDoc, err := parseSpecDocument(theirs)
+	if err != nil {
+		return nil, fmt.Errorf("upstream spec: %w", err)
+	}
+	var baseRoot *yaml.Node
+	if len(bytes.TrimSpace(base)) > 0 {
+		baseDoc, err := parseSpecDocument(base)
+		if err != nil {
+			return nil, fmt.Errorf("base spec: %w", err)
+		}
+		baseRoot = baseDoc.Content[0]
+	}
+
+	merger := &specMerger{strategy: strategy}
+	merged := merger.merge("", baseRoot, oursDoc.Content[0], theirsDoc.Content[0])
+	result := &MergeResult{Updated: merger.updated, Conflicts: merger.conflicts}
+	switch {
+	case len(result.Conflicts) > 0 && strategy == MergeStrategyFail:
+		return result, nil
+	case !merger.tookTheirs:
+		result.Data = ours
+		return result, nil
+	}
+
+	oursDoc.Content[0] = merged
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(oursDoc); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close YAML encoder: %w", err)
+	}
+	result.Data = buf.Bytes()
+	return result, nil
+}
+
+// parseSpecDocument parses a YAML document whose root is a mapping
+func parseSpecDocument(data []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected a YAML mapping")
+	}
+	return &doc, nil
+}
+
+// specMerger accumulates the updates and conflicts of a three-way merge
+type specMerger struct {
+	strategy   MergeStrategy
+	updated    []string
+	conflicts  []MergeConflict
+	tookTheirs bool
+}
+
+// merge returns the merged value of a field, or nil if the field is removed
+func (m *specMerger) merge(path string, base, ours, theirs *yaml.Node) *yaml.Node {
+	switch {
+	case equalNodes(ours, theirs), equalNodes(base, theirs):
+		return ours
+	case ours != nil && theirs != nil && ours.Kind == yaml.MappingNode && theirs.Kind == yaml.MappingNode:
+		return m.mergeMappings(path, base, ours, theirs)
+	case equalNodes(base, ours):
+		m.updated = append(m.updated, path)
+		m.tookTheirs = true
+		return withComments(theirs, ours)
+	}
+
+	m.conflicts = append(m.conflicts, MergeConflict{Path: path, Ours: describeNode(ours), Theirs: describeNode(theirs)})
+	if m.strategy == MergeStrategyTheirs {
+		m.tookTheirs = true
+		return withComments(theirs, ours)
+	}
+	return ours
+}
+
+// mergeMappings merges two mappings key by key, in local order followed by new upstream keys
+func (m *specMerger) mergeMappings(path string, base, ours, theirs *yaml.Node) *yaml.Node {
+	if base != nil && base.Kind != yaml.MappingNode {
+		base = nil
+	}
+
+	merged := *ours
+	merged.Content = nil
+	for i := 0; i+1 < len(ours.Content); i += 2 {
+		key := ours.Content[i]
+		value := m.merge(joinMergePath(path, key.Value),
+			mappingValue(base, key.Value), ours.Content[i+1], mappingValue(theirs, key.Value))
+		if value != nil {
+			merged.Content = append(merged.Content, key, value)
+		}
+	}
+	for i := 0; i+1 < len(theirs.Content); i += 2 {
+		key := theirs.Content[i]
+		if mappingValue(ours, key.Value) != nil {
+			continue
+		}
+		value := m.merge(joinMergePath(path, key.Value), mappingValue(base, key.Value), nil, theirs.Content[i+1])
+		if value != nil {
+			merged.Content = append(merged.Content, key, value)
+		}
+	}
+	return &merged
+}
+
+// joinMergePath appends a key to a dotted field path
+func joinMergePath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// equalNodes reports whether two nodes hold the same data, ignoring comments and style.
+// A nil node is a missing field and only equals another nil node.
+func equalNodes(a, b *yaml.Node) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	var aValue, bValue any
+	if err := a.Decode(&aValue); err != nil {
+		return false
+	}
+	if err := b.Decode(&bValue); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(aValue, bValue)
+}
+
+// withComments returns value with the comments of the node it replaces, where it has none
+func withComments(value, replaced *yaml.Node) *yaml.Node {
+	if value == nil || replaced == nil {
+		return value
+	}
+	result := *value
+	if result.HeadComment == "" {
+		result.HeadComment = replaced.HeadComment
+	}
+	if result.LineComment == "" {
+		result.LineComment = replaced.LineComment
+	}
+	if result.FootComment == "" {
+		result.FootComment = replaced.FootComment
+	}
+	return &result
+}
+
+// describeNode renders a field value on a single line for conflict reports
+func describeNode(node *yaml.Node) string {
+	if node == nil {
+		return "(removed)"
+	}
+	var value any
+	if err := node.Decode(&value); err != nil {
+		return node.Value
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return node.Value
+	}
+	return string(data)
+}
@@ -0,0 +1,183 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mergeTestBase = `name: server
+description: A server
+image: ghcr.io/example/server:1.0.0
+tools:
+  - read
+metadata:
+  stars: 10
+  pulls: 100
+`
+
+func TestMergeSpec(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		base      string
+		ours      string
+		theirs    string
+		strategy  MergeStrategy
+		want      string
+		updated   []string
+		conflicts []MergeConflict
+	}{
+		{
+			name:   "upstream change is applied and local comments are kept",
+			base:   mergeTestBase,
+			ours:   "# Curated entry\n" + mergeTestBase + "license: MIT # checked by hand\n",
+			theirs: "name: server\ndescription: A server\nimage: ghcr.io/example/server:1.1.0\ntools:\n  - read\nmetadata:\n  stars: 12\n  pulls: 100\n",
+			want: "# Curated entry\nname: server\ndescription: A server\nimage: ghcr.io/example/server:1.1.0\n" +
+				"tools:\n  - read\nmetadata:\n  stars: 12\n  pulls: 100\nlicense: MIT # checked by hand\n",
+			updated: []string{"image", "metadata.stars"},
+		},
+		{
+			name:   "local change wins when upstream is unchanged",
+			base:   mergeTestBase,
+			ours:   "description: Curated description\nname: server\nimage: ghcr.io/example/server:1.0.0\ntools:\n  - read\nmetadata:\n  stars: 10\n  pulls: 100\n",
+			theirs: mergeTestBase,
+			want:   "description: Curated description\nname: server\nimage: ghcr.io/example/server:1.0.0\ntools:\n  - read\nmetadata:\n  stars: 10\n  pulls: 100\n",
+		},
+		{
+			name:   "upstream additions and removals",
+			base:   mergeTestBase,
+			ours:   mergeTestBase,
+			theirs: "name: server\ndescription: A server\nimage: ghcr.io/example/server:1.0.0\ntools:\n  - read\ntags:\n  - new\n",
+			want: "name: server\ndescription: A server\nimage: ghcr.io/example/server:1.0.0\ntools:\n  - read\n" +
+				"tags:\n  - new\n",
+			updated: []string{"metadata", "tags"},
+		},
+		{
+			name:      "conflict keeps ours",
+			base:      mergeTestBase,
+			ours:      "name: server\ndescription: Ours\nimage: ghcr.io/example/server:1.0.0\ntools:\n  - read\n  - write\nmetadata:\n  stars: 10\n  pulls: 100\n",
+			theirs:    "name: server\ndescription: Theirs\nimage: ghcr.io/example/server:1.0.0\ntools:\n  - read\n  - write\nmetadata:\n  stars: 10\n  pulls: 100\n",
+			strategy:  MergeStrategyOurs,
+			want:      "name: server\ndescription: Ours\nimage: ghcr.io/example/server:1.0.0\ntools:\n  - read\n  - write\nmetadata:\n  stars: 10\n  pulls: 100\n",
+			conflicts: []MergeConflict{{Path: "description", Ours: `"Ours"`, Theirs: `"Theirs"`}},
+		},
+		{
+			name:      "conflict takes theirs",
+			base:      mergeTestBase,
+			ours:      "name: server\ndescription: Ours # note\nimage: ghcr.io/example/server:1.0.0\ntools:\n  - read\nmetadata:\n  stars: 10\n  pulls: 100\n",
+			theirs:    "name: server\ndescription: Theirs\nimage: ghcr.io/example/server:1.0.0\ntools:\n  - read\nmetadata:\n  stars: 10\n  pulls: 100\n",
+			strategy:  MergeStrategyTheirs,
+			want:      "name: server\ndescription: Theirs # note\nimage: ghcr.io/example/server:1.0.0\ntools:\n  - read\nmetadata:\n  stars: 10\n  pulls: 100\n",
+			conflicts: []MergeConflict{{Path: "description", Ours: `"Ours"`, Theirs: `"Theirs"`}},
+		},
+		{
+			name:     "conflict fails",
+			base:     mergeTestBase,
+			ours:     "name: server\ndescription: A server\nimage: ghcr.io/example/server:1.0.0\ntools:\n  - read\n",
+			theirs:   "name: server\ndescription: A server\nimage: ghcr.io/example/server:1.0.0\ntools:\n  - read\nmetadata:\n  stars: 11\n  pulls: 100\n",
+			strategy: MergeStrategyFail,
+			conflicts: []MergeConflict{
+				{Path: "metadata", Ours: "(removed)", Theirs: `{"pulls":100,"stars":11}`},
+			},
+		},
+		{
+			name:      "no base makes every difference a conflict",
+			ours:      "name: server\ndescription: Ours\nimage: ghcr.io/example/server:1.0.0\nlicense: MIT\n",
+			theirs:    "name: server\ndescription: Theirs\nimage: ghcr.io/example/server:1.0.0\ntools:\n  - read\n",
+			strategy:  MergeStrategyOurs,
+			want:      "name: server\ndescription: Ours\nimage: ghcr.io/example/server:1.0.0\nlicense: MIT\ntools:\n  - read\n",
+			updated:   []string{"tools"},
+			conflicts: []MergeConflict{{Path: "description", Ours: `"Ours"`, Theirs: `"Theirs"`}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var base []byte
+			if tt.base != "" {
+				base = []byte(tt.base)
+			}
+			result, err := MergeSpec(base, []byte(tt.ours), []byte(tt.theirs), tt.strategy)
+			require.NoError(t, err)
+			assert.Equal(t, tt.updated, result.Updated)
+			assert.Equal(t, tt.conflicts, result.Conflicts)
+			if tt.want == "" {
+				assert.Nil(t, result.Data)
+				return
+			}
+			assert.Equal(t, tt.want, string(result.Data))
+		})
+	}
+}
+
+func TestMergeSpec_UnchangedKeepsLocalFormatting(t *testing.T) {
+	t.Parallel()
+
+	ours := "name:   server\nimage: 'ghcr.io/example/server:1.0.0'\n\ntools: [read]\n"
+	result, err := MergeSpec([]byte(mergeTestBase), []byte(ours), []byte(mergeTestBase), MergeStrategyFail)
+	require.NoError(t, err)
+	assert.Equal(t, ours, string(result.Data))
+}
+
+func TestParseMergeStrategy(t *testing.T) {
+	t.Parallel()
+
+	strategy, err := ParseMergeStrategy("theirs")
+	require.NoError(t, err)
+	assert.Equal(t, MergeStrategyTheirs, strategy)
+
+	_, err = ParseMergeStrategy("force")
+	assert.ErrorContains(t, err, "invalid merge strategy")
+}
+
+func TestMergeImport(t *testing.T) {
+	t.Parallel()
+
+	entryDir := filepath.Join(t.TempDir(), "server")
+
+	// The first import creates the entry and records the base
+	spec, err := MergeImport(entryDir, "# header\n", []byte(mergeTestBase), MergeStrategyFail)
+	require.NoError(t, err)
+	assert.True(t, spec.Created)
+	require.NoError(t, spec.Write())
+
+	// Curate the entry locally
+	specPath := filepath.Join(entryDir, "spec.yaml")
+	local, err := os.ReadFile(specPath)
+	require.NoError(t, err)
+	local = append(local, []byte("license: MIT\n")...)
+	require.NoError(t, os.WriteFile(specPath, local, 0600))
+
+	// Re-importing the same spec keeps the local edit untouched
+	spec, err = MergeImport(entryDir, "# other header\n", []byte(mergeTestBase), MergeStrategyFail)
+	require.NoError(t, err)
+	assert.False(t, spec.Changed)
+	assert.Empty(t, spec.Updated)
+
+	// An upstream change is merged in
+	upstream := "name: server\ndescription: A server\nimage: ghcr.io/example/server:2.0.0\ntools:\n  - read\nmetadata:\n  stars: 10\n  pulls: 100\n"
+	spec, err = MergeImport(entryDir, "# other header\n", []byte(upstream), MergeStrategyFail)
+	require.NoError(t, err)
+	assert.True(t, spec.Changed)
+	assert.Equal(t, []string{"image"}, spec.Updated)
+	require.NoError(t, spec.Write())
+
+	data, err := os.ReadFile(specPath)
+	require.NoError(t, err)
+	assert.Equal(t, "# header\n"+upstream+"license: MIT\n", string(data))
+
+	// A local edit to a field that changes upstream is a conflict
+	require.NoError(t, os.WriteFile(specPath, []byte("# header\n"+upstream+"license: MIT\n"+"tags:\n  - local\n"), 0600))
+	conflicting := upstream + "tags:\n  - upstream\n"
+	spec, err = MergeImport(entryDir, "", []byte(conflicting), MergeStrategyFail)
+	require.ErrorIs(t, err, ErrMergeConflicts)
+	assert.Equal(t, []MergeConflict{{Path: "tags", Ours: `["local"]`, Theirs: `["upstream"]`}}, spec.Conflicts)
+	assert.Error(t, spec.Write())
+}
@@ -9,17 +9,21 @@ import (
 	"strings"
 	"time"
 
-	"github.com/google/uuid"
 	upstream "github.com/modelcontextprotocol/registry/pkg/api/v0"
 	"github.com/modelcontextprotocol/registry/pkg/model"
 	"github.com/xeipuuv/gojsonschema"
 
+	"github.com/stacklok/toolhive-registry/pkg/imageref"
 	"github.com/stacklok/toolhive-registry/pkg/types"
 )
 
+// placeholderRepositoryURL is the repository of remote servers that don't declare one
+const placeholderRepositoryURL = "https://github.com/stacklok/toolhive-registry"
+
 // OfficialRegistry handles building and writing the toolhive MCP registry based on the official server format
 type OfficialRegistry struct {
 	loader *Loader
+	clock  *BuildClock
 }
 
 // NewOfficialRegistry creates a new instance of the official registry
@@ -29,17 +33,28 @@ func NewOfficialRegistry(loader *Loader) *OfficialRegistry {
 	}
 }
 
+// SetClock sets the clock used for timestamps and server IDs (nil uses the current time and random IDs)
+func (or *OfficialRegistry) SetClock(clock *BuildClock) {
+	or.clock = clock
+}
+
+// Build validates all entries and creates the official MCP registry structure
+func (or *OfficialRegistry) Build() (*ToolHiveRegistryType, error) {
+	if err := or.validateEntries(); err != nil {
+		return nil, fmt.Errorf("entry validation failed: %w", err)
+	}
+	return or.build(), nil
+}
+
 // WriteJSON builds the official MCP registry and writes it to the specified path
 // Individual entries and the complete registry are validated before writing - generation fails if validation fails
 func (or *OfficialRegistry) WriteJSON(path string) error {
-	// Validate all entries first
-	if err := or.validateEntries(); err != nil {
-		return fmt.Errorf("entry validation failed: %w", err)
+	// Validate all entries and build the registry structure
+	registry, err := or.Build()
+	if err != nil {
+		return err
 	}
 
-	// Build the registry structure
-	registry := or.build()
-
 	// Validate the complete registry against schema (warnings only for now)
 	if err := or.validateRegistry(registry); err != nil {
 		fmt.Printf("⚠️  Schema validation warnings: %v\n", err)
@@ -57,8 +72,8 @@ func (or *OfficialRegistry) WriteJSON(path string) error {
 		return fmt.Errorf("failed to marshal JSON: %w", err)
 	}
 
-	// Write to file
-	if err := os.WriteFile(path, data, 0600); err != nil {
+	// Write to a temporary file and rename it into place
+	if err := writeFileAtomic(path, data, 0600); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
@@ -117,13 +132,13 @@ func (or *OfficialRegistry) validateEntries() error {
 	entries := or.loader.GetEntries()
 	validator := NewSchemaValidator()
 
+	var diags Diagnostics
 	for name, entry := range entries {
-		if err := validator.ValidateEntryFields(entry, name); err != nil {
-			return fmt.Errorf("entry '%s' validation failed: %w", name, err)
-		}
+		diags = append(diags, validator.DiagnoseEntryFields(entry, name)...)
 	}
+	diags.Sort()
 
-	return nil
+	return diags.Err()
 }
 
 // build creates the ToolHiveRegistryType structure from loaded entries
@@ -137,19 +152,20 @@ func (or *OfficialRegistry) build() *ToolHiveRegistryType {
 	}
 	sort.Strings(names)
 
-	// Transform entries to upstream.ServerJSON
+	// Transform every version of every entry to upstream.ServerJSON
 	var servers []upstream.ServerJSON
 	for _, name := range names {
-		entry := entries[name]
-		serverJSON := or.transformEntry(name, entry)
-		servers = append(servers, serverJSON)
+		for _, version := range or.loader.GetVersions(name) {
+			serverJSON := or.transformEntry(name, version)
+			servers = append(servers, serverJSON)
+		}
 	}
 
 	registry := &ToolHiveRegistryType{
 		Schema:  "https://raw.githubusercontent.com/stacklok/toolhive-registry/main/schemas/registry.schema.json",
 		Version: "1.0.0",
 		Meta: Meta{
-			LastUpdated: time.Now().UTC().Format(time.RFC3339),
+			LastUpdated: or.clock.BuildTime().Format(time.RFC3339),
 		},
 		Data: Data{
 			Servers: servers,
@@ -160,22 +176,26 @@ func (or *OfficialRegistry) build() *ToolHiveRegistryType {
 	return registry
 }
 
-// transformEntry converts a ToolHive RegistryEntry to an official MCP ServerJSON
-func (or *OfficialRegistry) transformEntry(name string, entry *types.RegistryEntry) upstream.ServerJSON {
+// transformEntry converts one version of a ToolHive RegistryEntry to an official MCP ServerJSON
+func (or *OfficialRegistry) transformEntry(name string, entryVersion *EntryVersion) upstream.ServerJSON {
+	entry := entryVersion.Entry
+	serverName := or.convertNameToReverseDNS(name)
+	version := entryVersion.Version
+
 	// Create the flattened server JSON with _meta extensions
 	serverJSON := upstream.ServerJSON{
-		Name:        or.convertNameToReverseDNS(name),
+		Name:        serverName,
 		Description: entry.GetDescription(),
 		Status:      or.convertStatus(entry.GetStatus()),
 		Repository:  or.createRepository(entry),
-		Version:     "1.0.0", // TODO: Default server version for now, fix this to use package/remote version
+		Version:     version,
 		Meta: &upstream.ServerMeta{
 			PublisherProvided: or.createXPublisherExtensions(entry),
 			// The registry extensions are not supposed to be set by us.
 			// They are generated by the registry system.
 			// We include them here so we can start using them in toolhive,
 			// and they are available when we support an official MCP registry.
-			Official: or.createRegistryExtensions(),
+			Official: or.createRegistryExtensions(name, serverName, version, entryVersion.IsLatest),
 		},
 	}
 
@@ -204,7 +224,7 @@ func (*OfficialRegistry) createRepository(entry *types.RegistryEntry) model.Repo
 
 	if repositoryURL == "" {
 		// Use a toolhive-registry placeholder URL to satisfy validation when no repository is available for remote servers
-		repositoryURL = "https://github.com/stacklok/toolhive-registry"
+		repositoryURL = placeholderRepositoryURL
 		if entry.IsRemote() {
 			return model.Repository{
 				URL:    repositoryURL,
@@ -242,13 +262,13 @@ func (*OfficialRegistry) createPackages(entry *types.RegistryEntry) []model.Pack
 		})
 	}
 
-	// Extract registry and version information from the image reference
-	registryBaseURL, identifier, version, err := parseImageReference(entry.Image)
-	if err != nil {
-		// Continue with fallback values
-		registryBaseURL = ""
-		identifier = entry.Image
-		version = ""
+	// Extract registry and version information from the image reference,
+	// falling back to the raw image if it can't be parsed
+	registryBaseURL, identifier, version := "", entry.Image, ""
+	if ref, err := imageref.Parse(entry.Image); err == nil {
+		registryBaseURL = "https://" + ref.Domain
+		identifier = ref.Repository()
+		version = ref.TagOrDigest()
 	}
 
 	// Determine transport type - use entry's transport or default to stdio for containers
@@ -291,6 +311,8 @@ func (*OfficialRegistry) createRemotes(entry *types.RegistryEntry) []model.Trans
 					Description: header.Description,
 					IsRequired:  header.Required,
 					IsSecret:    header.Secret,
+					Default:     header.Default,
+					Choices:     header.Choices,
 				},
 			},
 		})
@@ -306,13 +328,15 @@ func (*OfficialRegistry) createRemotes(entry *types.RegistryEntry) []model.Trans
 }
 
 // createRegistryExtensions creates registry-generated metadata
-func (*OfficialRegistry) createRegistryExtensions() *upstream.RegistryExtensions {
-	now := time.Now().UTC()
+func (or *OfficialRegistry) createRegistryExtensions(
+	name, serverName, version string, isLatest bool,
+) *upstream.RegistryExtensions {
+	published := or.clock.EntryTime(name)
 	return &upstream.RegistryExtensions{
-		ID:          uuid.NewString(),
-		PublishedAt: now,
-		UpdatedAt:   now,
-		IsLatest:    true,
+		ID:          or.clock.ServerID(serverName, version),
+		PublishedAt: published,
+		UpdatedAt:   published,
+		IsLatest:    isLatest,
 	}
 }
 
@@ -389,6 +413,14 @@ func (*OfficialRegistry) addImageSpecificExtensions(extensions map[string]interf
 		extensions["args"] = entry.Args
 	}
 
+	// Add the container port and additional image tags
+	if entry.TargetPort != 0 {
+		extensions["target_port"] = entry.TargetPort
+	}
+	if len(entry.DockerTags) > 0 {
+		extensions["docker_tags"] = entry.DockerTags
+	}
+
 	// Add metadata (stars, pulls, etc.)
 	if entry.ImageMetadata.Metadata != nil {
 		extensions["metadata"] = entry.ImageMetadata.Metadata
@@ -416,6 +448,16 @@ func (*OfficialRegistry) addRemoteSpecificExtensions(extensions map[string]inter
 		extensions["oauth_config"] = entry.OAuthConfig
 	}
 
+	// Add env vars, which remotes can't declare in the official format
+	if len(entry.RemoteServerMetadata.EnvVars) > 0 {
+		extensions["env_vars"] = entry.RemoteServerMetadata.EnvVars
+	}
+
+	// Add custom metadata (homepage, author, etc.)
+	if len(entry.RemoteServerMetadata.CustomMetadata) > 0 {
+		extensions["custom_metadata"] = entry.RemoteServerMetadata.CustomMetadata
+	}
+
 	// Add metadata
 	if entry.RemoteServerMetadata.Metadata != nil {
 		extensions["metadata"] = entry.RemoteServerMetadata.Metadata
@@ -433,6 +475,23 @@ func (*OfficialRegistry) addCommonExtensions(extensions map[string]interface{},
 	if entry.License != "" {
 		extensions["license"] = entry.License
 	}
+
+	// Add tool definitions and the side effects of the tools if present
+	if len(entry.ToolDefinitions) > 0 {
+		extensions["tool_definitions"] = entry.ToolDefinitions
+		extensions["tool_side_effects"] = entry.ToolSideEffects()
+	}
+
+	// Add resources, prompts and resource templates if present
+	if len(entry.Resources) > 0 {
+		extensions["resources"] = entry.Resources
+	}
+	if len(entry.Prompts) > 0 {
+		extensions["prompts"] = entry.Prompts
+	}
+	if len(entry.ResourceTemplates) > 0 {
+		extensions["resource_templates"] = entry.ResourceTemplates
+	}
 }
 
 // convertStatus converts ToolHive status to MCP model.Status
@@ -447,64 +506,6 @@ func (*OfficialRegistry) convertStatus(status string) model.Status {
 	}
 }
 
-// parseImageReference parses a container image reference into basic components
-// Returns error if registry has a port (not supported)
-func parseImageReference(image string) (registryBaseURL, identifier, version string, err error) {
-	// Check for port in registry (not supported)
-	if strings.Contains(image, ":") && strings.Count(image, ":") > 1 {
-		// Multiple colons might indicate registry:port/image:tag
-		parts := strings.Split(image, "/")
-		if len(parts) > 0 && strings.Contains(parts[0], ":") {
-			// First part has colon, likely registry:port
-			return "", "", "", fmt.Errorf("registry with port not supported: %s", parts[0])
-		}
-	}
-
-	// Handle digest (@sha256:...)
-	if strings.Contains(image, "@") {
-		parts := strings.SplitN(image, "@", 2)
-		imageRef := parts[0]
-		digest := parts[1]
-
-		reg, name := splitRegistryAndName(imageRef)
-		return reg, name, digest, nil
-	}
-
-	// Handle tag (:tag)
-	if strings.Contains(image, ":") {
-		parts := strings.SplitN(image, ":", 2)
-		imageRef := parts[0]
-		tag := parts[1]
-
-		reg, name := splitRegistryAndName(imageRef)
-		return reg, name, tag, nil
-	}
-
-	// No tag or digest - default to latest
-	reg, name := splitRegistryAndName(image)
-	return reg, name, "latest", nil
-}
-
-// splitRegistryAndName splits image into registry and name parts
-func splitRegistryAndName(image string) (registryBaseURL, identifier string) {
-	// No slash = Docker Hub image
-	if !strings.Contains(image, "/") {
-		return "https://docker.io", image
-	}
-
-	// Has slash - check if first part looks like registry
-	parts := strings.SplitN(image, "/", 2)
-	firstPart := parts[0]
-
-	// If first part has dot, assume it's a registry hostname
-	if strings.Contains(firstPart, ".") {
-		return "https://" + firstPart, parts[1]
-	}
-
-	// Otherwise assume Docker Hub with namespace
-	return "https://docker.io", image
-}
-
 // convertNameToReverseDNS converts simple server names to reverse-DNS format required by v1.0.0 schema
 func (*OfficialRegistry) convertNameToReverseDNS(name string) string {
 	// If already in reverse-DNS format (contains '/'), return as-is
@@ -513,5 +514,5 @@ func (*OfficialRegistry) convertNameToReverseDNS(name string) string {
 	}
 
 	// Convert simple names to toolhive namespace format
-	return "io.stacklok.toolhive/" + name
+	return toolhiveNamespace + name
 }
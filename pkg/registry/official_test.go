@@ -0,0 +1,54 @@
+package registry
+
+import (
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/imageref"
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func TestOfficialRegistry_CreatePackages(t *testing.T) {
+	t.Parallel()
+
+	digest := "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	tests := []struct {
+		image      string
+		baseURL    string
+		identifier string
+		version    string
+	}{
+		{"stacklok/server:1.0.0", "https://docker.io", "stacklok/server", "1.0.0"},
+		{"nginx", "https://docker.io", "nginx", "latest"},
+		{"ghcr.io/org/team/server:v0.2.0", "https://ghcr.io", "org/team/server", "v0.2.0"},
+		{"localhost/server:dev", "https://localhost", "server", "dev"},
+		{"registry.internal:5000/mcp/server:2.0.0", "https://registry.internal:5000", "mcp/server", "2.0.0"},
+		{"ghcr.io/org/server@" + digest, "https://ghcr.io", "org/server", digest},
+		{"registry.internal:5000/server:1.1.0@" + digest, "https://registry.internal:5000", "server", "1.1.0@" + digest},
+	}
+
+	official := NewOfficialRegistry(NewLoader(""))
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			t.Parallel()
+			packages := official.createPackages(&types.RegistryEntry{
+				ImageMetadata: &toolhiveRegistry.ImageMetadata{Image: tt.image},
+			})
+			require.Len(t, packages, 1)
+			assert.Equal(t, tt.baseURL, packages[0].RegistryBaseURL)
+			assert.Equal(t, tt.identifier, packages[0].Identifier)
+			assert.Equal(t, tt.version, packages[0].Version)
+
+			// The package converts back to the same image
+			want, err := imageref.Parse(tt.image)
+			require.NoError(t, err)
+			got, err := imageref.Parse(imageFromPackage(packages[0]))
+			require.NoError(t, err)
+			assert.Equal(t, want.Name(), got.Name())
+			assert.Equal(t, want.TagOrDigest(), got.TagOrDigest())
+		})
+	}
+}
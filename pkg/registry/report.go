@@ -0,0 +1,256 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	// ReportFormatText is the human-readable report format
+	ReportFormatText = "text"
+	// ReportFormatJSON is the machine-readable JSON report format
+	ReportFormatJSON = "json"
+	// ReportFormatSARIF is the SARIF 2.1.0 report format understood by GitHub code scanning
+	ReportFormatSARIF = "sarif"
+)
+
+// RuleDescriptions holds a short description for each diagnostic rule, used in SARIF output
+var RuleDescriptions = map[string]string{
+	"read":                 "Spec file could not be read",
+	"yaml":                 "Spec file is not valid YAML",
+	"server-type":          "Entry must be exactly one of an image or a remote server",
+	"image-required":       "Image-based servers must set image",
+	"url-required":         "Remote servers must set url",
+	"remote-transport":     "Remote servers cannot use the stdio transport",
+	"description-required": "Entries must have a description",
+	"transport-required":   "Entries must have a transport",
+	"tools-required":       "Entries must list at least one tool",
+	"schema":               "Entry must match the ToolHive registry schema",
+	"name-mismatch":        "Explicit entry name must match the spec directory name",
+	"duplicate-name":       "Entry names must be unique across spec directories",
+	"version-invalid":      "Versions and version overlay file names must be semantic versions",
+	"version-duplicate":    "Each version of an entry must be defined once",
+	"version-name":         "Version overlays cannot change the entry name",
+}
+
+// ValidationReport summarizes the result of validating a registry directory
+type ValidationReport struct {
+	// Valid is true if no error-severity diagnostics were found
+	Valid bool `json:"valid"`
+	// Entries is the number of registry entries that were checked
+	Entries int `json:"entries"`
+	// Errors is the number of error-severity diagnostics
+	Errors int `json:"errors"`
+	// Warnings is the number of warning-severity diagnostics
+	Warnings int `json:"warnings"`
+	// Diagnostics contains every problem found, sorted by file and position
+	Diagnostics Diagnostics `json:"diagnostics"`
+
+	// ruleDescriptions holds extra rule descriptions (e.g. lint rules) for SARIF output
+	ruleDescriptions map[string]string
+}
+
+// AddRuleDescriptions registers descriptions for rules that are not built-in validation rules
+func (r *ValidationReport) AddRuleDescriptions(descriptions map[string]string) {
+	if r.ruleDescriptions == nil {
+		r.ruleDescriptions = make(map[string]string)
+	}
+	for id, description := range descriptions {
+		r.ruleDescriptions[id] = description
+	}
+}
+
+// NewValidationReport creates a report for the given number of checked entries and their diagnostics
+func NewValidationReport(entries int, diags Diagnostics) *ValidationReport {
+	if diags == nil {
+		diags = Diagnostics{}
+	}
+	diags.Sort()
+	return &ValidationReport{
+		Valid:       !diags.HasErrors(),
+		Entries:     entries,
+		Errors:      diags.Count(SeverityError),
+		Warnings:    diags.Count(SeverityWarning),
+		Diagnostics: diags,
+	}
+}
+
+// WriteReport writes the report to w in the given format (text, json or sarif)
+func (r *ValidationReport) WriteReport(w io.Writer, format, toolVersion string) error {
+	switch format {
+	case ReportFormatText, "":
+		return r.WriteText(w)
+	case ReportFormatJSON:
+		return r.WriteJSON(w)
+	case ReportFormatSARIF:
+		return r.WriteSARIF(w, toolVersion)
+	default:
+		return fmt.Errorf("unknown report format: %s", format)
+	}
+}
+
+// WriteText writes one line per diagnostic followed by a summary
+func (r *ValidationReport) WriteText(w io.Writer) error {
+	for _, diag := range r.Diagnostics {
+		if _, err := fmt.Fprintln(w, diag.String()); err != nil {
+			return err
+		}
+	}
+	if r.Valid && len(r.Diagnostics) == 0 {
+		_, err := fmt.Fprintf(w, "✓ All %d registry entries are valid\n", r.Entries)
+		return err
+	}
+	if r.Valid {
+		_, err := fmt.Fprintf(w, "\n✓ No errors in %d registry entries (%d warning(s), %d info)\n",
+			r.Entries, r.Warnings, r.Diagnostics.Count(SeverityInfo))
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n✗ Found %d error(s) and %d warning(s) in %d registry entries\n",
+		r.Errors, r.Warnings, len(r.Diagnostics.Entries()))
+	return err
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *ValidationReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// sarifLog is the minimal subset of the SARIF 2.1.0 format needed for code scanning uploads
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// WriteSARIF writes the report as a SARIF 2.1.0 log suitable for GitHub code scanning
+func (r *ValidationReport) WriteSARIF(w io.Writer, toolVersion string) error {
+	ruleSet := make(map[string]bool)
+	results := make([]sarifResult, 0, len(r.Diagnostics))
+
+	for _, diag := range r.Diagnostics {
+		ruleSet[diag.Rule] = true
+
+		result := sarifResult{
+			RuleID:  diag.Rule,
+			Level:   sarifLevel(diag.Severity),
+			Message: sarifMessage{Text: diag.Message},
+		}
+		if diag.Path != "" {
+			location := sarifLocation{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(diag.Path)},
+				},
+			}
+			if diag.Line > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: diag.Line, StartColumn: diag.Column}
+			}
+			result.Locations = []sarifLocation{location}
+		}
+		results = append(results, result)
+	}
+
+	var ruleIDs []string
+	for id := range ruleSet {
+		ruleIDs = append(ruleIDs, id)
+	}
+	sort.Strings(ruleIDs)
+
+	rules := make([]sarifRule, 0, len(ruleIDs))
+	for _, id := range ruleIDs {
+		description := r.ruleDescriptions[id]
+		if description == "" {
+			description = RuleDescriptions[id]
+		}
+		if description == "" {
+			description = id
+		}
+		rules = append(rules, sarifRule{ID: id, ShortDescription: sarifMessage{Text: description}})
+	}
+
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "registry-builder",
+				Version:        toolVersion,
+				InformationURI: "https://github.com/stacklok/toolhive-registry",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
+
+// sarifLevel maps a diagnostic severity to a SARIF result level
+func sarifLevel(severity Severity) string {
+	switch severity {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "note"
+	default:
+		return "none"
+	}
+}
@@ -0,0 +1,56 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidationReport_WriteSARIF(t *testing.T) {
+	t.Parallel()
+	report := NewValidationReport(2, Diagnostics{
+		{Entry: "b", Path: "registry/b/spec.yaml", Line: 4, Column: 1, Rule: "tools-required",
+			Severity: SeverityError, Message: "at least one tool must be specified"},
+		{Entry: "a", Path: "registry/a/spec.yaml", Line: 2, Column: 3, Rule: "schema",
+			Severity: SeverityWarning, Message: "schema validation failed: bad tier"},
+	})
+
+	assert.False(t, report.Valid)
+	assert.Equal(t, 1, report.Errors)
+	assert.Equal(t, 1, report.Warnings)
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteReport(&buf, ReportFormatSARIF, "test"))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	require.Len(t, log.Runs, 1)
+	require.Len(t, log.Runs[0].Results, 2)
+
+	// Results are sorted by path so the output is stable
+	first := log.Runs[0].Results[0]
+	assert.Equal(t, "schema", first.RuleID)
+	assert.Equal(t, "warning", first.Level)
+	assert.Equal(t, "registry/a/spec.yaml", first.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, 2, first.Locations[0].PhysicalLocation.Region.StartLine)
+	assert.Equal(t, 3, first.Locations[0].PhysicalLocation.Region.StartColumn)
+
+	assert.Equal(t, "error", log.Runs[0].Results[1].Level)
+	assert.Len(t, log.Runs[0].Tool.Driver.Rules, 2)
+}
+
+func TestValidationReport_WriteJSON(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	require.NoError(t, NewValidationReport(3, nil).WriteReport(&buf, ReportFormatJSON, ""))
+
+	var report ValidationReport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+	assert.True(t, report.Valid)
+	assert.Equal(t, 3, report.Entries)
+	assert.NotNil(t, report.Diagnostics)
+	assert.Contains(t, buf.String(), `"diagnostics": []`)
+}
@@ -0,0 +1,168 @@
+package registry
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/stacklok/toolhive/pkg/permissions"
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"gopkg.in/yaml.v3"
+
+	"github.com/stacklok/toolhive-registry/pkg/imageref"
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+const (
+	// ScaffoldTargetPort is the container port of a new image server that uses an HTTP transport
+	ScaffoldTargetPort = 8080
+
+	scaffoldPlaceholderTool = "example_tool"
+	scaffoldDescriptionTODO = "TODO: describe what %s does in one sentence"
+	scaffoldEnvVarTODO      = "TODO: describe this variable, or remove it if the server needs no configuration"
+)
+
+// entryNamePattern matches the lowercase, hyphen-separated names of registry entries
+var entryNamePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// ScaffoldOptions holds the values given for a new registry entry. Exactly one of Image and
+// URL must be set; fields left empty get defaults or placeholders to fill in.
+type ScaffoldOptions struct {
+	Image         string
+	URL           string
+	Transport     string
+	Description   string
+	RepositoryURL string
+	TargetPort    int
+	Tools         []string
+}
+
+// NewEntry builds the registry entry for a new server. Tier and status get their defaults,
+// image servers get a permissions block that allows HTTPS, a target port if their transport
+// is HTTP and a placeholder env var, and missing descriptions and tools get placeholders that
+// pass validation.
+func NewEntry(name string, opts ScaffoldOptions) (*types.RegistryEntry, error) {
+	if !entryNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid entry name '%s': use lowercase letters, digits and hyphens", name)
+	}
+
+	description := opts.Description
+	if description == "" {
+		description = fmt.Sprintf(scaffoldDescriptionTODO, name)
+	}
+	tools := opts.Tools
+	if len(tools) == 0 {
+		tools = []string{scaffoldPlaceholderTool}
+	}
+	envVars := []*toolhiveRegistry.EnvVar{{
+		Name:        strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_API_KEY",
+		Description: scaffoldEnvVarTODO,
+		Secret:      true,
+	}}
+	base := toolhiveRegistry.BaseServerMetadata{
+		Description:   description,
+		Transport:     opts.Transport,
+		Tools:         tools,
+		RepositoryURL: opts.RepositoryURL,
+	}
+
+	var entry types.RegistryEntry
+	switch {
+	case opts.Image != "" && opts.URL != "":
+		return nil, fmt.Errorf("set either an image or a URL, not both")
+	case opts.Image != "":
+		if _, err := imageref.Parse(opts.Image); err != nil {
+			return nil, err
+		}
+		if base.Transport == "" {
+			base.Transport = "stdio"
+		}
+		entry.ImageMetadata = &toolhiveRegistry.ImageMetadata{
+			BaseServerMetadata: base,
+			Image:              opts.Image,
+			Permissions:        scaffoldPermissions(),
+			EnvVars:            envVars,
+		}
+		if base.Transport != "stdio" {
+			entry.TargetPort = opts.TargetPort
+			if entry.TargetPort == 0 {
+				entry.TargetPort = ScaffoldTargetPort
+			}
+		}
+	case opts.URL != "":
+		if base.Transport == "" {
+			base.Transport = "streamable-http"
+		}
+		entry.RemoteServerMetadata = &toolhiveRegistry.RemoteServerMetadata{
+			BaseServerMetadata: base,
+			URL:                opts.URL,
+			EnvVars:            envVars,
+		}
+	default:
+		return nil, fmt.Errorf("set an image or a URL for the new entry")
+	}
+
+	entry.SetDefaults()
+	return &entry, nil
+}
+
+// scaffoldPermissions allows HTTPS connections; the hosts are left for the author to list.
+// Permission profiles only govern outbound access, which doesn't depend on the transport:
+// the port an HTTP server listens on is its target port.
+func scaffoldPermissions() *permissions.Profile {
+	return &permissions.Profile{
+		Network: &permissions.NetworkPermissions{
+			Outbound: &permissions.OutboundNetworkPermissions{AllowPort: []int{443}},
+		},
+	}
+}
+
+// ScaffoldSpec encodes a new entry as spec.yaml in the canonical format, with comments on the
+// placeholders left to fill in, and validates the result as the loader would
+func ScaffoldSpec(name string, entry *types.RegistryEntry) ([]byte, error) {
+	node, err := SpecNode(entry)
+	if err != nil {
+		return nil, err
+	}
+	commentPlaceholders(node, name)
+
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	data, err = FormatSpec(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var check types.RegistryEntry
+	if err := yaml.Unmarshal(data, &check); err != nil {
+		return nil, fmt.Errorf("failed to parse generated spec: %w", err)
+	}
+	if err := NewSchemaValidator().ValidateComplete(&check, name); err != nil {
+		return nil, fmt.Errorf("generated spec is not valid: %w", err)
+	}
+	return data, nil
+}
+
+// commentPlaceholders adds a comment above each field of a new spec that still holds a placeholder
+func commentPlaceholders(node *yaml.Node, name string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		switch key.Value {
+		case "description":
+			if value.Value == fmt.Sprintf(scaffoldDescriptionTODO, name) {
+				key.HeadComment = "# Replace with a short description of the server"
+			}
+		case "tools":
+			if len(value.Content) == 1 && value.Content[0].Value == scaffoldPlaceholderTool {
+				key.HeadComment = "# Replace with the tools the server provides"
+			}
+		case "permissions":
+			key.HeadComment = "# List the hosts the server connects to under network.outbound.allow_host"
+		case "env_vars":
+			key.HeadComment = "# Replace with the environment variables the server reads"
+		default:
+		}
+	}
+}
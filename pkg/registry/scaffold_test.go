@@ -0,0 +1,128 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEntry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("image server with stdio transport", func(t *testing.T) {
+		t.Parallel()
+
+		entry, err := NewEntry("my-server", ScaffoldOptions{Image: "ghcr.io/example/my-server:1.0.0"})
+		require.NoError(t, err)
+		require.True(t, entry.IsImage())
+		assert.Equal(t, "stdio", entry.GetTransport())
+		assert.Equal(t, "Community", entry.GetTier())
+		assert.Equal(t, "Active", entry.GetStatus())
+		assert.Equal(t, []string{scaffoldPlaceholderTool}, entry.GetTools())
+		assert.Zero(t, entry.TargetPort)
+		assert.Equal(t, []int{443}, entry.Permissions.Network.Outbound.AllowPort)
+		require.Len(t, entry.ImageMetadata.EnvVars, 1)
+		assert.Equal(t, "MY_SERVER_API_KEY", entry.ImageMetadata.EnvVars[0].Name)
+		assert.True(t, entry.ImageMetadata.EnvVars[0].Secret)
+	})
+
+	t.Run("image server with HTTP transport gets a target port", func(t *testing.T) {
+		t.Parallel()
+
+		entry, err := NewEntry("web", ScaffoldOptions{Image: "ghcr.io/example/web:1.0.0", Transport: "sse"})
+		require.NoError(t, err)
+		assert.Equal(t, ScaffoldTargetPort, entry.TargetPort)
+	})
+
+	t.Run("remote server", func(t *testing.T) {
+		t.Parallel()
+
+		entry, err := NewEntry("remote", ScaffoldOptions{
+			URL:         "https://mcp.example.com/mcp",
+			Description: "Example remote server",
+			Tools:       []string{"search"},
+		})
+		require.NoError(t, err)
+		require.True(t, entry.IsRemote())
+		assert.Equal(t, "streamable-http", entry.GetTransport())
+		assert.Equal(t, "Example remote server", entry.GetDescription())
+		assert.Equal(t, []string{"search"}, entry.GetTools())
+	})
+
+	for name, tc := range map[string]struct {
+		entryName string
+		opts      ScaffoldOptions
+		wantErr   string
+	}{
+		"invalid name":      {"My_Server", ScaffoldOptions{Image: "ghcr.io/example/server:1.0.0"}, "invalid entry name"},
+		"no image or URL":   {"server", ScaffoldOptions{}, "set an image or a URL"},
+		"image and URL":     {"server", ScaffoldOptions{Image: "ghcr.io/example/server:1.0.0", URL: "https://x"}, "not both"},
+		"invalid image ref": {"server", ScaffoldOptions{Image: "ghcr.io/Example/server:1.0.0"}, "ghcr.io/Example"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewEntry(tc.entryName, tc.opts)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestScaffoldSpec(t *testing.T) {
+	t.Parallel()
+
+	entry, err := NewEntry("my-server", ScaffoldOptions{Image: "ghcr.io/example/my-server:1.0.0"})
+	require.NoError(t, err)
+	data, err := ScaffoldSpec("my-server", entry)
+	require.NoError(t, err)
+
+	assert.Equal(t, `image: ghcr.io/example/my-server:1.0.0
+# Replace with a short description of the server
+description: 'TODO: describe what my-server does in one sentence'
+transport: stdio
+tier: Community
+status: Active
+# Replace with the tools the server provides
+tools:
+  - example_tool
+# Replace with the environment variables the server reads
+env_vars:
+  - name: MY_SERVER_API_KEY
+    description: 'TODO: describe this variable, or remove it if the server needs no configuration'
+    required: false
+    secret: true
+# List the hosts the server connects to under network.outbound.allow_host
+permissions:
+  network:
+    outbound:
+      allow_port:
+        - 443
+`, string(data))
+
+	formatted, err := FormatSpec(data)
+	require.NoError(t, err)
+	assert.Equal(t, string(data), string(formatted), "scaffold must already be formatted")
+
+	// The scaffold loads cleanly as part of a registry
+	registryPath := t.TempDir()
+	specPath := filepath.Join(registryPath, "my-server", "spec.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(specPath), 0750))
+	require.NoError(t, os.WriteFile(specPath, data, 0600))
+	loader := NewLoader(registryPath)
+	require.NoError(t, loader.LoadAll())
+	assert.Empty(t, loader.Diagnostics())
+}
+
+func TestScaffoldSpec_Invalid(t *testing.T) {
+	t.Parallel()
+
+	entry, err := NewEntry("remote", ScaffoldOptions{URL: "https://mcp.example.com/mcp", Transport: "stdio"})
+	require.NoError(t, err)
+	_, err = ScaffoldSpec("remote", entry)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "remote servers cannot use stdio transport")
+}
@@ -4,6 +4,9 @@ package registry
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"slices"
+	"strings"
 
 	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
 
@@ -84,59 +87,212 @@ func (*SchemaValidator) convertToToolhiveRegistry(entry *types.RegistryEntry, na
 	return registry, nil
 }
 
-// ValidateEntryFields performs additional field-level validation beyond schema validation
-func (*SchemaValidator) ValidateEntryFields(entry *types.RegistryEntry, name string) error {
+// ValidateEntryFields performs additional field-level validation beyond schema validation.
+// All problems are reported together in a single *ValidationError.
+func (v *SchemaValidator) ValidateEntryFields(entry *types.RegistryEntry, name string) error {
+	return v.DiagnoseEntryFields(entry, name).Err()
+}
+
+// DiagnoseEntryFields collects every field-level problem in an entry instead of stopping at the first one
+func (*SchemaValidator) DiagnoseEntryFields(entry *types.RegistryEntry, name string) Diagnostics {
+	var diags Diagnostics
+	add := func(rule, field, format string, args ...any) {
+		diags = append(diags, Diagnostic{
+			Entry:    name,
+			Field:    field,
+			Rule:     rule,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
 	// Basic type validation
 	if entry.ImageMetadata == nil && entry.RemoteServerMetadata == nil {
-		return fmt.Errorf("entry '%s' must be either an image or remote server", name)
+		diags = append(diags, Diagnostic{
+			Entry:    name,
+			Rule:     "server-type",
+			Severity: SeverityError,
+			Message:  "must be either an image or remote server",
+		})
 	}
 
 	if entry.ImageMetadata != nil && entry.RemoteServerMetadata != nil {
-		return fmt.Errorf("entry '%s' cannot be both image and remote server", name)
+		diags = append(diags, Diagnostic{
+			Entry:    name,
+			Rule:     "server-type",
+			Severity: SeverityError,
+			Message:  "cannot be both image and remote server",
+		})
 	}
 
 	// Image-specific validation
-	if entry.IsImage() {
-		if entry.Image == "" {
-			return fmt.Errorf("entry '%s': image field is required for image-based servers", name)
-		}
+	if entry.ImageMetadata != nil && entry.Image == "" {
+		add("image-required", "image", "image field is required for image-based servers")
 	}
 
 	// Remote-specific validation
-	if entry.IsRemote() {
+	if entry.RemoteServerMetadata != nil {
 		if entry.URL == "" {
-			return fmt.Errorf("entry '%s': url field is required for remote servers", name)
+			add("url-required", "url", "url field is required for remote servers")
 		}
 
 		// Remote servers cannot use stdio transport
-		if entry.GetTransport() == "stdio" {
-			return fmt.Errorf("entry '%s': remote servers cannot use stdio transport (use sse or streamable-http)", name)
+		if entry.RemoteServerMetadata.Transport == "stdio" {
+			add("remote-transport", "transport", "remote servers cannot use stdio transport (use sse or streamable-http)")
 		}
 	}
 
 	// Common field validation
 	if entry.GetDescription() == "" {
-		return fmt.Errorf("entry '%s': description is required", name)
+		add("description-required", "description", "description is required")
 	}
 
 	if entry.GetTransport() == "" {
-		return fmt.Errorf("entry '%s': transport is required", name)
+		add("transport-required", "transport", "transport is required")
 	}
 
 	if len(entry.GetTools()) == 0 {
-		return fmt.Errorf("entry '%s': at least one tool must be specified", name)
+		add("tools-required", "tools", "at least one tool must be specified")
 	}
 
-	return nil
+	diags = append(diags, diagnoseToolDefinitions(entry, name)...)
+
+	if entry.Version != "" && !isSemver(entry.Version) {
+		add("version-invalid", "version", "version '%s' is not a semantic version (e.g. 1.2.3)", entry.Version)
+	}
+
+	return diags
+}
+
+// diagnoseToolDefinitions checks that every tool definition is for a listed tool and has a
+// valid side effect
+func diagnoseToolDefinitions(entry *types.RegistryEntry, name string) Diagnostics {
+	var diags Diagnostics
+	tools := entry.GetTools()
+	for _, definition := range entry.ToolDefinitions {
+		if !slices.Contains(tools, definition.Name) {
+			diags = append(diags, Diagnostic{
+				Entry:    name,
+				Field:    "tool_definitions",
+				Rule:     "tool-definition-unknown",
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("tool definition '%s' is not listed in tools", definition.Name),
+			})
+		}
+		diags = append(diags, diagnoseSideEffect(name, definition)...)
+	}
+	return diags
+}
+
+// diagnoseSideEffect checks the side effect of a tool definition. Tools without annotations
+// may be classified by hand, but a classification must not contradict the annotations.
+func diagnoseSideEffect(name string, definition types.ToolDefinition) Diagnostics {
+	var message, rule string
+	switch {
+	case definition.SideEffect == "":
+		return nil
+	case !slices.Contains(types.SideEffects, definition.SideEffect):
+		rule = "tool-side-effect-invalid"
+		message = fmt.Sprintf("side effect '%s' of tool '%s' must be one of: %s",
+			definition.SideEffect, definition.Name, strings.Join(types.SideEffects, ", "))
+	case len(definition.Annotations) > 0 && definition.SideEffect != types.ClassifyTool(definition.Annotations):
+		rule = "tool-side-effect-mismatch"
+		message = fmt.Sprintf("side effect '%s' of tool '%s' contradicts its annotations, which make it %s",
+			definition.SideEffect, definition.Name, types.ClassifyTool(definition.Annotations))
+	default:
+		return nil
+	}
+	return Diagnostics{{
+		Entry:    name,
+		Field:    "tool_definitions",
+		Rule:     rule,
+		Severity: SeverityError,
+		Message:  message,
+	}}
+}
+
+// DiagnoseEntrySchema validates an entry against the toolhive schema and reports each schema violation separately
+func (v *SchemaValidator) DiagnoseEntrySchema(entry *types.RegistryEntry, name string) Diagnostics {
+	err := v.ValidateEntry(entry, name)
+	if err == nil {
+		return nil
+	}
+	return schemaErrorDiagnostics(err, name)
+}
+
+// DiagnoseEntry collects both field-level and schema diagnostics for an entry
+func (v *SchemaValidator) DiagnoseEntry(entry *types.RegistryEntry, name string) Diagnostics {
+	diags := v.DiagnoseEntryFields(entry, name)
+
+	// Schema validation needs a well-formed entry type to build the wrapper registry
+	if !entry.IsImage() && !entry.IsRemote() {
+		return diags
+	}
+
+	// Skip schema violations for fields that already have a more specific field diagnostic
+	reported := make(map[string]bool)
+	for _, diag := range diags {
+		reported[diag.Field] = true
+	}
+	for _, diag := range v.DiagnoseEntrySchema(entry, name) {
+		if diag.Field == "" || !reported[diag.Field] {
+			diags = append(diags, diag)
+		}
+	}
+
+	return diags
 }
 
 // ValidateComplete performs both schema validation and field validation
 func (v *SchemaValidator) ValidateComplete(entry *types.RegistryEntry, name string) error {
-	// First perform field validation
-	if err := v.ValidateEntryFields(entry, name); err != nil {
-		return err
+	return v.DiagnoseEntry(entry, name).Err()
+}
+
+// schemaLocationPattern matches the instance location suffix added by toolhive's schema validator
+var schemaLocationPattern = regexp.MustCompile(`^(?:\d+\.\s+)?(.*?)(?: at '([^']*)')?$`)
+
+// schemaErrorDiagnostics splits a toolhive schema validation error into one diagnostic per violation.
+// With an empty name, each violation is reported for the server its location points into.
+func schemaErrorDiagnostics(err error, name string) Diagnostics {
+	var diags Diagnostics
+	for _, line := range strings.Split(err.Error(), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasSuffix(line, "errors:") {
+			continue
+		}
+		// Strip the wrapping prefixes so only the violation itself remains
+		if idx := strings.LastIndex(line, "registry schema validation failed: "); idx >= 0 {
+			line = line[idx+len("registry schema validation failed: "):]
+		}
+
+		message, entry, field := line, name, ""
+		if match := schemaLocationPattern.FindStringSubmatch(line); match != nil {
+			var server string
+			message = match[1]
+			server, field = splitSchemaLocation(match[2])
+			if entry == "" {
+				entry = server
+			}
+		}
+
+		diags = append(diags, Diagnostic{
+			Entry:    entry,
+			Field:    field,
+			Rule:     "schema",
+			Severity: SeverityError,
+			Message:  "schema validation failed: " + message,
+		})
 	}
+	return diags
+}
 
-	// Then perform schema validation
-	return v.ValidateEntry(entry, name)
+// splitSchemaLocation splits a JSON pointer such as "/servers/foo/env_vars/0/name" into the
+// server name "foo" and the dotted spec field path "env_vars.0.name"
+func splitSchemaLocation(location string) (server, field string) {
+	parts := strings.Split(strings.TrimPrefix(location, "/"), "/")
+	// Drop the "servers/<name>" or "remote_servers/<name>" wrapper added for validation
+	if len(parts) >= 2 && (parts[0] == "servers" || parts[0] == "remote_servers") {
+		server, parts = parts[1], parts[2:]
+	}
+	return server, strings.Join(parts, ".")
 }
@@ -0,0 +1,548 @@
+package registry
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	upstream "github.com/modelcontextprotocol/registry/pkg/api/v0"
+	"github.com/modelcontextprotocol/registry/pkg/model"
+	"github.com/stacklok/toolhive/pkg/permissions"
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+
+	"github.com/stacklok/toolhive-registry/pkg/imageref"
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// toolhiveNamespace is the reverse-DNS prefix used for server names in the official format
+const toolhiveNamespace = "io.stacklok.toolhive/"
+
+// LoadEntriesFrom loads registry entries from a registry directory, a built ToolHive
+// registry.json or a built official-registry.json, keyed by entry name
+func LoadEntriesFrom(path string) (map[string]*types.RegistryEntry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access %s: %w", path, err)
+	}
+
+	if info.IsDir() {
+		loader := NewLoader(path)
+		if err := loader.LoadAll(); err != nil {
+			return nil, fmt.Errorf("failed to load registry entries from %s: %w", path, err)
+		}
+		return loader.GetEntries(), nil
+	}
+
+	data, err := os.ReadFile(path) // #nosec G304 - path comes from command line argument
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return ParseRegistryJSON(data)
+}
+
+// LoadServersFrom loads official-format servers from a registry directory, a built ToolHive
+// registry.json or a built official-registry.json. Servers from an official-registry.json are
+// returned as-is; all other sources are converted with reproducible IDs and timestamps.
+func LoadServersFrom(path string) ([]upstream.ServerJSON, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access %s: %w", path, err)
+	}
+
+	if !info.IsDir() {
+		data, err := os.ReadFile(path) // #nosec G304 - path comes from command line argument
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		var official ToolHiveRegistryType
+		if err := json.Unmarshal(data, &official); err == nil && len(official.Data.Servers) > 0 {
+			return official.Data.Servers, nil
+		}
+	}
+
+	loader := NewLoader(path)
+	if info.IsDir() {
+		if err := loader.LoadAll(); err != nil {
+			return nil, fmt.Errorf("failed to load registry entries from %s: %w", path, err)
+		}
+	} else {
+		entries, err := LoadEntriesFrom(path)
+		if err != nil {
+			return nil, err
+		}
+		loader.entries = entries
+	}
+
+	clock, err := NewBuildClock(loader, true)
+	if err != nil {
+		return nil, err
+	}
+	return BuildOfficialServers(loader, clock)
+}
+
+// BuildOfficialServers converts the entries of a loader into official-format servers.
+// With a reproducible clock, server IDs stay stable across rebuilds.
+func BuildOfficialServers(loader *Loader, clock *BuildClock) ([]upstream.ServerJSON, error) {
+	official := NewOfficialRegistry(loader)
+	official.SetClock(clock)
+	registry, err := official.Build()
+	if err != nil {
+		return nil, err
+	}
+	return registry.Data.Servers, nil
+}
+
+// LoadEntriesAtRevision loads the registry directory as it was at a git revision.
+// The directory is exported with git archive into a temporary directory, so the
+// working tree is left untouched. Entries that don't load at that revision, for example
+// because they predate a validation rule, are skipped and their names returned.
+func LoadEntriesAtRevision(registryPath, revision string) (map[string]*types.RegistryEntry, []string, error) {
+	repoCmd := exec.Command("git", "rev-parse", "--show-toplevel", "--show-prefix") // #nosec G204 - fixed command
+	repoCmd.Dir = registryPath
+	output, err := repoCmd.Output()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s is not inside a git repository: %w", registryPath, err)
+	}
+	lines := strings.Split(strings.TrimRight(string(output), "\n"), "\n")
+	topLevel, prefix := lines[0], ""
+	if len(lines) > 1 {
+		prefix = lines[1]
+	}
+
+	tree, err := resolveRevisionTree(topLevel, revision)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Archive the tree of the registry directory itself from the repository root.
+	// #nosec G204 - tree is a resolved object name
+	cmd := exec.Command("git", "archive", "--format=tar", tree+":"+prefix)
+	cmd.Dir = topLevel
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	archive, err := cmd.Output()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to export %s at revision %s: %w: %s",
+			registryPath, revision, err, strings.TrimSpace(stderr.String()))
+	}
+
+	tempDir, err := os.MkdirTemp("", "registry-"+filepath.Base(registryPath)+"-")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(tempDir)
+	}()
+
+	if err := extractTar(bytes.NewReader(archive), tempDir); err != nil {
+		return nil, nil, fmt.Errorf("failed to extract %s at revision %s: %w", registryPath, revision, err)
+	}
+
+	loader := NewLoader(tempDir)
+	var validationErr *ValidationError
+	if err := loader.LoadAll(); err != nil && !errors.As(err, &validationErr) {
+		return nil, nil, fmt.Errorf("failed to load registry entries from %s at revision %s: %w",
+			registryPath, revision, err)
+	}
+	return loader.GetEntries(), invalidEntries(loader.Diagnostics()), nil
+}
+
+// resolveRevisionTree resolves a revision to the object name of its tree, so that only
+// commits, tags and trees are accepted and the revision is never read as an option
+func resolveRevisionTree(topLevel, revision string) (string, error) {
+	if revision == "" || strings.HasPrefix(revision, "-") {
+		return "", fmt.Errorf("invalid git revision %q", revision)
+	}
+
+	// #nosec G204 - revision is passed after --end-of-options
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", "--end-of-options", revision+"^{tree}")
+	cmd.Dir = topLevel
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s is not a git revision: %w", revision, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// invalidEntries returns the sorted names of the entries with error diagnostics
+func invalidEntries(diags Diagnostics) []string {
+	var errs Diagnostics
+	for _, diag := range diags {
+		if diag.Severity == SeverityError {
+			errs = append(errs, diag)
+		}
+	}
+	return errs.Entries()
+}
+
+// extractTar extracts regular files and directories from a tar stream into dir
+func extractTar(r io.Reader, dir string) error {
+	reader := tar.NewReader(r)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, filepath.Clean(header.Name)) // #nosec G305 - checked below
+		if target == filepath.Clean(dir) {
+			continue
+		}
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid path in archive: %s", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0750); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+				return err
+			}
+			file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600) // #nosec G304 - path checked above
+			if err != nil {
+				return err
+			}
+			_, copyErr := io.Copy(file, io.LimitReader(reader, header.Size)) // #nosec G110 - size comes from git archive
+			closeErr := file.Close()
+			if copyErr != nil {
+				return copyErr
+			}
+			if closeErr != nil {
+				return closeErr
+			}
+		}
+	}
+}
+
+// ParseRegistryJSON parses a built registry in either the ToolHive or the official format
+func ParseRegistryJSON(data []byte) (map[string]*types.RegistryEntry, error) {
+	var probe struct {
+		Data          json.RawMessage `json:"data"`
+		Servers       json.RawMessage `json:"servers"`
+		RemoteServers json.RawMessage `json:"remote_servers"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse registry JSON: %w", err)
+	}
+
+	if len(probe.Data) > 0 {
+		var official ToolHiveRegistryType
+		if err := json.Unmarshal(data, &official); err != nil {
+			return nil, fmt.Errorf("failed to parse official registry JSON: %w", err)
+		}
+		return EntriesFromServerJSON(official.Data.Servers)
+	}
+
+	var registry toolhiveRegistry.Registry
+	if err := json.Unmarshal(data, &registry); err != nil {
+		return nil, fmt.Errorf("failed to parse ToolHive registry JSON: %w", err)
+	}
+	return EntriesFromToolHiveRegistry(&registry), nil
+}
+
+// EntriesFromToolHiveRegistry converts a ToolHive-format registry into registry entries
+func EntriesFromToolHiveRegistry(registry *toolhiveRegistry.Registry) map[string]*types.RegistryEntry {
+	entries := make(map[string]*types.RegistryEntry)
+	for name, server := range registry.Servers {
+		metadata := *server
+		entry := &types.RegistryEntry{ImageMetadata: &metadata}
+		metadata.CustomMetadata = takeCapabilities(metadata.CustomMetadata, entry)
+		entry.SetName(name)
+		entries[name] = entry
+	}
+	for name, server := range registry.RemoteServers {
+		metadata := *server
+		entry := &types.RegistryEntry{RemoteServerMetadata: &metadata}
+		metadata.CustomMetadata = takeCapabilities(metadata.CustomMetadata, entry)
+		entry.SetName(name)
+		entries[name] = entry
+	}
+	return entries
+}
+
+// takeCapabilities moves the resources, prompts and resource templates that the ToolHive
+// format keeps in custom metadata back to the entry and returns the remaining custom metadata.
+// Tool side effects are dropped, as they are derived from tool definitions the ToolHive format
+// doesn't have.
+func takeCapabilities(custom map[string]any, entry *types.RegistryEntry) map[string]any {
+	_, hasResources := custom[customMetadataResources]
+	_, hasPrompts := custom[customMetadataPrompts]
+	_, hasTemplates := custom[customMetadataResourceTemplates]
+	_, hasSideEffects := custom[customMetadataToolSideEffects]
+	if !hasResources && !hasPrompts && !hasTemplates && !hasSideEffects {
+		return custom
+	}
+
+	result := maps.Clone(custom)
+	delete(result, customMetadataResources)
+	delete(result, customMetadataPrompts)
+	delete(result, customMetadataResourceTemplates)
+	delete(result, customMetadataToolSideEffects)
+	// Custom metadata decodes as generic JSON values; a round trip gives the typed lists
+	if data, err := json.Marshal(custom); err == nil {
+		var capabilities struct {
+			Resources         []types.Resource         `json:"resources"`
+			Prompts           []types.Prompt           `json:"prompts"`
+			ResourceTemplates []types.ResourceTemplate `json:"resource_templates"`
+		}
+		if json.Unmarshal(data, &capabilities) == nil {
+			entry.Resources, entry.Prompts = capabilities.Resources, capabilities.Prompts
+			entry.ResourceTemplates = capabilities.ResourceTemplates
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// EntriesFromServerJSON converts official-format servers back into registry entries
+func EntriesFromServerJSON(servers []upstream.ServerJSON) (map[string]*types.RegistryEntry, error) {
+	entries := make(map[string]*types.RegistryEntry)
+	for _, server := range servers {
+		name, entry, err := EntryFromServerJSON(server)
+		if err != nil {
+			return nil, err
+		}
+		// Of several versions of a server, keep the latest one
+		if _, exists := entries[name]; !exists || isLatestServer(server) {
+			entries[name] = entry
+		}
+	}
+	return entries, nil
+}
+
+// toolhiveExtensions mirrors the ToolHive publisher extensions written by createToolHiveExtensions
+type toolhiveExtensions struct {
+	Transport   string                        `json:"transport"`
+	Tools       []string                      `json:"tools"`
+	Tier        string                        `json:"tier"`
+	Tags        []string                      `json:"tags"`
+	Permissions *permissions.Profile          `json:"permissions"`
+	Args        []string                      `json:"args"`
+	Metadata    *toolhiveRegistry.Metadata    `json:"metadata"`
+	Provenance  *toolhiveRegistry.Provenance  `json:"provenance"`
+	OAuthConfig *toolhiveRegistry.OAuthConfig `json:"oauth_config"`
+	Examples    []types.Example               `json:"examples"`
+	License     string                        `json:"license"`
+
+	ToolDefinitions   []types.ToolDefinition   `json:"tool_definitions"`
+	Resources         []types.Resource         `json:"resources"`
+	Prompts           []types.Prompt           `json:"prompts"`
+	ResourceTemplates []types.ResourceTemplate `json:"resource_templates"`
+
+	TargetPort     int                        `json:"target_port"`
+	DockerTags     []string                   `json:"docker_tags"`
+	EnvVars        []*toolhiveRegistry.EnvVar `json:"env_vars"`
+	CustomMetadata map[string]any             `json:"custom_metadata"`
+}
+
+// EntryFromServerJSON converts a single official-format server back into a registry entry.
+// ToolHive-specific fields are read from the publisher-provided toolhive extensions.
+func EntryFromServerJSON(server upstream.ServerJSON) (string, *types.RegistryEntry, error) {
+	name := entryNameFromServerName(server.Name)
+	if name == "" {
+		return "", nil, fmt.Errorf("server has no name")
+	}
+
+	key, ext, err := readToolHiveExtensions(server)
+	if err != nil {
+		return "", nil, fmt.Errorf("server %s: %w", server.Name, err)
+	}
+
+	base := toolhiveRegistry.BaseServerMetadata{
+		Name:          name,
+		Description:   server.Description,
+		Tier:          ext.Tier,
+		Status:        convertStatusFromOfficial(server.Status),
+		Transport:     ext.Transport,
+		Tools:         ext.Tools,
+		Metadata:      ext.Metadata,
+		RepositoryURL: server.Repository.URL,
+		Tags:          ext.Tags,
+	}
+
+	entry := &types.RegistryEntry{
+		Examples:        ext.Examples,
+		License:         ext.License,
+		ToolDefinitions: ext.ToolDefinitions,
+		Resources:       ext.Resources,
+		Prompts:         ext.Prompts,
+
+		ResourceTemplates: ext.ResourceTemplates,
+	}
+
+	pkg := ociPackage(server.Packages)
+	switch {
+	case pkg != nil:
+		image := key
+		if image == "" {
+			image = imageFromPackage(*pkg)
+		}
+		if base.Transport == "" {
+			base.Transport = pkg.Transport.Type
+		}
+		entry.ImageMetadata = &toolhiveRegistry.ImageMetadata{
+			BaseServerMetadata: base,
+			Image:              image,
+			TargetPort:         ext.TargetPort,
+			Permissions:        ext.Permissions,
+			EnvVars:            envVarsFromInputs(pkg.EnvironmentVariables),
+			Args:               ext.Args,
+			DockerTags:         ext.DockerTags,
+			Provenance:         ext.Provenance,
+		}
+	case len(server.Remotes) > 0:
+		remote := server.Remotes[0]
+		if base.Transport == "" {
+			base.Transport = remote.Type
+		}
+		if base.RepositoryURL == placeholderRepositoryURL {
+			base.RepositoryURL = ""
+		}
+		base.CustomMetadata = ext.CustomMetadata
+		entry.RemoteServerMetadata = &toolhiveRegistry.RemoteServerMetadata{
+			BaseServerMetadata: base,
+			URL:                remote.URL,
+			Headers:            headersFromInputs(remote.Headers),
+			OAuthConfig:        ext.OAuthConfig,
+			EnvVars:            ext.EnvVars,
+		}
+	case len(server.Packages) > 0:
+		return "", nil, fmt.Errorf("server %s has no OCI package (found %s)", server.Name, server.Packages[0].RegistryType)
+	default:
+		return "", nil, fmt.Errorf("server %s has neither packages nor remotes", server.Name)
+	}
+
+	// Only keep an explicit version if it can't be derived from the entry itself
+	if isSemver(server.Version) && strings.TrimPrefix(server.Version, "v") != entryVersion(entry) {
+		entry.Version = server.Version
+	}
+
+	return name, entry, nil
+}
+
+// entryNameFromServerName derives an entry name from an official server name. ToolHive
+// servers drop their namespace; servers of other publishers keep the last path segment.
+func entryNameFromServerName(serverName string) string {
+	if name, ok := strings.CutPrefix(serverName, toolhiveNamespace); ok {
+		return name
+	}
+	return serverName[strings.LastIndex(serverName, "/")+1:]
+}
+
+// ociPackage returns the first OCI package of a server, or nil if it has none
+func ociPackage(packages []model.Package) *model.Package {
+	for i := range packages {
+		if packages[i].RegistryType == model.RegistryTypeOCI {
+			return &packages[i]
+		}
+	}
+	return nil
+}
+
+// isLatestServer reports whether a server is marked as the latest version of its name
+func isLatestServer(server upstream.ServerJSON) bool {
+	return server.Meta != nil && server.Meta.Official != nil && server.Meta.Official.IsLatest
+}
+
+// readToolHiveExtensions extracts the toolhive publisher extensions and the image/URL key they are stored under
+func readToolHiveExtensions(server upstream.ServerJSON) (string, *toolhiveExtensions, error) {
+	ext := &toolhiveExtensions{}
+	if server.Meta == nil || server.Meta.PublisherProvided == nil {
+		return "", ext, nil
+	}
+
+	toolhive, ok := server.Meta.PublisherProvided["toolhive"].(map[string]interface{})
+	if !ok {
+		return "", ext, nil
+	}
+
+	for key, value := range toolhive {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to read toolhive extensions: %w", err)
+		}
+		if err := json.Unmarshal(data, ext); err != nil {
+			return "", nil, fmt.Errorf("failed to parse toolhive extensions: %w", err)
+		}
+		return key, ext, nil
+	}
+
+	return "", ext, nil
+}
+
+// imageFromPackage rebuilds an image reference from an OCI package
+func imageFromPackage(pkg model.Package) string {
+	image := pkg.Identifier
+	registryHost := strings.TrimPrefix(pkg.RegistryBaseURL, "https://")
+	if registryHost != "" && registryHost != imageref.DockerHubDomain {
+		image = registryHost + "/" + image
+	}
+	if pkg.Version != "" {
+		// A bare digest follows an @, a tag or a tag@digest pin follows a colon
+		if strings.Contains(pkg.Version, ":") && !strings.Contains(pkg.Version, "@") {
+			image += "@" + pkg.Version
+		} else {
+			image += ":" + pkg.Version
+		}
+	}
+	return image
+}
+
+// envVarsFromInputs converts official key/value inputs into ToolHive env vars
+func envVarsFromInputs(inputs []model.KeyValueInput) []*toolhiveRegistry.EnvVar {
+	var envVars []*toolhiveRegistry.EnvVar
+	for _, input := range inputs {
+		envVars = append(envVars, &toolhiveRegistry.EnvVar{
+			Name:        input.Name,
+			Description: input.Description,
+			Required:    input.IsRequired,
+			Default:     input.Default,
+			Secret:      input.IsSecret,
+		})
+	}
+	return envVars
+}
+
+// headersFromInputs converts official key/value inputs into ToolHive headers
+func headersFromInputs(inputs []model.KeyValueInput) []*toolhiveRegistry.Header {
+	var headers []*toolhiveRegistry.Header
+	for _, input := range inputs {
+		headers = append(headers, &toolhiveRegistry.Header{
+			Name:        input.Name,
+			Description: input.Description,
+			Required:    input.IsRequired,
+			Default:     input.Default,
+			Secret:      input.IsSecret,
+			Choices:     input.Choices,
+		})
+	}
+	return headers
+}
+
+// convertStatusFromOfficial converts an official model.Status back to a ToolHive status
+func convertStatusFromOfficial(status model.Status) string {
+	switch status {
+	case model.StatusDeprecated, model.StatusDeleted:
+		return types.StatusDeprecated
+	case model.StatusActive:
+		return types.StatusActive
+	default:
+		return types.StatusActive
+	}
+}
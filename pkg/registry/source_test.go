@@ -0,0 +1,67 @@
+package registry
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// commitTestRegistry creates a git repository with the given spec.yaml files under registry/
+// and commits them, returning the path of the registry directory
+func commitTestRegistry(t *testing.T, specs map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	registryDir := filepath.Join(dir, "registry")
+	for name, spec := range specs {
+		require.NoError(t, os.MkdirAll(filepath.Join(registryDir, name), 0750))
+		require.NoError(t, os.WriteFile(filepath.Join(registryDir, name, "spec.yaml"), []byte(spec), 0600))
+	}
+
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "registry"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	return registryDir
+}
+
+func TestLoadEntriesAtRevision(t *testing.T) {
+	t.Parallel()
+
+	registryDir := commitTestRegistry(t, map[string]string{
+		"good": `description: Good server
+image: test/good:latest
+transport: stdio
+tier: Community
+status: Active
+tools:
+  - tool1`,
+		// Missing both description and tools
+		"broken": `image: test/broken:latest
+transport: stdio
+tier: Community
+status: Active`,
+	})
+
+	// Invalid entries are skipped rather than failing the whole revision
+	entries, skipped, err := LoadEntriesAtRevision(registryDir, "HEAD")
+	require.NoError(t, err)
+	assert.Contains(t, entries, "good")
+	assert.NotContains(t, entries, "broken")
+	assert.Equal(t, []string{"broken"}, skipped)
+
+	// Revisions are never passed on as options, and must name a tree
+	for _, revision := range []string{"", "--output=/tmp/registry.tar", "-h", "missing", "HEAD:registry/good/spec.yaml"} {
+		_, _, err := LoadEntriesAtRevision(registryDir, revision)
+		assert.Error(t, err, revision)
+	}
+}
@@ -0,0 +1,177 @@
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"gopkg.in/yaml.v3"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// remoteCustomMetadataFields are the custom metadata keys that remote specs declare as top-level fields
+var remoteCustomMetadataFields = []string{"homepage", "license", "author"}
+
+// specOAuth is the simplified oauth: shape of remote server specs
+type specOAuth struct {
+	Issuer       string            `yaml:"issuer,omitempty"`
+	AuthorizeURL string            `yaml:"authorize_url,omitempty"`
+	TokenURL     string            `yaml:"token_url,omitempty"`
+	ClientID     string            `yaml:"client_id,omitempty"`
+	Scopes       []string          `yaml:"scopes,omitempty"`
+	UsePKCE      *bool             `yaml:"use_pkce,omitempty"`
+	OAuthParams  map[string]string `yaml:"oauth_params,omitempty"`
+	CallbackPort int               `yaml:"callback_port,omitempty"`
+}
+
+// MarshalSpec encodes an entry as spec.yaml content that loads back into the same entry
+func MarshalSpec(entry *types.RegistryEntry) ([]byte, error) {
+	node, err := SpecNode(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close YAML encoder: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// SpecNode builds the YAML mapping of a spec.yaml for an entry. Fields are in the canonical
+// order of FormatSpec, with remote OAuth settings and custom metadata written in the
+// simplified spec.yaml form.
+func SpecNode(entry *types.RegistryEntry) (*yaml.Node, error) {
+	var node yaml.Node
+	switch {
+	case entry.IsImage():
+		if err := node.Encode(entry.ImageMetadata); err != nil {
+			return nil, fmt.Errorf("failed to encode image metadata: %w", err)
+		}
+	case entry.IsRemote():
+		if err := encodeRemoteSpec(&node, entry); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("entry must be either an image or a remote server")
+	}
+
+	if err := encodeExtendedSpec(&node, entry); err != nil {
+		return nil, err
+	}
+
+	orderMappingKeys(&node, specFileLayout.order)
+	return &node, nil
+}
+
+// encodeExtendedSpec adds the registry's extended fields of an entry to its spec mapping
+func encodeExtendedSpec(node *yaml.Node, entry *types.RegistryEntry) error {
+	if entry.Version != "" {
+		setMappingValue(node, "version", &yaml.Node{Kind: yaml.ScalarNode, Value: entry.Version})
+	}
+	if entry.License != "" {
+		setMappingValue(node, "license", &yaml.Node{Kind: yaml.ScalarNode, Value: entry.License})
+	}
+
+	lists := []struct {
+		key   string
+		value any
+		empty bool
+	}{
+		{"examples", entry.Examples, len(entry.Examples) == 0},
+		{"tool_definitions", entry.ToolDefinitions, len(entry.ToolDefinitions) == 0},
+		{"resources", entry.Resources, len(entry.Resources) == 0},
+		{"prompts", entry.Prompts, len(entry.Prompts) == 0},
+		{"resource_templates", entry.ResourceTemplates, len(entry.ResourceTemplates) == 0},
+	}
+	for _, list := range lists {
+		if list.empty {
+			continue
+		}
+		var value yaml.Node
+		if err := value.Encode(list.value); err != nil {
+			return fmt.Errorf("failed to encode %s: %w", list.key, err)
+		}
+		setMappingValue(node, list.key, &value)
+	}
+	return nil
+}
+
+// encodeRemoteSpec encodes remote server metadata, replacing oauth_config with the oauth: block
+// and moving the custom metadata that spec.yaml files declare at the top level out of custom_metadata
+func encodeRemoteSpec(node *yaml.Node, entry *types.RegistryEntry) error {
+	metadata := *entry.RemoteServerMetadata
+	metadata.OAuthConfig = nil
+	metadata.CustomMetadata = nil
+	for field, value := range entry.RemoteServerMetadata.CustomMetadata {
+		if slices.Contains(remoteCustomMetadataFields, field) {
+			continue
+		}
+		if metadata.CustomMetadata == nil {
+			metadata.CustomMetadata = make(map[string]any)
+		}
+		metadata.CustomMetadata[field] = value
+	}
+	if err := node.Encode(&metadata); err != nil {
+		return fmt.Errorf("failed to encode remote server metadata: %w", err)
+	}
+
+	custom := entry.RemoteServerMetadata.CustomMetadata
+	for _, field := range remoteCustomMetadataFields {
+		value, ok := custom[field]
+		if !ok || (field == "license" && entry.License != "") {
+			continue
+		}
+		var valueNode yaml.Node
+		if err := valueNode.Encode(value); err != nil {
+			return fmt.Errorf("failed to encode %s: %w", field, err)
+		}
+		setMappingValue(node, field, &valueNode)
+	}
+
+	if config := entry.RemoteServerMetadata.OAuthConfig; config != nil {
+		var oauth yaml.Node
+		if err := oauth.Encode(simplifyOAuthConfig(config)); err != nil {
+			return fmt.Errorf("failed to encode oauth: %w", err)
+		}
+		setMappingValue(node, "oauth", &oauth)
+	}
+	return nil
+}
+
+// simplifyOAuthConfig converts an OAuth config to the oauth: shape of spec.yaml,
+// where PKCE is enabled unless use_pkce is explicitly false
+func simplifyOAuthConfig(config *toolhiveRegistry.OAuthConfig) *specOAuth {
+	oauth := &specOAuth{
+		Issuer:       config.Issuer,
+		AuthorizeURL: config.AuthorizeURL,
+		TokenURL:     config.TokenURL,
+		ClientID:     config.ClientID,
+		Scopes:       config.Scopes,
+		OAuthParams:  config.OAuthParams,
+		CallbackPort: config.CallbackPort,
+	}
+	if !config.UsePKCE {
+		usePKCE := false
+		oauth.UsePKCE = &usePKCE
+	}
+	return oauth
+}
+
+// setMappingValue sets the value of a key in a mapping node, appending the key if it is missing
+func setMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+}
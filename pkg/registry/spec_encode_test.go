@@ -0,0 +1,83 @@
+package registry
+
+import (
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func TestMarshalSpec_Remote(t *testing.T) {
+	t.Parallel()
+
+	entry := &types.RegistryEntry{RemoteServerMetadata: &toolhiveRegistry.RemoteServerMetadata{
+		BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+			Name:        "remote",
+			Description: "Remote server",
+			Tier:        types.TierOfficial,
+			Status:      types.StatusActive,
+			Transport:   "streamable-http",
+			Tools:       []string{"search"},
+			CustomMetadata: map[string]any{
+				"homepage": "https://example.com",
+				"author":   "Example",
+				"category": "search",
+			},
+		},
+		URL: "https://mcp.example.com/mcp",
+		Headers: []*toolhiveRegistry.Header{
+			{Name: "X-API-Key", Description: "API key", Required: true, Secret: true},
+		},
+		OAuthConfig: &toolhiveRegistry.OAuthConfig{
+			AuthorizeURL: "https://example.com/oauth/authorize",
+			TokenURL:     "https://example.com/oauth/token",
+			Scopes:       []string{"read"},
+			UsePKCE:      true,
+		},
+		EnvVars: []*toolhiveRegistry.EnvVar{{Name: "WORKSPACE", Description: "Workspace"}},
+	}}
+
+	data, err := MarshalSpec(entry)
+	require.NoError(t, err)
+
+	var raw map[string]any
+	require.NoError(t, yaml.Unmarshal(data, &raw))
+	assert.NotContains(t, raw, "oauth_config")
+	assert.Equal(t, "Example", raw["author"])
+	assert.Equal(t, map[string]any{"category": "search"}, raw["custom_metadata"])
+	assert.NotContains(t, raw["oauth"], "use_pkce")
+
+	var loaded types.RegistryEntry
+	require.NoError(t, yaml.Unmarshal(data, &loaded))
+	assert.Equal(t, entry, &loaded)
+}
+
+func TestMarshalSpec_Image(t *testing.T) {
+	t.Parallel()
+
+	entry := &types.RegistryEntry{
+		ImageMetadata: &toolhiveRegistry.ImageMetadata{
+			BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+				Name:        "image",
+				Description: "Image server",
+				Transport:   "stdio",
+				Tools:       []string{"read"},
+			},
+			Image: "ghcr.io/example/server:1.0.0",
+		},
+		License:  "MIT",
+		Version:  "1.0.0",
+		Examples: []types.Example{{Name: "basic", Description: "Basic", Sample: "thv run image"}},
+	}
+
+	data, err := MarshalSpec(entry)
+	require.NoError(t, err)
+
+	var loaded types.RegistryEntry
+	require.NoError(t, yaml.Unmarshal(data, &loaded))
+	assert.Equal(t, entry, &loaded)
+}
@@ -3,6 +3,7 @@ package registry
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"sort"
@@ -14,9 +15,19 @@ import (
 	"github.com/stacklok/toolhive-registry/pkg/types"
 )
 
+// Custom metadata keys that hold the resources, prompts, resource templates and tool side
+// effects of a server in the ToolHive format
+const (
+	customMetadataResources         = "resources"
+	customMetadataPrompts           = "prompts"
+	customMetadataResourceTemplates = "resource_templates"
+	customMetadataToolSideEffects   = "tool_side_effects"
+)
+
 // Builder builds the final registry JSON from loaded entries
 type Builder struct {
 	loader *Loader
+	clock  *BuildClock
 }
 
 // NewBuilder creates a new registry builder
@@ -26,11 +37,16 @@ func NewBuilder(loader *Loader) *Builder {
 	}
 }
 
+// SetClock sets the clock used for build timestamps (nil uses the current time)
+func (b *Builder) SetClock(clock *BuildClock) {
+	b.clock = clock
+}
+
 // Build creates the final registry structure compatible with toolhive
 func (b *Builder) Build() (*toolhiveRegistry.Registry, error) {
 	registry := &toolhiveRegistry.Registry{
 		Version:       "1.0.0",
-		LastUpdated:   time.Now().UTC().Format(time.RFC3339),
+		LastUpdated:   b.clock.BuildTime().Format(time.RFC3339),
 		Servers:       make(map[string]*toolhiveRegistry.ImageMetadata),
 		RemoteServers: make(map[string]*toolhiveRegistry.RemoteServerMetadata),
 	}
@@ -49,10 +65,12 @@ func (b *Builder) Build() (*toolhiveRegistry.Registry, error) {
 		if entry.IsImage() {
 			// Process image-based server
 			metadata := b.processImageMetadata(entry.ImageMetadata)
+			metadata.CustomMetadata = withCapabilities(metadata.CustomMetadata, entry)
 			registry.Servers[name] = metadata
 		} else if entry.IsRemote() {
 			// Process remote server
 			metadata := b.processRemoteMetadata(entry.RemoteServerMetadata)
+			metadata.CustomMetadata = withCapabilities(metadata.CustomMetadata, entry)
 			registry.RemoteServers[name] = metadata
 		}
 	}
@@ -60,6 +78,35 @@ func (b *Builder) Build() (*toolhiveRegistry.Registry, error) {
 	return registry, nil
 }
 
+// withCapabilities returns a copy of the custom metadata of an entry with the resources,
+// prompts and resource templates the server reports and the side effects of its tools added,
+// as the ToolHive format has no fields for them. Side effects are only added for entries with
+// tool definitions.
+func withCapabilities(custom map[string]any, entry *types.RegistryEntry) map[string]any {
+	if len(entry.Resources) == 0 && len(entry.Prompts) == 0 && len(entry.ResourceTemplates) == 0 &&
+		len(entry.ToolDefinitions) == 0 {
+		return custom
+	}
+
+	result := maps.Clone(custom)
+	if result == nil {
+		result = make(map[string]any)
+	}
+	if len(entry.Resources) > 0 {
+		result[customMetadataResources] = entry.Resources
+	}
+	if len(entry.Prompts) > 0 {
+		result[customMetadataPrompts] = entry.Prompts
+	}
+	if len(entry.ResourceTemplates) > 0 {
+		result[customMetadataResourceTemplates] = entry.ResourceTemplates
+	}
+	if len(entry.ToolDefinitions) > 0 {
+		result[customMetadataToolSideEffects] = entry.ToolSideEffects()
+	}
+	return result
+}
+
 // processImageMetadata processes and normalizes ImageMetadata
 func (*Builder) processImageMetadata(metadata *toolhiveRegistry.ImageMetadata) *toolhiveRegistry.ImageMetadata {
 	// Create a copy of the ImageMetadata
@@ -187,14 +234,40 @@ func (b *Builder) WriteJSON(path string) error {
 		return fmt.Errorf("failed to marshal JSON: %w", err)
 	}
 
-	// Write to file
-	if err := os.WriteFile(path, data, 0600); err != nil {
+	// Write to a temporary file and rename it into place
+	if err := writeFileAtomic(path, data, 0600); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
 	return nil
 }
 
+// DiagnoseSchema validates the built registry against the toolhive schema and reports each
+// violation for the entry it belongs to. Problems outside any entry are reported for "registry".
+func (b *Builder) DiagnoseSchema() Diagnostics {
+	registry, err := b.Build()
+	if err != nil {
+		return Diagnostics{{
+			Entry:    "registry",
+			Rule:     "build",
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("failed to build registry: %v", err),
+		}}
+	}
+	err = NewSchemaValidator().ValidateRegistry(registry)
+	if err == nil {
+		return nil
+	}
+
+	diags := schemaErrorDiagnostics(err, "")
+	for i := range diags {
+		if diags[i].Entry == "" {
+			diags[i].Entry = "registry"
+		}
+	}
+	return diags
+}
+
 // ValidateAgainstSchema validates the built registry against the toolhive schema
 func (b *Builder) ValidateAgainstSchema() error {
 	registry, err := b.Build()
@@ -1,10 +1,12 @@
 package registry
 
 import (
+	"encoding/json"
 	"testing"
 
 	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/stacklok/toolhive-registry/pkg/types"
 )
@@ -38,6 +40,93 @@ func TestBuilder_Build(t *testing.T) {
 	assert.Contains(t, registry.Servers, "test-server")
 }
 
+func TestBuilder_BuildResourcesAndPrompts(t *testing.T) {
+	t.Parallel()
+	entry := &types.RegistryEntry{
+		ImageMetadata: &toolhiveRegistry.ImageMetadata{
+			BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+				Name:           "test-server",
+				Description:    "Test server",
+				Transport:      "stdio",
+				Tools:          []string{"test-tool"},
+				CustomMetadata: map[string]any{"homepage": "https://example.com"},
+			},
+			Image: "test/image:latest",
+		},
+		Resources: []types.Resource{{URI: "file:///readme.md", Name: "readme", MIMEType: "text/markdown"}},
+		Prompts: []types.Prompt{{
+			Name:      "summarize",
+			Arguments: []types.PromptArgument{{Name: "text", Required: true}},
+		}},
+		ResourceTemplates: []types.ResourceTemplate{{URITemplate: "file:///{path}", Name: "file"}},
+	}
+	loader := NewLoader("")
+	loader.entries = map[string]*types.RegistryEntry{"test-server": entry}
+
+	registry, err := NewBuilder(loader).Build()
+	require.NoError(t, err)
+	custom := registry.Servers["test-server"].CustomMetadata
+	assert.Equal(t, entry.Resources, custom["resources"])
+	assert.Equal(t, entry.Prompts, custom["prompts"])
+	assert.Equal(t, entry.ResourceTemplates, custom["resource_templates"])
+	assert.NotContains(t, entry.ImageMetadata.CustomMetadata, "resources", "source entry must not change")
+
+	// Reading the built registry back moves them out of custom_metadata again
+	data, err := json.Marshal(registry)
+	require.NoError(t, err)
+	entries, err := ParseRegistryJSON(data)
+	require.NoError(t, err)
+	require.Contains(t, entries, "test-server")
+	assert.Equal(t, entry.Resources, entries["test-server"].Resources)
+	assert.Equal(t, entry.Prompts, entries["test-server"].Prompts)
+	assert.Equal(t, entry.ResourceTemplates, entries["test-server"].ResourceTemplates)
+	assert.Equal(t, map[string]any{"homepage": "https://example.com"}, entries["test-server"].ImageMetadata.CustomMetadata)
+}
+
+func TestBuilder_BuildToolSideEffects(t *testing.T) {
+	t.Parallel()
+	entry := &types.RegistryEntry{
+		ImageMetadata: &toolhiveRegistry.ImageMetadata{
+			BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+				Name:        "test-server",
+				Description: "Test server",
+				Transport:   "stdio",
+				Tools:       []string{"delete", "fetch", "read", "search", "write"},
+			},
+			Image: "test/image:latest",
+		},
+		ToolDefinitions: []types.ToolDefinition{
+			{Name: "delete", Annotations: map[string]any{"destructiveHint": true}},
+			{Name: "read", Annotations: map[string]any{"readOnlyHint": true}},
+			{Name: "write", Annotations: map[string]any{"readOnlyHint": false, "destructiveHint": false}},
+			// MCP's defaults make annotated tools destructive unless they say otherwise
+			{Name: "search", Annotations: map[string]any{"openWorldHint": true, "idempotentHint": true}},
+			// Classified by hand, as it has no annotations
+			{Name: "fetch", SideEffect: types.SideEffectReadOnly},
+		},
+	}
+	assert.False(t, entry.IsReadOnly())
+
+	loader := NewLoader("")
+	loader.entries = map[string]*types.RegistryEntry{"test-server": entry}
+	registry, err := NewBuilder(loader).Build()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"delete": types.SideEffectDestructive,
+		"fetch":  types.SideEffectReadOnly,
+		"read":   types.SideEffectReadOnly,
+		"search": types.SideEffectDestructive,
+		"write":  types.SideEffectAdditive,
+	}, registry.Servers["test-server"].CustomMetadata["tool_side_effects"])
+
+	// Only entries whose tools are all read-only are read-only
+	entry.ImageMetadata.Tools = []string{"fetch", "read"}
+	entry.ToolDefinitions = entry.ToolDefinitions[1:2]
+	assert.False(t, entry.IsReadOnly(), "tools without a definition are unknown")
+	entry.ToolDefinitions = append(entry.ToolDefinitions, types.ToolDefinition{Name: "fetch", SideEffect: types.SideEffectReadOnly})
+	assert.True(t, entry.IsReadOnly())
+}
+
 func TestBuilder_ValidateAgainstSchema(t *testing.T) {
 	t.Parallel()
 	loader := NewLoader("")
@@ -78,3 +167,47 @@ func TestBuilder_ValidateAgainstSchema(t *testing.T) {
 	err = builder.ValidateAgainstSchema()
 	assert.Error(t, err)
 }
+
+func TestBuilder_DiagnoseSchema(t *testing.T) {
+	t.Parallel()
+	loader := NewLoader("")
+	loader.entries = map[string]*types.RegistryEntry{
+		"valid-server": {
+			ImageMetadata: &toolhiveRegistry.ImageMetadata{
+				BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+					Name:        "valid-server",
+					Description: "Valid test server",
+					Transport:   "stdio",
+					Tier:        types.TierCommunity,
+					Status:      types.StatusActive,
+					Tools:       []string{"test-tool"},
+				},
+				Image: "test/image:latest",
+			},
+		},
+	}
+	builder := NewBuilder(loader)
+	assert.Empty(t, builder.DiagnoseSchema())
+
+	loader.entries["invalid-server"] = &types.RegistryEntry{
+		ImageMetadata: &toolhiveRegistry.ImageMetadata{
+			BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+				Name:        "invalid-server",
+				Description: "Invalid test server",
+				Transport:   "stdio",
+				Tier:        "Unknown",
+				Status:      types.StatusActive,
+				Tools:       []string{"test-tool"},
+			},
+			Image: "test/image:latest",
+		},
+	}
+	diags := builder.DiagnoseSchema()
+	require.NotEmpty(t, diags)
+	for _, diag := range diags {
+		assert.Equal(t, "invalid-server", diag.Entry)
+		assert.Equal(t, "schema", diag.Rule)
+		assert.Equal(t, SeverityError, diag.Severity)
+	}
+	assert.Contains(t, diags[0].Field, "tier")
+}
@@ -0,0 +1,318 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/semver"
+	"gopkg.in/yaml.v3"
+
+	"github.com/stacklok/toolhive-registry/pkg/imageref"
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+const (
+	// VersionsDir is the directory inside an entry that holds per-version overlays
+	VersionsDir = "versions"
+
+	// DefaultVersion is the version of an entry's spec.yaml when nothing else determines it
+	DefaultVersion = "1.0.0"
+)
+
+// EntryVersion is one published version of a registry entry
+type EntryVersion struct {
+	// Version is the semantic version of this entry version
+	Version string
+	// Entry is the complete entry for this version, with any overlay applied
+	Entry *types.RegistryEntry
+	// Path is the file that defines this version (spec.yaml or versions/<version>.yaml)
+	Path string
+	// IsLatest is true for the version that the ToolHive format publishes
+	IsLatest bool
+}
+
+// digestVersionLength is the number of digest characters kept in the fallback version of digest-pinned images
+const digestVersionLength = 12
+
+// entryVersion returns the version of the entry defined by a spec.yaml, in order of preference:
+//   - the explicit version field
+//   - the image tag, if it is a semantic version (a leading v is dropped, e.g. v1.2.3 becomes 1.2.3)
+//   - 0.0.0-sha256-<digest prefix> for other digest-pinned images, and 0.0.0-<tag> for
+//     other tags such as latest, so they sort below every release
+//   - DefaultVersion for remote servers without a version field
+func entryVersion(entry *types.RegistryEntry) string {
+	if entry.Version != "" {
+		return strings.TrimPrefix(entry.Version, "v")
+	}
+	if !entry.IsImage() {
+		return DefaultVersion
+	}
+
+	ref, err := imageref.Parse(entry.Image)
+	if err != nil {
+		return DefaultVersion
+	}
+	if isSemver(ref.Tag) {
+		return strings.TrimPrefix(ref.Tag, "v")
+	}
+
+	tag := ref.Version()
+	if algorithm, digest, ok := strings.Cut(ref.Digest, ":"); ok {
+		if len(digest) > digestVersionLength {
+			digest = digest[:digestVersionLength]
+		}
+		tag = algorithm + "-" + digest
+	}
+	version := "0.0.0-" + prereleaseIdentifier(tag)
+	if !isSemver(version) {
+		return DefaultVersion
+	}
+	return version
+}
+
+// prereleaseIdentifier turns an arbitrary tag into a single semver pre-release identifier.
+// Numeric identifiers can't have leading zeros, so tags such as 0123 become t0123.
+func prereleaseIdentifier(tag string) string {
+	identifier := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, tag)
+	if len(identifier) > 1 && identifier[0] == '0' && strings.Trim(identifier, "0123456789") == "" {
+		identifier = "t" + identifier
+	}
+	return identifier
+}
+
+// loadEntryVersions loads the versions/<semver>.yaml overlays of an entry directory and
+// merges each one over the base spec.yaml. The result includes the base spec itself,
+// sorted by ascending version, with the latest version marked.
+func (*Loader) loadEntryVersions(
+	name, specPath string, base *types.RegistryEntry, baseRoot *yaml.Node,
+) ([]*EntryVersion, Diagnostics) {
+	versions := []*EntryVersion{{Version: entryVersion(base), Entry: base, Path: specPath}}
+
+	versionsDir := filepath.Join(filepath.Dir(specPath), VersionsDir)
+	files, err := os.ReadDir(versionsDir)
+	if err != nil {
+		// No versions directory means a single version
+		markLatest(versions)
+		return versions, nil
+	}
+
+	var diags Diagnostics
+	seen := map[string]string{semverCanonical(versions[0].Version): specPath}
+	for _, file := range files {
+		ext := filepath.Ext(file.Name())
+		if file.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+
+		path := filepath.Join(versionsDir, file.Name())
+		version := strings.TrimSuffix(file.Name(), ext)
+		if !isSemver(version) {
+			diags = append(diags, versionDiagnostic(name, path, "version-invalid",
+				fmt.Sprintf("version file name '%s' is not a semantic version (e.g. 1.2.3)", version)))
+			continue
+		}
+		if other, ok := seen[semverCanonical(version)]; ok {
+			diags = append(diags, versionDiagnostic(name, path, "version-duplicate",
+				fmt.Sprintf("version %s is also defined by %s", version, other)))
+			continue
+		}
+		seen[semverCanonical(version)] = path
+
+		entry, versionDiags := loadVersionOverlay(name, path, specPath, baseRoot)
+		diags = append(diags, versionDiags...)
+		if entry != nil && !versionDiags.HasErrors() {
+			// The file name, not the inherited version field or image tag, defines an overlay's version
+			version = strings.TrimPrefix(version, "v")
+			entry.Version = version
+			versions = append(versions, &EntryVersion{Version: version, Entry: entry, Path: path})
+		}
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		return semver.Compare(semverCanonical(versions[i].Version), semverCanonical(versions[j].Version)) < 0
+	})
+	markLatest(versions)
+
+	return versions, diags
+}
+
+// loadVersionOverlay merges a single version overlay over the base spec and validates the result
+func loadVersionOverlay(name, path, specPath string, baseRoot *yaml.Node) (*types.RegistryEntry, Diagnostics) {
+	data, err := os.ReadFile(path) // #nosec G304 - path is constructed from known directory structure
+	if err != nil {
+		return nil, Diagnostics{versionDiagnostic(name, path, "read", fmt.Sprintf("failed to read file: %v", err))}
+	}
+
+	var overlay yaml.Node
+	if err := yaml.Unmarshal(data, &overlay); err != nil {
+		diag := versionDiagnostic(name, path, "yaml", fmt.Sprintf("failed to parse YAML: %v", err))
+		diag.Line = yamlErrorLine(err)
+		return nil, Diagnostics{diag}
+	}
+
+	if overlaySets(&overlay, "name") {
+		diag := versionDiagnostic(name, path, "version-name", "version overlays cannot change the entry name")
+		diag.Field = "name"
+		diag.Line, diag.Column = locateField(&overlay, "name")
+		return nil, Diagnostics{diag}
+	}
+
+	merged := mergeYAMLNodes(baseRoot, &overlay)
+	var entry types.RegistryEntry
+	if err := merged.Decode(&entry); err != nil {
+		return nil, Diagnostics{versionDiagnostic(name, path, "yaml", fmt.Sprintf("failed to parse YAML: %v", err))}
+	}
+
+	// Point each problem at the overlay if it sets the field, otherwise at the base spec
+	diags := NewSchemaValidator().DiagnoseEntry(&entry, name)
+	for i := range diags {
+		diags[i].Path = path
+		diags[i].Line, diags[i].Column = locateField(&overlay, diags[i].Field)
+		if !overlaySets(&overlay, diags[i].Field) {
+			diags[i].Path = specPath
+			diags[i].Line, diags[i].Column = locateField(baseRoot, diags[i].Field)
+		}
+		diags[i].Message = fmt.Sprintf("version %s: %s", filepath.Base(path), diags[i].Message)
+	}
+
+	return &entry, diags
+}
+
+// overlaySets reports whether an overlay sets the top-level key of a dotted field path
+func overlaySets(overlay *yaml.Node, field string) bool {
+	node := overlay
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode || field == "" {
+		return false
+	}
+	key, _, _ := strings.Cut(field, ".")
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return true
+		}
+	}
+	return false
+}
+
+// versionDiagnostic creates an error diagnostic for a version overlay file
+func versionDiagnostic(name, path, rule, message string) Diagnostic {
+	return Diagnostic{
+		Entry:    name,
+		Path:     path,
+		Rule:     rule,
+		Severity: SeverityError,
+		Message:  message,
+	}
+}
+
+// markLatest marks the highest version as latest, preferring releases over pre-releases
+func markLatest(versions []*EntryVersion) {
+	latest := -1
+	for i, version := range versions {
+		version.IsLatest = false
+		if semver.Prerelease(semverCanonical(version.Version)) == "" {
+			latest = i
+		}
+	}
+	if latest < 0 {
+		latest = len(versions) - 1
+	}
+	if latest >= 0 {
+		versions[latest].IsLatest = true
+	}
+}
+
+// latestVersion returns the version marked as latest
+func latestVersion(versions []*EntryVersion) *EntryVersion {
+	for _, version := range versions {
+		if version.IsLatest {
+			return version
+		}
+	}
+	return nil
+}
+
+// isSemver reports whether a version is a valid semantic version, with or without a leading v.
+// Like the official registry, it requires all three of major, minor and patch.
+func isSemver(version string) bool {
+	canonical := semverCanonical(version)
+	if !semver.IsValid(canonical) {
+		return false
+	}
+	core := strings.TrimPrefix(canonical, "v")
+	core, _, _ = strings.Cut(core, "+")
+	core, _, _ = strings.Cut(core, "-")
+	return strings.Count(core, ".") == 2
+}
+
+// semverCanonical adds the leading v expected by golang.org/x/mod/semver
+func semverCanonical(version string) string {
+	if strings.HasPrefix(version, "v") {
+		return version
+	}
+	return "v" + version
+}
+
+// mergeYAMLNodes returns a copy of base with overlay applied: mappings are merged
+// key by key, while scalars and sequences in the overlay replace those in base
+func mergeYAMLNodes(base, overlay *yaml.Node) *yaml.Node {
+	if base == nil {
+		return overlay
+	}
+	if overlay == nil {
+		return copyYAMLNode(base)
+	}
+
+	if base.Kind == yaml.DocumentNode && overlay.Kind == yaml.DocumentNode &&
+		len(base.Content) == 1 && len(overlay.Content) == 1 {
+		merged := *base
+		merged.Content = []*yaml.Node{mergeYAMLNodes(base.Content[0], overlay.Content[0])}
+		return &merged
+	}
+
+	if base.Kind != yaml.MappingNode || overlay.Kind != yaml.MappingNode {
+		return copyYAMLNode(overlay)
+	}
+
+	merged := copyYAMLNode(base)
+	for i := 0; i+1 < len(overlay.Content); i += 2 {
+		key, value := overlay.Content[i], overlay.Content[i+1]
+		replaced := false
+		for j := 0; j+1 < len(merged.Content); j += 2 {
+			if merged.Content[j].Value == key.Value {
+				merged.Content[j+1] = mergeYAMLNodes(merged.Content[j+1], value)
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged.Content = append(merged.Content, copyYAMLNode(key), copyYAMLNode(value))
+		}
+	}
+	return merged
+}
+
+// copyYAMLNode returns a deep copy of a node tree
+func copyYAMLNode(node *yaml.Node) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	copied := *node
+	if node.Content != nil {
+		copied.Content = make([]*yaml.Node, len(node.Content))
+		for i, child := range node.Content {
+			copied.Content[i] = copyYAMLNode(child)
+		}
+	}
+	return &copied
+}
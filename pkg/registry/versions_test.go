@@ -0,0 +1,197 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+const versionsTestSpec = `description: Versioned server
+image: test/server:1.0.0
+transport: stdio
+tier: Community
+status: Active
+tools:
+  - tool1
+env_vars:
+  - name: API_KEY
+    description: API key
+    required: true
+`
+
+func writeVersionsTestEntry(t *testing.T, dir string, overlays map[string]string) {
+	t.Helper()
+	entryDir := filepath.Join(dir, "versioned")
+	require.NoError(t, os.MkdirAll(filepath.Join(entryDir, VersionsDir), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(entryDir, "spec.yaml"), []byte(versionsTestSpec), 0600))
+	for file, content := range overlays {
+		require.NoError(t, os.WriteFile(filepath.Join(entryDir, VersionsDir, file), []byte(content), 0600))
+	}
+}
+
+func TestLoader_Versions(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+
+	writeVersionsTestEntry(t, tmpDir, map[string]string{
+		"2.0.0.yaml":      "image: test/server:2.0.0\ntools:\n  - tool1\n  - tool2\n",
+		"1.5.0.yaml":      "image: test/server:1.5.0\n",
+		"3.0.0-rc.1.yaml": "image: test/server:3.0.0-rc.1\n",
+	})
+
+	loader := NewLoader(tmpDir)
+	require.NoError(t, loader.LoadAll())
+
+	versions := loader.GetVersions("versioned")
+	require.Len(t, versions, 4)
+
+	var names []string
+	for _, version := range versions {
+		names = append(names, version.Version)
+	}
+	assert.Equal(t, []string{"1.0.0", "1.5.0", "2.0.0", "3.0.0-rc.1"}, names)
+
+	// The highest release is latest; pre-releases are not
+	latest := versions[2]
+	assert.True(t, latest.IsLatest)
+	assert.False(t, versions[3].IsLatest)
+	assert.Equal(t, "test/server:2.0.0", latest.Entry.Image)
+	assert.Equal(t, []string{"tool1", "tool2"}, latest.Entry.GetTools())
+
+	// Overlays inherit everything they don't set from spec.yaml
+	assert.Equal(t, "Versioned server", latest.Entry.GetDescription())
+	require.Len(t, latest.Entry.ImageMetadata.EnvVars, 1)
+	assert.Equal(t, "API_KEY", latest.Entry.ImageMetadata.EnvVars[0].Name)
+	assert.Equal(t, []string{"tool1"}, versions[1].Entry.GetTools())
+
+	// The ToolHive format carries only the latest version
+	assert.Equal(t, "test/server:2.0.0", loader.GetEntries()["versioned"].Image)
+	registry, err := NewBuilder(loader).Build()
+	require.NoError(t, err)
+	assert.Equal(t, "test/server:2.0.0", registry.Servers["versioned"].Image)
+
+	// The official format carries every version
+	official := NewOfficialRegistry(loader).build()
+	require.Len(t, official.Data.Servers, 4)
+	for _, server := range official.Data.Servers {
+		assert.Equal(t, "io.stacklok.toolhive/versioned", server.Name)
+		assert.Equal(t, server.Version == "2.0.0", server.Meta.Official.IsLatest, server.Version)
+	}
+}
+
+func TestLoader_VersionsInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		overlays map[string]string
+		rule     string
+	}{
+		{
+			name:     "file name is not semver",
+			overlays: map[string]string{"latest.yaml": "image: test/server:latest\n"},
+			rule:     "version-invalid",
+		},
+		{
+			name:     "duplicates the base version",
+			overlays: map[string]string{"v1.0.0.yaml": "image: test/server:1.0.0\n"},
+			rule:     "version-duplicate",
+		},
+		{
+			name:     "overlay renames the entry",
+			overlays: map[string]string{"2.0.0.yaml": "name: other\n"},
+			rule:     "version-name",
+		},
+		{
+			name:     "explicit version is not semver",
+			overlays: map[string]string{"2.0.0.yaml": "version: latest\n"},
+			rule:     "version-invalid",
+		},
+		{
+			name:     "merged overlay is invalid",
+			overlays: map[string]string{"2.0.0.yaml": "transport: bogus\n"},
+			rule:     "schema",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir := t.TempDir()
+			writeVersionsTestEntry(t, tmpDir, tt.overlays)
+
+			loader := NewLoader(tmpDir)
+			require.Error(t, loader.LoadAll())
+
+			diags := loader.Diagnostics()
+			require.NotEmpty(t, diags)
+			assert.Equal(t, tt.rule, diags[0].Rule)
+			assert.Equal(t, "versioned", diags[0].Entry)
+		})
+	}
+}
+
+func TestEntryVersion(t *testing.T) {
+	t.Parallel()
+
+	image := func(ref string) *types.RegistryEntry {
+		return &types.RegistryEntry{ImageMetadata: &toolhiveRegistry.ImageMetadata{Image: ref}}
+	}
+	remote := &types.RegistryEntry{
+		RemoteServerMetadata: &toolhiveRegistry.RemoteServerMetadata{URL: "https://example.com/mcp"},
+	}
+	versioned := image("ghcr.io/org/server:latest")
+	versioned.Version = "v2.1.0"
+	versionedRemote := *remote
+	versionedRemote.Version = "0.4.0"
+
+	tests := []struct {
+		name  string
+		entry *types.RegistryEntry
+		want  string
+	}{
+		{"semver tag", image("ghcr.io/org/server:1.2.3"), "1.2.3"},
+		{"v-prefixed tag", image("ghcr.io/org/server:v0.15.0"), "0.15.0"},
+		{"pre-release tag", image("ghcr.io/org/server:2.0.0-rc.1"), "2.0.0-rc.1"},
+		{"latest tag", image("ghcr.io/org/server:latest"), "0.0.0-latest"},
+		{"no tag", image("ghcr.io/org/server"), "0.0.0-latest"},
+		{"partial version tag", image("ghcr.io/org/server:1.2"), "0.0.0-1-2"},
+		{"other tag", image("ghcr.io/org/server:main_2024"), "0.0.0-main-2024"},
+		{"numeric tag", image("ghcr.io/org/server:20240101"), "0.0.0-20240101"},
+		{"numeric tag with leading zero", image("ghcr.io/org/server:0123"), "0.0.0-t0123"},
+		{
+			"digest-pinned",
+			image("ghcr.io/org/server@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"),
+			"0.0.0-sha256-0123456789ab",
+		},
+		{"explicit version", versioned, "2.1.0"},
+		{"remote without version", remote, DefaultVersion},
+		{"remote with version", &versionedRemote, "0.4.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			version := entryVersion(tt.entry)
+			assert.Equal(t, tt.want, version)
+			assert.True(t, isSemver(version))
+		})
+	}
+}
+
+func TestIsSemver(t *testing.T) {
+	t.Parallel()
+
+	for _, version := range []string{"1.2.3", "v1.2.3", "1.2.3-rc.1", "1.2.3+build.5", "0.0.0-latest"} {
+		assert.True(t, isSemver(version), version)
+	}
+	for _, version := range []string{"1", "1.2", "v1.2", "latest", "1.2.3.4", "01.2.3", ""} {
+		assert.False(t, isSemver(version), version)
+	}
+}
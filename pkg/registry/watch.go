@@ -0,0 +1,199 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// defaultWatchDebounce groups the burst of events editors emit for a single save
+const defaultWatchDebounce = 200 * time.Millisecond
+
+// WatchEvent describes the result of reloading a changed spec.yaml
+type WatchEvent struct {
+	// Entry is the name of the affected entry
+	Entry string
+	// Path is the spec.yaml that changed
+	Path string
+	// Removed is true if the spec was deleted and its entry dropped
+	Removed bool
+	// Diagnostics are the validation results for the changed entry only
+	Diagnostics Diagnostics
+}
+
+// Watcher watches the spec.yaml files of a loaded registry and reloads only the entries that change
+type Watcher struct {
+	loader    *Loader
+	fsWatcher *fsnotify.Watcher
+	debounce  time.Duration
+}
+
+// NewWatcher creates a watcher for the registry directory of a loader.
+// The loader should already have been populated with LoadAll.
+func NewWatcher(loader *Loader) (*Watcher, error) {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+
+	w := &Watcher{
+		loader:    loader,
+		fsWatcher: fsWatcher,
+		debounce:  defaultWatchDebounce,
+	}
+
+	if err := fsWatcher.Add(loader.registryPath); err != nil {
+		_ = fsWatcher.Close()
+		return nil, fmt.Errorf("failed to watch %s: %w", loader.registryPath, err)
+	}
+
+	dirs, err := os.ReadDir(loader.registryPath)
+	if err != nil {
+		_ = fsWatcher.Close()
+		return nil, fmt.Errorf("failed to read %s: %w", loader.registryPath, err)
+	}
+	for _, dir := range dirs {
+		if dir.IsDir() && !strings.HasPrefix(dir.Name(), ".") {
+			entryDir := filepath.Join(loader.registryPath, dir.Name())
+			if err := fsWatcher.Add(entryDir); err != nil {
+				_ = fsWatcher.Close()
+				return nil, fmt.Errorf("failed to watch %s: %w", dir.Name(), err)
+			}
+			w.watchVersionsDir(entryDir)
+		}
+	}
+
+	return w, nil
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	return w.fsWatcher.Close()
+}
+
+// Run processes file system events until the context is cancelled. Each changed
+// spec.yaml is reloaded through the loader and reported to onChange once the burst
+// of events for it has settled.
+func (w *Watcher) Run(ctx context.Context, onChange func(WatchEvent)) error {
+	pending := make(map[string]bool)
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-w.fsWatcher.Events:
+			if !ok {
+				return nil
+			}
+			if specPath := w.specPathFor(event); specPath != "" {
+				pending[specPath] = true
+				timer.Reset(w.debounce)
+			}
+
+		case err, ok := <-w.fsWatcher.Errors:
+			if !ok {
+				return nil
+			}
+			return fmt.Errorf("file watcher failed: %w", err)
+
+		case <-timer.C:
+			for _, specPath := range sortedKeys(pending) {
+				if event, ok := w.reload(specPath); ok {
+					onChange(event)
+				}
+			}
+			pending = make(map[string]bool)
+		}
+	}
+}
+
+// specPathFor maps a file system event to the spec.yaml it affects, or "" if none.
+// New entry directories are added to the watch list as they appear.
+func (w *Watcher) specPathFor(event fsnotify.Event) string {
+	registryPath := filepath.Clean(w.loader.registryPath)
+	name := filepath.Clean(event.Name)
+	base := filepath.Base(name)
+
+	if strings.HasPrefix(base, ".") {
+		return ""
+	}
+
+	// An entry directory directly below the registry was created, removed or renamed
+	if filepath.Dir(name) == registryPath {
+		specPath := filepath.Join(name, "spec.yaml")
+		if info, err := os.Stat(name); err == nil && info.IsDir() {
+			if event.Has(fsnotify.Create) {
+				_ = w.fsWatcher.Add(name)
+				w.watchVersionsDir(name)
+			}
+			return specPath
+		}
+		if w.loader.hasSourcePath(specPath) {
+			return specPath
+		}
+		return ""
+	}
+
+	entryDir := filepath.Dir(name)
+	if base == "spec.yaml" && filepath.Dir(entryDir) == registryPath {
+		return name
+	}
+
+	// The versions directory itself, or a version overlay inside it, changed
+	if base == VersionsDir && filepath.Dir(entryDir) == registryPath {
+		if event.Has(fsnotify.Create) {
+			w.watchVersionsDir(entryDir)
+		}
+		return filepath.Join(entryDir, "spec.yaml")
+	}
+	if filepath.Base(entryDir) == VersionsDir && filepath.Dir(filepath.Dir(entryDir)) == registryPath {
+		return filepath.Join(filepath.Dir(entryDir), "spec.yaml")
+	}
+
+	return ""
+}
+
+// watchVersionsDir adds the versions directory of an entry to the watch list if it exists
+func (w *Watcher) watchVersionsDir(entryDir string) {
+	versionsDir := filepath.Join(entryDir, VersionsDir)
+	if info, err := os.Stat(versionsDir); err == nil && info.IsDir() {
+		_ = w.fsWatcher.Add(versionsDir)
+	}
+}
+
+// reload reloads a single spec.yaml and describes the result.
+// It returns false for a missing spec that was never loaded, such as a new empty directory.
+func (w *Watcher) reload(specPath string) (WatchEvent, bool) {
+	_, statErr := os.Stat(specPath)
+	removed := os.IsNotExist(statErr)
+	if removed && !w.loader.hasSourcePath(specPath) {
+		return WatchEvent{}, false
+	}
+
+	name, diags := w.loader.ReloadEntry(specPath)
+	return WatchEvent{
+		Entry:       name,
+		Path:        specPath,
+		Removed:     removed,
+		Diagnostics: diags,
+	}, true
+}
+
+// sortedKeys returns the keys of a set in sorted order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
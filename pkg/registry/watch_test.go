@@ -0,0 +1,121 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const watchTestSpec = `description: %s
+image: test/server:latest
+transport: stdio
+tier: Community
+status: Active
+tools:
+  - tool1
+`
+
+func writeWatchTestSpec(t *testing.T, dir, name, description string) string {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, name), 0750))
+	path := filepath.Join(dir, name, "spec.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf(watchTestSpec, description)), 0600))
+	return path
+}
+
+func TestLoader_ReloadEntry(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+
+	writeWatchTestSpec(t, tmpDir, "one", "First server")
+	twoPath := writeWatchTestSpec(t, tmpDir, "two", "Second server")
+
+	loader := NewLoader(tmpDir)
+	require.NoError(t, loader.LoadAll())
+
+	// A valid change replaces only that entry
+	writeWatchTestSpec(t, tmpDir, "two", "Updated server")
+	name, diags := loader.ReloadEntry(twoPath)
+	assert.Equal(t, "two", name)
+	assert.Empty(t, diags)
+	assert.Equal(t, "Updated server", loader.GetEntries()["two"].GetDescription())
+
+	// An invalid change is reported and keeps the previous version
+	require.NoError(t, os.WriteFile(twoPath, []byte("image: test/server:latest\ntransport: stdio\n"), 0600))
+	name, diags = loader.ReloadEntry(twoPath)
+	assert.Equal(t, "two", name)
+	assert.True(t, diags.HasErrors())
+	assert.Equal(t, []string{"two"}, diags.Entries())
+	assert.Equal(t, "Updated server", loader.GetEntries()["two"].GetDescription())
+	assert.Equal(t, diags, loader.Diagnostics())
+
+	// Claiming another entry's name is rejected
+	require.NoError(t, os.WriteFile(twoPath, []byte("name: one\n"+fmt.Sprintf(watchTestSpec, "Clash")), 0600))
+	_, diags = loader.ReloadEntry(twoPath)
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, "First server", loader.GetEntries()["one"].GetDescription())
+
+	// Removing the spec drops the entry and its diagnostics
+	require.NoError(t, os.RemoveAll(filepath.Dir(twoPath)))
+	name, diags = loader.ReloadEntry(twoPath)
+	assert.Equal(t, "two", name)
+	assert.Empty(t, diags)
+	assert.NotContains(t, loader.GetEntries(), "two")
+	assert.Empty(t, loader.Diagnostics())
+}
+
+func TestWatcher_Run(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+
+	onePath := writeWatchTestSpec(t, tmpDir, "one", "First server")
+
+	loader := NewLoader(tmpDir)
+	require.NoError(t, loader.LoadAll())
+
+	watcher, err := NewWatcher(loader)
+	require.NoError(t, err)
+	defer watcher.Close()
+	watcher.debounce = 20 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan WatchEvent, 10)
+	go func() {
+		_ = watcher.Run(ctx, func(event WatchEvent) {
+			events <- event
+		})
+	}()
+
+	next := func() WatchEvent {
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for watch event")
+			return WatchEvent{}
+		}
+	}
+
+	writeWatchTestSpec(t, tmpDir, "one", "Edited server")
+	event := next()
+	assert.Equal(t, "one", event.Entry)
+	assert.Equal(t, onePath, event.Path)
+	assert.False(t, event.Removed)
+	assert.Equal(t, "Edited server", loader.GetEntries()["one"].GetDescription())
+
+	// New entry directories are picked up
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "three"), 0750))
+	time.Sleep(100 * time.Millisecond)
+	writeWatchTestSpec(t, tmpDir, "three", "Third server")
+	event = next()
+	assert.Equal(t, "three", event.Entry)
+	assert.Contains(t, loader.GetEntries(), "three")
+}
@@ -0,0 +1,322 @@
+// Package server serves a built registry over the read-only MCP Registry v0 HTTP API
+package server
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	upstream "github.com/modelcontextprotocol/registry/pkg/api/v0"
+)
+
+const (
+	// DefaultLimit is the page size used when the limit query parameter is not set
+	DefaultLimit = 30
+	// MaxLimit is the largest accepted page size
+	MaxLimit = 100
+)
+
+// Server serves registry servers over the MCP Registry v0 API.
+// The served servers can be replaced at any time with Update.
+type Server struct {
+	mu      sync.RWMutex
+	servers []upstream.ServerJSON
+	mux     *http.ServeMux
+}
+
+// New creates a server for the given servers
+func New(servers []upstream.ServerJSON) *Server {
+	s := &Server{servers: servers}
+
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("GET /v0/servers", s.handleListServers)
+	s.mux.HandleFunc("GET /v0/servers/{id...}", s.handleGetServer)
+	s.mux.HandleFunc("GET /v0/health", s.handleHealth)
+
+	return s
+}
+
+// Update replaces the served servers
+func (s *Server) Update(servers []upstream.ServerJSON) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.servers = servers
+}
+
+// Servers returns the currently served servers
+func (s *Server) Servers() []upstream.ServerJSON {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.servers
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// listOptions are the query parameters of the list endpoint
+type listOptions struct {
+	cursor       string
+	limit        int
+	search       string
+	version      string
+	updatedSince time.Time
+}
+
+// parseListOptions parses and validates the query parameters of the list endpoint
+func parseListOptions(query url.Values) (listOptions, error) {
+	opts := listOptions{
+		cursor:  query.Get("cursor"),
+		limit:   DefaultLimit,
+		search:  strings.ToLower(query.Get("search")),
+		version: query.Get("version"),
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > MaxLimit {
+			return opts, fmt.Errorf("invalid limit parameter: must be between 1 and %d", MaxLimit)
+		}
+		opts.limit = limit
+	}
+
+	if value := query.Get("updated_since"); value != "" {
+		updatedSince, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return opts, fmt.Errorf("invalid updated_since format: expected RFC3339 timestamp")
+		}
+		opts.updatedSince = updatedSince
+	}
+
+	return opts, nil
+}
+
+// matches reports whether a server passes the search, version and updated_since filters
+func (opts listOptions) matches(server upstream.ServerJSON) bool {
+	if opts.search != "" && !strings.Contains(strings.ToLower(server.Name), opts.search) {
+		return false
+	}
+	if opts.version != "" && !matchesVersion(server, opts.version) {
+		return false
+	}
+	return opts.updatedSince.IsZero() || serverUpdatedAt(server).After(opts.updatedSince)
+}
+
+// handleListServers lists servers with cursor pagination and optional filters:
+// search (case-insensitive substring of the name), version ("latest" or an exact
+// version) and updated_since (RFC3339 timestamp)
+func (s *Server) handleListServers(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseListOptions(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	matched := []upstream.ServerJSON{}
+	for _, server := range s.Servers() {
+		if opts.matches(server) {
+			matched = append(matched, server)
+		}
+	}
+
+	page, nextCursor, ok := paginate(matched, opts.cursor, opts.limit)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid cursor parameter")
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, upstream.ServerListResponse{
+		Servers: page,
+		Metadata: &upstream.Metadata{
+			NextCursor: nextCursor,
+			Count:      len(page),
+			Total:      len(matched),
+		},
+	})
+}
+
+// paginate returns the page of servers after the cursor and the cursor of the next page.
+// The cursor is the ID of the last server of the previous page.
+func paginate(servers []upstream.ServerJSON, cursor string, limit int) ([]upstream.ServerJSON, string, bool) {
+	start := 0
+	if cursor != "" {
+		start = -1
+		for i, server := range servers {
+			if serverID(server) == cursor {
+				start = i + 1
+				break
+			}
+		}
+		if start < 0 {
+			return nil, "", false
+		}
+	}
+
+	end := min(start+limit, len(servers))
+	page := servers[start:end]
+
+	var nextCursor string
+	if end < len(servers) && len(page) > 0 {
+		nextCursor = serverID(page[len(page)-1])
+	}
+	return page, nextCursor, true
+}
+
+// handleGetServer returns a single server by registry ID, full name or short name
+func (s *Server) handleGetServer(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	servers := s.Servers()
+	for _, server := range servers {
+		if serverID(server) == id {
+			writeJSON(w, r, http.StatusOK, server)
+			return
+		}
+	}
+
+	// A name resolves to the latest version, or to the first version if none is marked latest
+	var found *upstream.ServerJSON
+	for i, server := range servers {
+		if server.Name != id && shortName(server.Name) != id {
+			continue
+		}
+		if found == nil || (matchesVersion(server, "latest") && !matchesVersion(*found, "latest")) {
+			found = &servers[i]
+		}
+	}
+	if found == nil {
+		writeError(w, http.StatusNotFound, "server not found")
+		return
+	}
+	writeJSON(w, r, http.StatusOK, *found)
+}
+
+// handleHealth reports that the server is up
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, r, http.StatusOK, map[string]interface{}{
+		"status":  "ok",
+		"servers": len(s.Servers()),
+	})
+}
+
+// matchesVersion reports whether a server matches the version filter
+func matchesVersion(server upstream.ServerJSON, version string) bool {
+	if version == "latest" {
+		return server.Meta == nil || server.Meta.Official == nil || server.Meta.Official.IsLatest
+	}
+	return server.Version == version
+}
+
+// serverID returns the registry-assigned ID of a server
+func serverID(server upstream.ServerJSON) string {
+	if server.Meta == nil || server.Meta.Official == nil {
+		return server.Name
+	}
+	return server.Meta.Official.ID
+}
+
+// serverUpdatedAt returns the last update time of a server
+func serverUpdatedAt(server upstream.ServerJSON) time.Time {
+	if server.Meta == nil || server.Meta.Official == nil {
+		return time.Time{}
+	}
+	if server.Meta.Official.UpdatedAt.IsZero() {
+		return server.Meta.Official.PublishedAt
+	}
+	return server.Meta.Official.UpdatedAt
+}
+
+// shortName strips the namespace from a reverse-DNS server name
+func shortName(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// writeJSON writes a JSON response with a weak ETag, answering If-None-Match with
+// 304 Not Modified and compressing the body if the client accepts gzip
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, value interface{}) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to encode response")
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+
+	header := w.Header()
+	header.Set("ETag", etag)
+	header.Set("Cache-Control", "no-cache")
+	header.Add("Vary", "Accept-Encoding")
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	header.Set("Content-Type", "application/json")
+	if !acceptsGzip(r) {
+		header.Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(status)
+		if r.Method != http.MethodHead {
+			_, _ = w.Write(body)
+		}
+		return
+	}
+
+	header.Set("Content-Encoding", "gzip")
+	w.WriteHeader(status)
+	if r.Method == http.MethodHead {
+		return
+	}
+	gz := gzip.NewWriter(w)
+	_, _ = gz.Write(body)
+	_ = gz.Close()
+}
+
+// writeError writes an RFC 7807 problem response like the upstream registry
+func writeError(w http.ResponseWriter, status int, detail string) {
+	body, _ := json.Marshal(map[string]interface{}{
+		"title":  http.StatusText(status),
+		"status": status,
+		"detail": detail,
+	})
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+// etagMatches reports whether an If-None-Match header matches the ETag, using weak comparison
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsGzip reports whether the client accepts gzip-encoded responses
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(encoding), ";")
+		if strings.EqualFold(strings.TrimSpace(name), "gzip") && strings.ReplaceAll(params, " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+package server
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	upstream "github.com/modelcontextprotocol/registry/pkg/api/v0"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testServers(n int) []upstream.ServerJSON {
+	servers := make([]upstream.ServerJSON, 0, n)
+	for i := 0; i < n; i++ {
+		servers = append(servers, upstream.ServerJSON{
+			Name:        fmt.Sprintf("io.stacklok.toolhive/server-%02d", i),
+			Description: "Test server",
+			Version:     "1.0.0",
+			Meta: &upstream.ServerMeta{
+				Official: &upstream.RegistryExtensions{
+					ID:          fmt.Sprintf("id-%02d", i),
+					PublishedAt: time.Unix(int64(i), 0).UTC(),
+					UpdatedAt:   time.Unix(int64(i), 0).UTC(),
+					IsLatest:    true,
+				},
+			},
+		})
+	}
+	return servers
+}
+
+func get(t *testing.T, handler http.Handler, target string, headers map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer_ListServersPagination(t *testing.T) {
+	t.Parallel()
+	srv := New(testServers(5))
+
+	var names []string
+	cursor := ""
+	for pages := 0; pages < 10; pages++ {
+		rec := get(t, srv, "/v0/servers?limit=2&cursor="+cursor, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var response upstream.ServerListResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, 5, response.Metadata.Total)
+		for _, server := range response.Servers {
+			names = append(names, server.Name)
+		}
+
+		cursor = response.Metadata.NextCursor
+		if cursor == "" {
+			break
+		}
+	}
+
+	assert.Len(t, names, 5)
+	assert.Equal(t, "io.stacklok.toolhive/server-04", names[4])
+
+	assert.Equal(t, http.StatusBadRequest, get(t, srv, "/v0/servers?cursor=unknown", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, get(t, srv, "/v0/servers?limit=1000", nil).Code)
+}
+
+func TestServer_ListServersFilters(t *testing.T) {
+	t.Parallel()
+	srv := New(testServers(12))
+
+	var response upstream.ServerListResponse
+	rec := get(t, srv, "/v0/servers?search=SERVER-1", nil)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Metadata.Total)
+
+	rec = get(t, srv, "/v0/servers?updated_since="+time.Unix(9, 0).UTC().Format(time.RFC3339), nil)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Metadata.Total)
+
+	rec = get(t, srv, "/v0/servers?version=2.0.0", nil)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Empty(t, response.Servers)
+}
+
+func TestServer_GetServer(t *testing.T) {
+	t.Parallel()
+	srv := New(testServers(3))
+
+	for _, id := range []string{"id-01", "io.stacklok.toolhive/server-01", "server-01"} {
+		rec := get(t, srv, "/v0/servers/"+id, nil)
+		require.Equal(t, http.StatusOK, rec.Code, id)
+		var server upstream.ServerJSON
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &server))
+		assert.Equal(t, "io.stacklok.toolhive/server-01", server.Name)
+	}
+
+	assert.Equal(t, http.StatusNotFound, get(t, srv, "/v0/servers/missing", nil).Code)
+}
+
+func TestServer_GetServerPrefersLatest(t *testing.T) {
+	t.Parallel()
+	servers := testServers(2)
+	servers[1].Name = servers[0].Name
+	servers[1].Version = "2.0.0"
+	servers[0].Meta.Official.IsLatest = false
+
+	srv := New(servers)
+	for _, id := range []string{"io.stacklok.toolhive/server-00", "server-00"} {
+		rec := get(t, srv, "/v0/servers/"+id, nil)
+		require.Equal(t, http.StatusOK, rec.Code, id)
+		var server upstream.ServerJSON
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &server))
+		assert.Equal(t, "2.0.0", server.Version)
+	}
+
+	// Older versions stay reachable by ID
+	rec := get(t, srv, "/v0/servers/id-00", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var server upstream.ServerJSON
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &server))
+	assert.Equal(t, "1.0.0", server.Version)
+}
+
+func TestServer_ETagAndGzip(t *testing.T) {
+	t.Parallel()
+	srv := New(testServers(3))
+
+	rec := get(t, srv, "/v0/servers", nil)
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	rec = get(t, srv, "/v0/servers", map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.Bytes())
+
+	// Updating the served servers changes the ETag
+	srv.Update(testServers(4))
+	rec = get(t, srv, "/v0/servers", map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = get(t, srv, "/v0/servers", map[string]string{"Accept-Encoding": "gzip"})
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	reader, err := gzip.NewReader(rec.Body)
+	require.NoError(t, err)
+	var response upstream.ServerListResponse
+	require.NoError(t, json.NewDecoder(reader).Decode(&response))
+	assert.Len(t, response.Servers, 4)
+}
@@ -3,6 +3,8 @@ package toolhive
 import (
 	"fmt"
 
+	"github.com/stacklok/toolhive/pkg/registry"
+
 	"github.com/stacklok/toolhive-registry/pkg/types"
 )
 
@@ -53,8 +55,10 @@ func (b *CommandBuilder) Build() []string {
 	return b.args
 }
 
-// BuildRunCommand builds the thv run command arguments from a spec
-func BuildRunCommand(spec *types.RegistryEntry, tempName, image string) []string {
+// BuildRunCommand builds the thv run command arguments from a spec. Env vars named in env get
+// their injected values from the env file at envFile, in place of the defaults and placeholders
+// of the spec, so that the values never show up in the arguments.
+func BuildRunCommand(spec *types.RegistryEntry, tempName, image string, env map[string]string, envFile string) []string {
 	builder := NewCommandBuilder("run")
 	builder.AddFlag("--name", tempName)
 
@@ -63,24 +67,8 @@ func BuildRunCommand(spec *types.RegistryEntry, tempName, image string) []string
 		builder.AddFlag("--transport", spec.ImageMetadata.Transport)
 
 		// Add environment variables
-		if spec.ImageMetadata.EnvVars != nil {
-			for _, envVar := range spec.ImageMetadata.EnvVars {
-				// Precedence: explicit default from spec > required flag > secret flag
-				if envVar.Default != "" {
-					builder.AddEnvVar(envVar.Name, envVar.Default)
-					continue
-				}
-				if envVar.Required {
-					// Inject a dummy value to allow server startup and tool discovery
-					builder.AddEnvVar(envVar.Name, "placeholder")
-					continue
-				}
-				if envVar.Secret {
-					// Even when not required, inject a dummy for secrets to surface optional tools
-					builder.AddEnvVar(envVar.Name, "placeholder")
-				}
-			}
-		}
+		addEnvVars(builder, spec.ImageMetadata.EnvVars, env)
+		builder.AddFlag("--env-file", envFile)
 
 		// Add permission profile
 		if spec.Permissions != nil && spec.Permissions.Network != nil {
@@ -104,3 +92,27 @@ func BuildRunCommand(spec *types.RegistryEntry, tempName, image string) []string
 
 	return builder.Build()
 }
+
+// addEnvVars adds the env vars of a spec that have no injected value in env, which the env
+// file sets instead
+func addEnvVars(builder *CommandBuilder, envVars []*registry.EnvVar, env map[string]string) {
+	for _, envVar := range envVars {
+		// Precedence: injected value > explicit default from spec > required flag > secret flag
+		if _, ok := env[envVar.Name]; ok {
+			continue
+		}
+		if envVar.Default != "" {
+			builder.AddEnvVar(envVar.Name, envVar.Default)
+			continue
+		}
+		if envVar.Required {
+			// Inject a dummy value to allow server startup and tool discovery
+			builder.AddEnvVar(envVar.Name, "placeholder")
+			continue
+		}
+		if envVar.Secret {
+			// Even when not required, inject a dummy for secrets to surface optional tools
+			builder.AddEnvVar(envVar.Name, "placeholder")
+		}
+	}
+}
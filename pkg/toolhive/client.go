@@ -2,6 +2,9 @@
 package toolhive
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"os/exec"
 	"strings"
@@ -12,10 +15,23 @@ import (
 	"github.com/stacklok/toolhive-registry/pkg/types"
 )
 
+const (
+	// DefaultReadyTimeout is how long RunServer waits for a started server to answer
+	DefaultReadyTimeout = 60 * time.Second
+	// DefaultReadyInterval is the delay before RunServer checks a started server again
+	DefaultReadyInterval = 500 * time.Millisecond
+
+	// maxReadyInterval caps the exponential backoff between readiness checks
+	maxReadyInterval = 5 * time.Second
+)
+
 // Client represents a ToolHive client
 type Client struct {
-	thvPath string
-	verbose bool
+	thvPath       string
+	verbose       bool
+	readyTimeout  time.Duration
+	readyInterval time.Duration
+	secrets       *Secrets
 }
 
 // NewClient creates a new ToolHive client
@@ -30,13 +46,28 @@ func NewClient(thvPath string, verbose bool) (*Client, error) {
 	}
 
 	return &Client{
-		thvPath: thvPath,
-		verbose: verbose,
+		thvPath:       thvPath,
+		verbose:       verbose,
+		readyTimeout:  DefaultReadyTimeout,
+		readyInterval: DefaultReadyInterval,
 	}, nil
 }
 
-// RunServer starts an MCP server from a spec
-func (c *Client) RunServer(spec *types.RegistryEntry, serverName string) (string, error) {
+// SetReadiness sets how long RunServer waits for a started server to answer and the delay
+// before the first retry, which doubles after every failed check
+func (c *Client) SetReadiness(timeout, interval time.Duration) {
+	c.readyTimeout = timeout
+	c.readyInterval = interval
+}
+
+// SetSecrets sets the credentials injected into the servers RunServer starts
+func (c *Client) SetSecrets(secrets *Secrets) {
+	c.secrets = secrets
+}
+
+// RunServer starts an MCP server from a spec and waits until it answers or ctx is done. A
+// server that doesn't become ready is stopped and removed again.
+func (c *Client) RunServer(ctx context.Context, spec *types.RegistryEntry, serverName string) (string, error) {
 	// Get the image from the spec
 	var image string
 	if spec.IsImage() && spec.ImageMetadata != nil {
@@ -52,35 +83,162 @@ func (c *Client) RunServer(spec *types.RegistryEntry, serverName string) (string
 	}
 
 	if c.verbose {
-		logger.Debugf("Using thv binary: %s", c.thvPath)
-		logger.Debugf("Running MCP server from image: %s", image)
+		logger.Infof("Using thv binary: %s", c.thvPath)
+		logger.Infof("Running MCP server from image: %s", image)
 	}
 
 	// Build the run command
-	tempName := fmt.Sprintf("temp-%s-%d", serverName, time.Now().Unix())
-	runArgs := BuildRunCommand(spec, tempName, image)
+	tempName, err := TempServerName(serverName)
+	if err != nil {
+		return "", err
+	}
+	if err := c.start(spec, serverName, tempName, image); err != nil {
+		// thv may have created the workload before failing, so it is removed all the same
+		c.Cleanup(tempName)
+		return "", err
+	}
 
+	if err := c.waitReady(ctx, tempName); err != nil {
+		c.Cleanup(tempName)
+		return "", err
+	}
+
+	return tempName, nil
+}
+
+// start runs thv run for a server, with the values injected into it in an env file that is
+// removed again once thv has read it
+func (c *Client) start(spec *types.RegistryEntry, serverName, tempName, image string) error {
+	env := c.secrets.ForServer(serverName, spec)
+	var envFile string
+	if len(env) > 0 {
+		var remove func()
+		var err error
+		if envFile, remove, err = writeEnvFile(env); err != nil {
+			return err
+		}
+		defer remove()
+	}
+
+	runArgs := BuildRunCommand(spec, tempName, image, env, envFile)
 	if c.verbose {
-		logger.Debugf("Running command: thv %s", strings.Join(runArgs, " "))
+		logger.Infof("Running command: thv %s", strings.Join(runArgs, " "))
 	}
 
 	runCmd := exec.Command(c.thvPath, runArgs...) // #nosec G204 - thvPath is validated in NewClient
 	runOutput, err := runCmd.CombinedOutput()
 	if err != nil {
-		return "", fmt.Errorf("failed to start MCP server: %w\nOutput: %s", err, string(runOutput))
+		return fmt.Errorf("failed to start MCP server: %w\nOutput: %s", err, c.secrets.Redact(string(runOutput)))
 	}
+	return nil
+}
 
-	// Give the server time to start
-	time.Sleep(5 * time.Second)
+// waitReady polls a started server until it answers a tools listing, backing off
+// exponentially, and gives up early when ctx is done. If it doesn't answer in time, the
+// error includes the server's logs.
+func (c *Client) waitReady(ctx context.Context, serverName string) error {
+	start := time.Now()
+	deadline := start.Add(c.readyTimeout)
+	interval := c.readyInterval
+	for attempt := 1; ; attempt++ {
+		_, err := c.ListTools(serverName)
+		if err == nil {
+			if c.verbose {
+				logger.Infof("Server %s ready after %s (%d checks)", serverName, time.Since(start).Round(time.Millisecond), attempt)
+			}
+			return nil
+		}
 
-	return tempName, nil
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			err = fmt.Errorf("server %s not ready after %s: %w", serverName, c.readyTimeout, err)
+			logs, logErr := c.Logs(serverName)
+			if logErr != nil {
+				return fmt.Errorf("%w\nFailed to fetch logs: %v", err, logErr)
+			}
+			return fmt.Errorf("%w\nLogs:\n%s", err, logs)
+		}
+		if c.verbose {
+			logger.Infof("Server %s not ready yet, checking again in %s", serverName, interval)
+		}
+		timer := time.NewTimer(min(interval, remaining))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("stopped waiting for server %s: %w", serverName, ctx.Err())
+		case <-timer.C:
+		}
+		interval = min(interval*2, maxReadyInterval)
+	}
+}
+
+// Cleanup stops and removes a temporary server, logging failures
+func (c *Client) Cleanup(serverName string) {
+	if err := c.StopServer(serverName); err != nil {
+		logger.Warnf("Failed to stop temporary server %s: %v", serverName, err)
+	}
+	if err := c.RemoveServer(serverName); err != nil {
+		logger.Warnf("Failed to remove temporary server %s: %v", serverName, err)
+	}
+}
+
+// TempServerName returns a unique workload name for a temporary run of a server, so that
+// servers started in parallel or in quick succession never clash
+func TempServerName(serverName string) (string, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate temporary server name: %w", err)
+	}
+	return fmt.Sprintf("temp-%s-%d-%s", serverName, time.Now().Unix(), hex.EncodeToString(suffix)), nil
 }
 
 // ListTools queries a running MCP server for its tools
 func (c *Client) ListTools(serverName string) ([]string, error) {
+	output, err := c.list(serverName, "tools")
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseToolsJSON(output)
+}
+
+// ListToolDefinitions queries a running MCP server for its tools with their descriptions,
+// argument schemas and annotations
+func (c *Client) ListToolDefinitions(serverName string) ([]types.ToolDefinition, error) {
+	output, err := c.list(serverName, "tools")
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseToolDefinitionsJSON(output)
+}
+
+// ListResources queries a running MCP server for its resources
+func (c *Client) ListResources(serverName string) ([]types.Resource, error) {
+	output, err := c.list(serverName, "resources")
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseResourcesJSON(output)
+}
+
+// ListPrompts queries a running MCP server for its prompt templates
+func (c *Client) ListPrompts(serverName string) ([]types.Prompt, error) {
+	output, err := c.list(serverName, "prompts")
+	if err != nil {
+		return nil, err
+	}
+
+	return ParsePromptsJSON(output)
+}
+
+// list returns the output of thv mcp list <kind> for a running server, where kind is
+// tools, resources or prompts
+func (c *Client) list(serverName, kind string) (string, error) {
 	listArgs := NewCommandBuilder("mcp").
 		AddPositional("list").
-		AddPositional("tools").
+		AddPositional(kind).
 		AddFlag("--server", serverName).
 		AddFlag("--format", "json").
 		Build()
@@ -88,13 +246,14 @@ func (c *Client) ListTools(serverName string) ([]string, error) {
 	listCmd := exec.Command(c.thvPath, listArgs...) // #nosec G204 - thvPath is validated in NewClient
 	output, err := listCmd.CombinedOutput()
 	if err != nil {
-		return nil, fmt.Errorf("thv mcp list failed: %w\nOutput: %s", err, string(output))
+		return "", fmt.Errorf("thv mcp list failed: %w\nOutput: %s", err, c.secrets.Redact(string(output)))
 	}
 
-	return ParseToolsJSON(string(output))
+	// Servers can echo their configuration back, which must not end up in spec.yaml
+	return c.secrets.Redact(string(output)), nil
 }
 
-// Logs retrieves logs from a running MCP server
+// Logs retrieves logs from a running MCP server, with the values injected into it masked
 func (c *Client) Logs(serverName string) (string, error) {
 	logsArgs := NewCommandBuilder("logs").
 		AddFlag("--follow", "false").
@@ -104,10 +263,10 @@ func (c *Client) Logs(serverName string) (string, error) {
 	logsCmd := exec.Command(c.thvPath, logsArgs...) // #nosec G204 - thvPath is validated in NewClient
 	output, err := logsCmd.CombinedOutput()
 	if err != nil {
-		return "", fmt.Errorf("thv logs failed: %w\nOutput: %s", err, string(output))
+		return "", fmt.Errorf("thv logs failed: %w\nOutput: %s", err, c.secrets.Redact(string(output)))
 	}
 
-	return string(output), nil
+	return c.secrets.Redact(string(output)), nil
 }
 
 // StopServer stops a running MCP server
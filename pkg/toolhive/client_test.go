@@ -0,0 +1,201 @@
+package toolhive
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// newFakeThv returns a client for a thv that runs the given shell commands after recording
+// its arguments, one invocation per line, in the returned calls file, which the commands
+// can read as $calls
+func newFakeThv(t *testing.T, commands string) (*Client, string) {
+	t.Helper()
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	script := "#!/bin/sh\ncalls=" + calls + "\necho \"$@\" >> \"$calls\"\n" + commands + "\n"
+	path := filepath.Join(dir, "thv")
+	require.NoError(t, os.WriteFile(path, []byte(script), 0700)) // #nosec G306 - the script must be executable
+
+	client, err := NewClient(path, false)
+	require.NoError(t, err)
+	return client, calls
+}
+
+// readCalls returns the recorded thv invocations
+func readCalls(t *testing.T, calls string) []string {
+	t.Helper()
+	data, err := os.ReadFile(calls) // #nosec G304 - path is created by the test
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func testImageSpec() *types.RegistryEntry {
+	return &types.RegistryEntry{
+		ImageMetadata: &toolhiveRegistry.ImageMetadata{
+			BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{Transport: "stdio", Tools: []string{"read"}},
+			Image:              "test/server:1.0.0",
+		},
+	}
+}
+
+func TestRunServer_CleansUpFailedRun(t *testing.T) {
+	t.Parallel()
+
+	client, calls := newFakeThv(t, `[ "$1" = run ] && { echo "image pull failed"; exit 1; }; exit 0`)
+	_, err := client.RunServer(context.Background(), testImageSpec(), "server")
+	require.ErrorContains(t, err, "image pull failed")
+
+	// thv may have created the workload before failing, so it is stopped and removed
+	invocations := readCalls(t, calls)
+	require.Len(t, invocations, 3)
+	assert.True(t, strings.HasPrefix(invocations[0], "run "))
+	tempName := strings.Fields(invocations[1])[1]
+	assert.True(t, strings.HasPrefix(tempName, "temp-server-"))
+	assert.Equal(t, []string{"stop " + tempName, "rm " + tempName}, invocations[1:])
+}
+
+// copyEnvFile makes thv run keep a copy of the env file it is given next to $calls
+const copyEnvFile = `if [ "$1" = run ]; then
+  for arg; do [ "$prev" = --env-file ] && cp "$arg" "$calls.env"; prev=$arg; done
+fi`
+
+func TestRunServer_InjectsSecrets(t *testing.T) {
+	t.Parallel()
+
+	client, calls := newFakeThv(t, copyEnvFile+`
+[ "$1" = run ] && { echo "token ghp_abc rejected"; exit 1; }
+[ "$1" = logs ] && echo "using ghp_abc"
+exit 0`)
+	client.SetSecrets(&Secrets{servers: map[string]map[string]string{"github": {"GITHUB_TOKEN": "ghp_abc"}}})
+
+	_, err := client.RunServer(context.Background(), testImageSpec(), "github")
+	require.ErrorContains(t, err, "token **** rejected")
+	assert.NotContains(t, err.Error(), "ghp_abc")
+
+	// The value reaches thv in an env file that is removed again, never in its arguments
+	invocations := readCalls(t, calls)
+	assert.NotContains(t, strings.Join(invocations, "\n"), "ghp_abc")
+	runArgs := strings.Fields(invocations[0])
+	envFile := runArgs[slices.Index(runArgs, "--env-file")+1]
+	assert.NoFileExists(t, envFile)
+	data, err := os.ReadFile(calls + ".env") // #nosec G304 - path is created by the test
+	require.NoError(t, err)
+	assert.Equal(t, "GITHUB_TOKEN=ghp_abc\n", string(data))
+
+	logs, err := client.Logs("server")
+	require.NoError(t, err)
+	assert.Equal(t, "using ****\n", logs)
+}
+
+func TestWaitReady_RedactsSecrets(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newFakeThv(t, `if [ "$1" = mcp ]; then echo "bad token ghp_abc"; exit 1; fi
+[ "$1" = logs ] && echo "using ghp_abc"
+exit 0`)
+	client.SetSecrets(&Secrets{servers: map[string]map[string]string{"github": {"GITHUB_TOKEN": "ghp_abc"}}})
+	client.SetReadiness(50*time.Millisecond, 10*time.Millisecond)
+
+	_, err := client.RunServer(context.Background(), testImageSpec(), "github")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad token ****")
+	assert.Contains(t, err.Error(), "Logs:\nusing ****")
+	assert.NotContains(t, err.Error(), "ghp_abc")
+}
+
+func TestListToolDefinitions_RedactsSecrets(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newFakeThv(t, `[ "$1" = mcp ] && echo '{"tools":[{"name":"read","description":"Reads with ghp_abc"}]}'
+exit 0`)
+	secrets := &Secrets{servers: map[string]map[string]string{"github": {"GITHUB_TOKEN": "ghp_abc"}}}
+	secrets.ForServer("github", testImageSpec())
+	client.SetSecrets(secrets)
+
+	// A server echoing its configuration doesn't leak it into the spec
+	definitions, err := client.ListToolDefinitions("server")
+	require.NoError(t, err)
+	assert.Equal(t, []types.ToolDefinition{{
+		Name: "read", Description: "Reads with ****", SideEffect: types.SideEffectUnknown,
+	}}, definitions)
+}
+
+// listFailsUntil makes thv mcp list fail until the given attempt; thv logs prints a log line
+func listFailsUntil(attempt int) string {
+	return `if [ "$1" = mcp ]; then
+  [ "$(grep -c '^mcp' "$calls")" -ge ` + strconv.Itoa(attempt) + ` ] || { echo "connection refused"; exit 1; }
+  echo '{"tools":[{"name":"read"}]}'
+fi
+[ "$1" = logs ] && echo "server log line"
+exit 0`
+}
+
+// countCalls returns how many recorded thv invocations start with prefix
+func countCalls(t *testing.T, calls, prefix string) int {
+	t.Helper()
+	count := 0
+	for _, invocation := range readCalls(t, calls) {
+		if strings.HasPrefix(invocation, prefix) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestWaitReady_BacksOff(t *testing.T) {
+	t.Parallel()
+
+	client, calls := newFakeThv(t, listFailsUntil(4))
+	client.SetReadiness(time.Minute, 20*time.Millisecond)
+
+	start := time.Now()
+	require.NoError(t, client.waitReady(context.Background(), "server"))
+
+	// Three failed checks wait 20ms, 40ms and 80ms before the next one
+	assert.Equal(t, 4, countCalls(t, calls, "mcp list tools"))
+	assert.GreaterOrEqual(t, time.Since(start), 140*time.Millisecond)
+}
+
+func TestWaitReady_TimesOutWithLogs(t *testing.T) {
+	t.Parallel()
+
+	client, calls := newFakeThv(t, listFailsUntil(1000))
+	client.SetReadiness(100*time.Millisecond, 10*time.Millisecond)
+
+	err := client.waitReady(context.Background(), "server")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "server server not ready after 100ms")
+	assert.Contains(t, err.Error(), "connection refused")
+	assert.Contains(t, err.Error(), "Logs:\nserver log line")
+	assert.Equal(t, 1, countCalls(t, calls, "logs"))
+}
+
+func TestWaitReady_StopsWhenContextIsDone(t *testing.T) {
+	t.Parallel()
+
+	client, calls := newFakeThv(t, listFailsUntil(1000))
+	client.SetReadiness(time.Minute, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.RunServer(ctx, testImageSpec(), "server")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 10*time.Second)
+
+	// The server that never became ready is removed
+	assert.Equal(t, 1, countCalls(t, calls, "stop "))
+	assert.Equal(t, 1, countCalls(t, calls, "rm "))
+}
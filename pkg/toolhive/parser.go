@@ -3,11 +3,14 @@ package toolhive
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
 
 	"github.com/stacklok/toolhive/pkg/logger"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
 )
 
 // Tool represents an MCP tool
@@ -18,6 +21,10 @@ type Tool struct {
 	Annotations map[string]interface{} `json:"annotations,omitempty"`
 }
 
+// ErrUnparsableOutput is returned when the output of thv mcp list isn't the JSON it was
+// asked for, such as from versions of thv that only print text
+var ErrUnparsableOutput = errors.New("thv output is not valid JSON")
+
 // MCPListOutput represents the JSON output from thv mcp list
 type MCPListOutput struct {
 	Tools []Tool `json:"tools"`
@@ -51,6 +58,122 @@ func ParseToolsJSON(output string) ([]string, error) {
 	return tools, nil
 }
 
+// ParseToolDefinitionsJSON parses the full tool definitions from the JSON output of
+// thv mcp list tools --format json, sorted by name
+func ParseToolDefinitionsJSON(output string) ([]types.ToolDefinition, error) {
+	var result MCPListOutput
+	if err := unmarshalListOutput(output, &result); err != nil {
+		return nil, err
+	}
+
+	definitions := make([]types.ToolDefinition, 0, len(result.Tools))
+	for _, tool := range result.Tools {
+		definitions = append(definitions, types.ToolDefinition{
+			Name:        tool.Name,
+			Description: tool.Description,
+			InputSchema: tool.InputSchema,
+			Annotations: tool.Annotations,
+			SideEffect:  types.ClassifyTool(tool.Annotations),
+		})
+	}
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].Name < definitions[j].Name
+	})
+
+	return definitions, nil
+}
+
+// MCPResourcesOutput represents the JSON output from thv mcp list resources
+type MCPResourcesOutput struct {
+	Resources []struct {
+		URI         string `json:"uri"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		MIMEType    string `json:"mimeType"`
+	} `json:"resources"`
+}
+
+// MCPPromptsOutput represents the JSON output from thv mcp list prompts
+type MCPPromptsOutput struct {
+	Prompts []struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Arguments   []struct {
+			Name        string `json:"name"`
+			Description string `json:"description"`
+			Required    bool   `json:"required"`
+		} `json:"arguments"`
+	} `json:"prompts"`
+}
+
+// ParseResourcesJSON parses the JSON output of thv mcp list resources --format json,
+// sorted by URI
+func ParseResourcesJSON(output string) ([]types.Resource, error) {
+	var result MCPResourcesOutput
+	if err := unmarshalListOutput(output, &result); err != nil {
+		return nil, err
+	}
+
+	resources := make([]types.Resource, 0, len(result.Resources))
+	for _, resource := range result.Resources {
+		resources = append(resources, types.Resource{
+			URI:         resource.URI,
+			Name:        resource.Name,
+			Description: resource.Description,
+			MIMEType:    resource.MIMEType,
+		})
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].URI < resources[j].URI
+	})
+
+	return resources, nil
+}
+
+// ParsePromptsJSON parses the JSON output of thv mcp list prompts --format json,
+// sorted by name
+func ParsePromptsJSON(output string) ([]types.Prompt, error) {
+	var result MCPPromptsOutput
+	if err := unmarshalListOutput(output, &result); err != nil {
+		return nil, err
+	}
+
+	prompts := make([]types.Prompt, 0, len(result.Prompts))
+	for _, prompt := range result.Prompts {
+		var arguments []types.PromptArgument
+		for _, argument := range prompt.Arguments {
+			arguments = append(arguments, types.PromptArgument{
+				Name:        argument.Name,
+				Description: argument.Description,
+				Required:    argument.Required,
+			})
+		}
+		prompts = append(prompts, types.Prompt{
+			Name:        prompt.Name,
+			Description: prompt.Description,
+			Arguments:   arguments,
+		})
+	}
+	sort.Slice(prompts, func(i, j int) bool {
+		return prompts[i].Name < prompts[j].Name
+	})
+
+	return prompts, nil
+}
+
+// unmarshalListOutput decodes the JSON part of thv mcp list output, skipping any warning
+// messages printed before it
+func unmarshalListOutput(output string, result any) error {
+	jsonStart := strings.Index(output, "{")
+	if jsonStart == -1 {
+		return fmt.Errorf("%w: no JSON found in output", ErrUnparsableOutput)
+	}
+	if err := json.Unmarshal([]byte(output[jsonStart:]), result); err != nil {
+		return fmt.Errorf("%w: %w", ErrUnparsableOutput, err)
+	}
+	return nil
+}
+
 // ParseToolsText parses text output from thv mcp list (fallback parser)
 func ParseToolsText(output string) ([]string, error) {
 	var tools []string
@@ -0,0 +1,171 @@
+package toolhive
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// maskedValue replaces secret values in thv output and logs
+const maskedValue = "****"
+
+// Secrets holds real credentials to inject into servers started for tool discovery, in place
+// of the placeholders used for required and secret env vars. Values are never logged: they
+// reach thv in an env file rather than its arguments, and Redact masks them in its output.
+type Secrets struct {
+	// shared apply to the env vars every server declares, from a dotenv file
+	shared map[string]string
+	// servers apply to a single server, from a YAML file keyed by server name
+	servers map[string]map[string]string
+	// passthrough takes the env vars a server declares from the current environment
+	passthrough bool
+
+	mu sync.Mutex
+	// injected holds every value ForServer returned, for Redact
+	injected map[string]bool
+}
+
+// NewSecrets loads a secrets file, if path isn't empty. A .yaml or .yml file maps server names
+// to their env vars:
+//
+//	github:
+//	  GITHUB_PERSONAL_ACCESS_TOKEN: ghp_...
+//
+// Any other file is read as dotenv (NAME=value lines), whose values are injected into every
+// server that declares the env var. With passthrough, declared env vars that neither sets are
+// taken from the environment.
+func NewSecrets(path string, passthrough bool) (*Secrets, error) {
+	secrets := &Secrets{passthrough: passthrough}
+	if path == "" {
+		return secrets, nil
+	}
+
+	data, err := os.ReadFile(path) // #nosec G304 - path is given by the user
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets file: %w", err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &secrets.servers); err != nil {
+			// The YAML error may quote the offending line, which can hold a secret
+			return nil, fmt.Errorf("failed to parse secrets file %s: expected env vars keyed by server name", path)
+		}
+	default:
+		if secrets.shared, err = parseDotenv(data); err != nil {
+			return nil, fmt.Errorf("failed to parse secrets file %s: %w", path, err)
+		}
+	}
+	return secrets, nil
+}
+
+// parseDotenv parses NAME=value lines, skipping blank lines and comments. Values may be
+// quoted and lines may start with export.
+func parseDotenv(data []byte) (map[string]string, error) {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			// The line itself isn't included, as it may hold a secret
+			return nil, fmt.Errorf("line %d is not NAME=value", lineNumber)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[name] = value
+	}
+	return values, scanner.Err()
+}
+
+// ForServer returns the env vars to inject into a server. Its own values from a YAML secrets
+// file come first, then shared dotenv values and the environment for the env vars its spec
+// declares.
+func (s *Secrets) ForServer(serverName string, spec *types.RegistryEntry) map[string]string {
+	env := make(map[string]string)
+	if s == nil {
+		return env
+	}
+
+	if spec.ImageMetadata != nil {
+		for _, envVar := range spec.ImageMetadata.EnvVars {
+			if value, ok := s.shared[envVar.Name]; ok {
+				env[envVar.Name] = value
+			} else if value, ok := os.LookupEnv(envVar.Name); ok && s.passthrough && value != "" {
+				env[envVar.Name] = value
+			}
+		}
+	}
+	maps.Copy(env, s.servers[serverName])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.injected == nil {
+		s.injected = make(map[string]bool)
+	}
+	for _, value := range env {
+		if value != "" {
+			s.injected[value] = true
+		}
+	}
+	return env
+}
+
+// Redact masks every value injected into a server so far in text, such as the output and
+// logs of thv
+func (s *Secrets) Redact(text string) string {
+	if s == nil {
+		return text
+	}
+
+	s.mu.Lock()
+	values := slices.Collect(maps.Keys(s.injected))
+	s.mu.Unlock()
+	// Longer values go first, so that a value containing another one is masked whole
+	slices.SortFunc(values, func(a, b string) int { return len(b) - len(a) })
+	for _, value := range values {
+		text = strings.ReplaceAll(text, value, maskedValue)
+	}
+	return text
+}
+
+// writeEnvFile writes env vars to a file that only the current user can read, for thv run
+// --env-file, and returns its path and a function that removes it
+func writeEnvFile(env map[string]string) (string, func(), error) {
+	var content strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(env)) {
+		// thv reads one trimmed NAME=value per line
+		if value := env[name]; strings.ContainsAny(value, "\r\n") || strings.TrimSpace(value) != value {
+			return "", nil, fmt.Errorf("value of %s starts or ends with whitespace or spans lines", name)
+		}
+		fmt.Fprintf(&content, "%s=%s\n", name, env[name])
+	}
+
+	dir, err := os.MkdirTemp("", "thv-env-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create env file: %w", err)
+	}
+	remove := func() { _ = os.RemoveAll(dir) }
+	path := filepath.Join(dir, "env")
+	if err := os.WriteFile(path, []byte(content.String()), 0600); err != nil {
+		remove()
+		return "", nil, fmt.Errorf("failed to write env file: %w", err)
+	}
+	return path, remove, nil
+}
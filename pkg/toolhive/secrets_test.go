@@ -0,0 +1,227 @@
+package toolhive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func TestParseDotenv(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		data    string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name: "comments and blank lines",
+			data: "# tokens\n\nTOKEN=abc\n  # indented comment\n",
+			want: map[string]string{"TOKEN": "abc"},
+		},
+		{
+			name: "export",
+			data: "export TOKEN=abc\nexport  SPACED = def \n",
+			want: map[string]string{"TOKEN": "abc", "SPACED": "def"},
+		},
+		{
+			name: "quotes",
+			data: "DOUBLE=\"a b\"\nSINGLE='c d'\nMIXED=\"e'\nLONE=\"\nEMPTY=\n",
+			want: map[string]string{"DOUBLE": "a b", "SINGLE": "c d", "MIXED": "\"e'", "LONE": "\"", "EMPTY": ""},
+		},
+		{
+			name: "equals sign in the value",
+			data: "URL=https://example.com/?a=b\n",
+			want: map[string]string{"URL": "https://example.com/?a=b"},
+		},
+		{
+			name:    "no equals sign",
+			data:    "TOKEN=abc\nghp_secret\n",
+			wantErr: "line 2 is not NAME=value",
+		},
+		{
+			name:    "no name",
+			data:    "=ghp_secret\n",
+			wantErr: "line 1 is not NAME=value",
+		},
+		{
+			name:    "space in the name",
+			data:    "MY TOKEN=ghp_secret\n",
+			wantErr: "line 1 is not NAME=value",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			values, err := parseDotenv([]byte(tt.data))
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, values)
+		})
+	}
+}
+
+func TestNewSecrets(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	secrets, err := NewSecrets("", true)
+	require.NoError(t, err)
+	assert.True(t, secrets.passthrough)
+
+	yamlPath := filepath.Join(dir, "secrets.yaml")
+	require.NoError(t, os.WriteFile(yamlPath, []byte("github:\n  GITHUB_TOKEN: ghp_abc\n"), 0600))
+	secrets, err = NewSecrets(yamlPath, false)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]string{"github": {"GITHUB_TOKEN": "ghp_abc"}}, secrets.servers)
+	assert.Empty(t, secrets.shared)
+
+	envPath := filepath.Join(dir, ".env")
+	require.NoError(t, os.WriteFile(envPath, []byte("GITHUB_TOKEN=ghp_abc\n"), 0600))
+	secrets, err = NewSecrets(envPath, false)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"GITHUB_TOKEN": "ghp_abc"}, secrets.shared)
+	assert.Empty(t, secrets.servers)
+
+	// Parse errors don't quote the file, which holds secrets
+	malformed := filepath.Join(dir, "malformed.yml")
+	require.NoError(t, os.WriteFile(malformed, []byte("github: [ghp_abc\n"), 0600))
+	_, err = NewSecrets(malformed, false)
+	require.ErrorContains(t, err, "expected env vars keyed by server name")
+	assert.NotContains(t, err.Error(), "ghp_abc")
+}
+
+// specWithEnvVars returns an image spec that declares the given env vars
+func specWithEnvVars(envVars ...*toolhiveRegistry.EnvVar) *types.RegistryEntry {
+	spec := testImageSpec()
+	spec.ImageMetadata.EnvVars = envVars
+	return spec
+}
+
+func TestSecrets_ForServer(t *testing.T) {
+	t.Parallel()
+
+	secrets := &Secrets{
+		shared: map[string]string{"SHARED": "shared", "OVERRIDDEN": "shared", "UNDECLARED": "shared"},
+		servers: map[string]map[string]string{
+			"github": {"OVERRIDDEN": "own", "EXTRA": "own"},
+			"other":  {"SHARED": "other"},
+		},
+	}
+	spec := specWithEnvVars(
+		&toolhiveRegistry.EnvVar{Name: "SHARED"},
+		&toolhiveRegistry.EnvVar{Name: "OVERRIDDEN"},
+		&toolhiveRegistry.EnvVar{Name: "MISSING", Required: true},
+	)
+
+	// The server's own values win over shared ones, which only go to the env vars it declares
+	assert.Equal(t, map[string]string{"SHARED": "shared", "OVERRIDDEN": "own", "EXTRA": "own"},
+		secrets.ForServer("github", spec))
+	assert.Equal(t, map[string]string{"SHARED": "shared", "OVERRIDDEN": "shared"},
+		secrets.ForServer("fetch", spec))
+
+	var none *Secrets
+	assert.Empty(t, none.ForServer("github", spec))
+}
+
+func TestSecrets_ForServerPassthrough(t *testing.T) {
+	t.Setenv("TOOLHIVE_REGISTRY_TEST_FROM_ENV", "env")
+	t.Setenv("TOOLHIVE_REGISTRY_TEST_SHARED", "env")
+	t.Setenv("TOOLHIVE_REGISTRY_TEST_EMPTY", "")
+	t.Setenv("TOOLHIVE_REGISTRY_TEST_UNDECLARED", "env")
+	spec := specWithEnvVars(
+		&toolhiveRegistry.EnvVar{Name: "TOOLHIVE_REGISTRY_TEST_FROM_ENV"},
+		&toolhiveRegistry.EnvVar{Name: "TOOLHIVE_REGISTRY_TEST_SHARED"},
+		&toolhiveRegistry.EnvVar{Name: "TOOLHIVE_REGISTRY_TEST_EMPTY"},
+	)
+	shared := map[string]string{"TOOLHIVE_REGISTRY_TEST_SHARED": "shared"}
+
+	// The secrets file wins over the environment, and only declared env vars are passed
+	secrets := &Secrets{shared: shared, passthrough: true}
+	assert.Equal(t, map[string]string{
+		"TOOLHIVE_REGISTRY_TEST_FROM_ENV": "env",
+		"TOOLHIVE_REGISTRY_TEST_SHARED":   "shared",
+	}, secrets.ForServer("server", spec))
+
+	secrets = &Secrets{shared: shared}
+	assert.Equal(t, shared, secrets.ForServer("server", spec))
+}
+
+func TestSecrets_Redact(t *testing.T) {
+	t.Parallel()
+
+	secrets := &Secrets{servers: map[string]map[string]string{
+		"github": {"GITHUB_TOKEN": "ghp_abc", "GITHUB_URL": "https://ghp_abc.example.com", "EMPTY": ""},
+		"fetch":  {"API_KEY": "key123"},
+	}}
+	spec := testImageSpec()
+	const text = "token ghp_abc rejected by https://ghp_abc.example.com; key key123"
+
+	// Only values that were injected are known
+	assert.Equal(t, text, secrets.Redact(text))
+	secrets.ForServer("github", spec)
+	assert.Equal(t, "token **** rejected by ****; key key123", secrets.Redact(text))
+	secrets.ForServer("fetch", spec)
+	assert.Equal(t, "token **** rejected by ****; key ****", secrets.Redact(text))
+
+	var none *Secrets
+	assert.Equal(t, text, none.Redact(text))
+}
+
+func TestWriteEnvFile(t *testing.T) {
+	t.Parallel()
+
+	path, remove, err := writeEnvFile(map[string]string{"B": "two words", "A": "x=y"})
+	require.NoError(t, err)
+	data, err := os.ReadFile(path) // #nosec G304 - path is created by the test
+	require.NoError(t, err)
+	assert.Equal(t, "A=x=y\nB=two words\n", string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	remove()
+	assert.NoDirExists(t, filepath.Dir(path))
+
+	// thv would read a different value than the one given
+	for _, value := range []string{"line\nbreak", " padded"} {
+		_, _, err = writeEnvFile(map[string]string{"TOKEN": value})
+		assert.EqualError(t, err, "value of TOKEN starts or ends with whitespace or spans lines")
+	}
+}
+
+func TestBuildRunCommand_EnvVars(t *testing.T) {
+	t.Parallel()
+
+	spec := specWithEnvVars(
+		&toolhiveRegistry.EnvVar{Name: "INJECTED", Required: true, Secret: true, Default: "default"},
+		&toolhiveRegistry.EnvVar{Name: "DEFAULT", Required: true, Default: "default"},
+		&toolhiveRegistry.EnvVar{Name: "REQUIRED", Required: true},
+		&toolhiveRegistry.EnvVar{Name: "SECRET", Secret: true},
+		&toolhiveRegistry.EnvVar{Name: "OPTIONAL"},
+	)
+	env := map[string]string{"INJECTED": "ghp_abc", "UNDECLARED": "key123"}
+
+	// Injected values win over defaults and placeholders and are left to the env file
+	assert.Equal(t, []string{
+		"run", "--name", "temp", "--transport", "stdio",
+		"-e", "DEFAULT=default", "-e", "REQUIRED=placeholder", "-e", "SECRET=placeholder",
+		"--env-file", "/tmp/env", "test/server:1.0.0",
+	}, BuildRunCommand(spec, "temp", "test/server:1.0.0", env, "/tmp/env"))
+
+	assert.Equal(t, []string{
+		"run", "--name", "temp", "--transport", "stdio",
+		"-e", "INJECTED=default", "-e", "DEFAULT=default", "-e", "REQUIRED=placeholder", "-e", "SECRET=placeholder",
+		"test/server:1.0.0",
+	}, BuildRunCommand(spec, "temp", "test/server:1.0.0", nil, ""))
+}
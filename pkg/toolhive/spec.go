@@ -7,28 +7,82 @@ import (
 	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
 )
 
 // UpdateSpecTools updates the tools field in a spec file
 func UpdateSpecTools(path string, tools []string) error {
-	// Read the original file
+	toolsNode := &yaml.Node{
+		Kind:    yaml.SequenceNode,
+		Content: make([]*yaml.Node, 0, len(tools)),
+	}
+
+	for _, tool := range tools {
+		toolsNode.Content = append(toolsNode.Content, &yaml.Node{
+			Kind:  yaml.ScalarNode,
+			Value: tool,
+		})
+	}
+
+	return updateSpecField(path, "tools", toolsNode)
+}
+
+// UpdateSpecToolDefinitions updates the tool_definitions field in a spec file
+func UpdateSpecToolDefinitions(path string, definitions []types.ToolDefinition) error {
+	var definitionsNode yaml.Node
+	if err := definitionsNode.Encode(definitions); err != nil {
+		return fmt.Errorf("failed to encode tool definitions: %w", err)
+	}
+
+	return updateSpecField(path, "tool_definitions", &definitionsNode)
+}
+
+// UpdateSpecResources updates the resources field in a spec file
+func UpdateSpecResources(path string, resources []types.Resource) error {
+	var resourcesNode yaml.Node
+	if err := resourcesNode.Encode(resources); err != nil {
+		return fmt.Errorf("failed to encode resources: %w", err)
+	}
+
+	return updateSpecField(path, "resources", &resourcesNode)
+}
+
+// UpdateSpecPrompts updates the prompts field in a spec file
+func UpdateSpecPrompts(path string, prompts []types.Prompt) error {
+	var promptsNode yaml.Node
+	if err := promptsNode.Encode(prompts); err != nil {
+		return fmt.Errorf("failed to encode prompts: %w", err)
+	}
+
+	return updateSpecField(path, "prompts", &promptsNode)
+}
+
+// UpdateSpecResourceTemplates updates the resource_templates field in a spec file
+func UpdateSpecResourceTemplates(path string, templates []types.ResourceTemplate) error {
+	var templatesNode yaml.Node
+	if err := templatesNode.Encode(templates); err != nil {
+		return fmt.Errorf("failed to encode resource templates: %w", err)
+	}
+
+	return updateSpecField(path, "resource_templates", &templatesNode)
+}
+
+// updateSpecField replaces or adds a top-level field in a spec file, preserving the rest
+func updateSpecField(path, key string, value *yaml.Node) error {
 	data, err := os.ReadFile(path) // #nosec G304 - path is controlled by application
 	if err != nil {
 		return fmt.Errorf("failed to read file: %w", err)
 	}
 
-	// Parse with yaml.v3 to preserve structure
 	var doc yaml.Node
 	if err := yaml.Unmarshal(data, &doc); err != nil {
 		return fmt.Errorf("failed to parse YAML: %w", err)
 	}
-
-	// Update the tools field
-	if err := updateToolsInNode(&doc, tools); err != nil {
-		return fmt.Errorf("failed to update tools: %w", err)
+	if err := setFieldInNode(&doc, key, value); err != nil {
+		return fmt.Errorf("failed to update %s: %w", key, err)
 	}
 
-	// Marshal back preserving structure
 	var buf bytes.Buffer
 	encoder := yaml.NewEncoder(&buf)
 	encoder.SetIndent(2)
@@ -36,54 +90,31 @@ func UpdateSpecTools(path string, tools []string) error {
 		return fmt.Errorf("failed to encode YAML: %w", err)
 	}
 
-	// Write back to file
 	return os.WriteFile(path, buf.Bytes(), 0600)
 }
 
-// updateToolsInNode updates the tools field in the YAML node tree
-func updateToolsInNode(node *yaml.Node, tools []string) error {
+// setFieldInNode replaces the value of a field in the YAML node tree, adding the field at
+// the end if it doesn't exist
+func setFieldInNode(node *yaml.Node, key string, value *yaml.Node) error {
 	// Navigate to the document content
 	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
-		return updateToolsInNode(node.Content[0], tools)
+		return setFieldInNode(node.Content[0], key, value)
 	}
 
 	if node.Kind != yaml.MappingNode {
 		return fmt.Errorf("expected mapping node, got %v", node.Kind)
 	}
 
-	// Find or create tools section
-	toolsIndex := -1
 	for i := 0; i < len(node.Content); i += 2 {
-		if node.Content[i].Value == "tools" {
-			toolsIndex = i
-			break
+		if node.Content[i].Value == key {
+			// Replace the existing field
+			node.Content[i+1] = value
+			return nil
 		}
 	}
 
-	// Create new tools array node
-	toolsNode := &yaml.Node{
-		Kind:    yaml.SequenceNode,
-		Content: make([]*yaml.Node, 0, len(tools)),
-	}
-
-	for _, tool := range tools {
-		toolsNode.Content = append(toolsNode.Content, &yaml.Node{
-			Kind:  yaml.ScalarNode,
-			Value: tool,
-		})
-	}
-
-	if toolsIndex >= 0 {
-		// Replace existing tools
-		node.Content[toolsIndex+1] = toolsNode
-	} else {
-		// Add new tools section
-		node.Content = append(node.Content,
-			&yaml.Node{Kind: yaml.ScalarNode, Value: "tools"},
-			toolsNode,
-		)
-	}
-
+	// Add a new field
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
 	return nil
 }
 
@@ -21,6 +21,22 @@ const (
 	TierOfficial = "Official"
 )
 
+// Side effects of a tool, classified from its MCP annotations
+const (
+	// SideEffectReadOnly tools don't modify their environment (readOnlyHint)
+	SideEffectReadOnly = "read-only"
+	// SideEffectAdditive tools modify their environment but never destructively (destructiveHint false)
+	SideEffectAdditive = "additive"
+	// SideEffectDestructive tools may delete or overwrite data, which MCP assumes of any
+	// annotated tool that isn't read-only unless destructiveHint is false
+	SideEffectDestructive = "destructive"
+	// SideEffectUnknown tools have no annotations to classify them by
+	SideEffectUnknown = "unknown"
+)
+
+// SideEffects lists the valid tool side effects
+var SideEffects = []string{SideEffectReadOnly, SideEffectAdditive, SideEffectDestructive, SideEffectUnknown}
+
 // RegistryEntry is a unified type that can represent either an image-based or remote MCP server
 // It embeds either ImageMetadata or RemoteServerMetadata from toolhive based on what's in the spec.yaml
 type RegistryEntry struct {
@@ -33,6 +49,22 @@ type RegistryEntry struct {
 	// Extended fields for the registry (applies to both types)
 	Examples []Example `yaml:"examples,omitempty"`
 	License  string    `yaml:"license,omitempty"`
+
+	// Version is the server version published in the official format. Remote servers use it
+	// to set their version; for image-based servers it overrides the version of the image tag.
+	Version string `yaml:"version,omitempty"`
+
+	// ToolDefinitions describe the tools listed in tools with their descriptions and argument
+	// schemas, as reported by the server
+	ToolDefinitions []ToolDefinition `yaml:"tool_definitions,omitempty"`
+
+	// Resources and Prompts are the resources and prompt templates the server reports
+	Resources []Resource `yaml:"resources,omitempty"`
+	Prompts   []Prompt   `yaml:"prompts,omitempty"`
+
+	// ResourceTemplates are the parameterized resources the server reports. They are only
+	// discovered for remote servers, as thv can't list them.
+	ResourceTemplates []ResourceTemplate `yaml:"resource_templates,omitempty"`
 }
 
 // GetServerMetadata returns the underlying ServerMetadata interface
@@ -59,6 +91,130 @@ type Example struct {
 	Sample string `yaml:"sample"`
 }
 
+// ToolDefinition describes a tool of an MCP server as reported by tools/list
+type ToolDefinition struct {
+	// Name of the tool, as listed in tools
+	Name string `yaml:"name" json:"name"`
+
+	// Description of what the tool does
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+
+	// InputSchema is the JSON schema of the tool's arguments
+	InputSchema map[string]any `yaml:"input_schema,omitempty" json:"input_schema,omitempty"`
+
+	// Annotations are the MCP hints about the tool's behavior, such as readOnlyHint
+	Annotations map[string]any `yaml:"annotations,omitempty" json:"annotations,omitempty"`
+
+	// SideEffect classifies the tool as read-only, additive, destructive or unknown. It is
+	// derived from the annotations, or set by hand for tools without any.
+	SideEffect string `yaml:"side_effect,omitempty" json:"side_effect,omitempty"`
+}
+
+// ClassifyTool returns the side effect of a tool from its MCP annotations, with MCP's defaults
+// for the hints it leaves out. readOnlyHint marks read-only tools; other annotated tools, such
+// as those with only openWorldHint or idempotentHint, are destructive unless destructiveHint
+// is false. Tools without annotations are unknown.
+func ClassifyTool(annotations map[string]any) string {
+	readOnly, _ := annotations["readOnlyHint"].(bool)
+	destructive, hasDestructive := annotations["destructiveHint"].(bool)
+	switch {
+	case len(annotations) == 0:
+		return SideEffectUnknown
+	case readOnly:
+		return SideEffectReadOnly
+	case hasDestructive && !destructive:
+		return SideEffectAdditive
+	default:
+		return SideEffectDestructive
+	}
+}
+
+// ToolSideEffects returns the side effect of every tool of the entry. Tools without a
+// definition are unknown, and definitions without a side effect are classified from their
+// annotations.
+func (r *RegistryEntry) ToolSideEffects() map[string]string {
+	effects := make(map[string]string)
+	for _, tool := range r.GetTools() {
+		effects[tool] = SideEffectUnknown
+	}
+	for _, definition := range r.ToolDefinitions {
+		if _, listed := effects[definition.Name]; !listed {
+			continue
+		}
+		effects[definition.Name] = definition.SideEffect
+		if definition.SideEffect == "" {
+			effects[definition.Name] = ClassifyTool(definition.Annotations)
+		}
+	}
+	return effects
+}
+
+// IsReadOnly returns true if the entry lists tools and all of them are read-only
+func (r *RegistryEntry) IsReadOnly() bool {
+	effects := r.ToolSideEffects()
+	for _, effect := range effects {
+		if effect != SideEffectReadOnly {
+			return false
+		}
+	}
+	return len(effects) > 0
+}
+
+// Resource describes a resource of an MCP server as reported by resources/list
+type Resource struct {
+	// URI identifying the resource
+	URI string `yaml:"uri" json:"uri"`
+
+	// Name of the resource
+	Name string `yaml:"name" json:"name"`
+
+	// Description of what the resource contains
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+
+	// MIMEType of the resource content, if known
+	MIMEType string `yaml:"mime_type,omitempty" json:"mime_type,omitempty"`
+}
+
+// ResourceTemplate describes a parameterized resource of an MCP server as reported by
+// resources/templates/list
+type ResourceTemplate struct {
+	// URITemplate is the RFC 6570 template the URIs of the resources follow
+	URITemplate string `yaml:"uri_template" json:"uri_template"`
+
+	// Name of the resource template
+	Name string `yaml:"name" json:"name"`
+
+	// Description of what the resources contain
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+
+	// MIMEType of the resource content, if all resources of the template share one
+	MIMEType string `yaml:"mime_type,omitempty" json:"mime_type,omitempty"`
+}
+
+// Prompt describes a prompt template of an MCP server as reported by prompts/list
+type Prompt struct {
+	// Name of the prompt
+	Name string `yaml:"name" json:"name"`
+
+	// Description of what the prompt is for
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+
+	// Arguments the prompt template accepts
+	Arguments []PromptArgument `yaml:"arguments,omitempty" json:"arguments,omitempty"`
+}
+
+// PromptArgument describes an argument of a prompt template
+type PromptArgument struct {
+	// Name of the argument
+	Name string `yaml:"name" json:"name"`
+
+	// Description of the argument
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+
+	// Required is true if the prompt cannot be used without the argument
+	Required bool `yaml:"required,omitempty" json:"required,omitempty"`
+}
+
 // RegistryMetadata contains metadata about the entire registry
 type RegistryMetadata struct {
 	// Version of the registry format
@@ -162,6 +318,13 @@ func (r *RegistryEntry) SetDefaults() {
 type extendedFields struct {
 	Examples []Example `yaml:"examples,omitempty"`
 	License  string    `yaml:"license,omitempty"`
+	Version  string    `yaml:"version,omitempty"`
+	// Tool definitions with descriptions and argument schemas
+	ToolDefinitions []ToolDefinition `yaml:"tool_definitions,omitempty"`
+	// Resources, prompts and resource templates reported by the server
+	Resources         []Resource         `yaml:"resources,omitempty"`
+	Prompts           []Prompt           `yaml:"prompts,omitempty"`
+	ResourceTemplates []ResourceTemplate `yaml:"resource_templates,omitempty"`
 	// OAuth configuration in simplified YAML format
 	OAuth *struct {
 		Issuer       string            `yaml:"issuer,omitempty"`
@@ -226,13 +389,19 @@ func (r *RegistryEntry) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		}
 	}
 
-	// Unmarshal extended fields (examples, license, oauth, headers, env_vars) separately
+	// Unmarshal extended fields (examples, license, version, tool definitions, resources, prompts,
+	// resource templates, oauth, headers, env_vars) separately
 	var extended extendedFields
 	if err := unmarshal(&extended); err != nil {
 		return err
 	}
 	r.Examples = extended.Examples
 	r.License = extended.License
+	r.Version = extended.Version
+	r.ToolDefinitions = extended.ToolDefinitions
+	r.Resources = extended.Resources
+	r.Prompts = extended.Prompts
+	r.ResourceTemplates = extended.ResourceTemplates
 
 	// Handle OAuth configuration transformation for remote servers
 	if r.RemoteServerMetadata != nil && extended.OAuth != nil {
@@ -292,8 +461,12 @@ func (r *RegistryEntry) handleRemoteServerFields(extended *extendedFields, raw m
 		}
 	}
 
-	// Handle custom metadata fields (homepage, license, author, etc.)
-	customFields := make(map[string]interface{})
+	// Handle custom metadata fields (homepage, license, author, etc.), merged into
+	// any other fields declared under custom_metadata
+	customFields := r.RemoteServerMetadata.CustomMetadata
+	if customFields == nil {
+		customFields = make(map[string]interface{})
+	}
 
 	// Check for common custom fields
 	if val, exists := raw["homepage"]; exists {